@@ -58,12 +58,21 @@ func (*StatusRequest) Descriptor() ([]byte, []int) {
 }
 
 type StatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	IsActive      bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	UptimeSeconds int32                  `protobuf:"varint,2,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
-	ActiveUsers   int32                  `protobuf:"varint,3,opt,name=active_users,json=activeUsers,proto3" json:"active_users,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	IsActive            bool                   `protobuf:"varint,1,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	UptimeSeconds       int32                  `protobuf:"varint,2,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	ActiveUsers         int32                  `protobuf:"varint,3,opt,name=active_users,json=activeUsers,proto3" json:"active_users,omitempty"`
+	PendingUsers        int32                  `protobuf:"varint,4,opt,name=pending_users,json=pendingUsers,proto3" json:"pending_users,omitempty"`                           // Logins waiting to connect
+	ExpiredPending      int64                  `protobuf:"varint,5,opt,name=expired_pending,json=expiredPending,proto3" json:"expired_pending,omitempty"`                     // Logins expired before connecting
+	IdleWarnings        int64                  `protobuf:"varint,6,opt,name=idle_warnings,json=idleWarnings,proto3" json:"idle_warnings,omitempty"`                           // Warnings sent to idle players
+	IdleDisconnects     int64                  `protobuf:"varint,7,opt,name=idle_disconnects,json=idleDisconnects,proto3" json:"idle_disconnects,omitempty"`                  // Players disconnected for being idle
+	QueuedUsers         int32                  `protobuf:"varint,8,opt,name=queued_users,json=queuedUsers,proto3" json:"queued_users,omitempty"`                              // Logins waiting for a free slot
+	OutputQueueDepth    int32                  `protobuf:"varint,9,opt,name=output_queue_depth,json=outputQueueDepth,proto3" json:"output_queue_depth,omitempty"`             // Messages waiting to be written across every player
+	MaxOutputQueueDepth int32                  `protobuf:"varint,10,opt,name=max_output_queue_depth,json=maxOutputQueueDepth,proto3" json:"max_output_queue_depth,omitempty"` // Messages waiting for the most backed up player
+	DroppedMessages     int64                  `protobuf:"varint,11,opt,name=dropped_messages,json=droppedMessages,proto3" json:"dropped_messages,omitempty"`                 // Messages dropped because a player's output queue was full
+	OverflowDisconnects int64                  `protobuf:"varint,12,opt,name=overflow_disconnects,json=overflowDisconnects,proto3" json:"overflow_disconnects,omitempty"`     // Players disconnected because their output queue was full
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *StatusResponse) Reset() {
@@ -117,16 +126,89 @@ func (x *StatusResponse) GetActiveUsers() int32 {
 	return 0
 }
 
+func (x *StatusResponse) GetPendingUsers() int32 {
+	if x != nil {
+		return x.PendingUsers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetExpiredPending() int64 {
+	if x != nil {
+		return x.ExpiredPending
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetIdleWarnings() int64 {
+	if x != nil {
+		return x.IdleWarnings
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetIdleDisconnects() int64 {
+	if x != nil {
+		return x.IdleDisconnects
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetQueuedUsers() int32 {
+	if x != nil {
+		return x.QueuedUsers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetOutputQueueDepth() int32 {
+	if x != nil {
+		return x.OutputQueueDepth
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetMaxOutputQueueDepth() int32 {
+	if x != nil {
+		return x.MaxOutputQueueDepth
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetDroppedMessages() int64 {
+	if x != nil {
+		return x.DroppedMessages
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetOverflowDisconnects() int64 {
+	if x != nil {
+		return x.OverflowDisconnects
+	}
+	return 0
+}
+
 var File_api_proto_health_proto protoreflect.FileDescriptor
 
 const file_api_proto_health_proto_rawDesc = "" +
 	"\n" +
 	"\x16api/proto/health.proto\x12\x14com.xealgo.muddy.api\"\x0f\n" +
-	"\rStatusRequest\"w\n" +
+	"\rStatusRequest\"\xf9\x03\n" +
 	"\x0eStatusResponse\x12\x1b\n" +
 	"\tis_active\x18\x01 \x01(\bR\bisActive\x12%\n" +
 	"\x0euptime_seconds\x18\x02 \x01(\x05R\ruptimeSeconds\x12!\n" +
-	"\factive_users\x18\x03 \x01(\x05R\vactiveUsers2g\n" +
+	"\factive_users\x18\x03 \x01(\x05R\vactiveUsers\x12#\n" +
+	"\rpending_users\x18\x04 \x01(\x05R\fpendingUsers\x12'\n" +
+	"\x0fexpired_pending\x18\x05 \x01(\x03R\x0eexpiredPending\x12#\n" +
+	"\ridle_warnings\x18\x06 \x01(\x03R\fidleWarnings\x12)\n" +
+	"\x10idle_disconnects\x18\a \x01(\x03R\x0fidleDisconnects\x12!\n" +
+	"\fqueued_users\x18\b \x01(\x05R\vqueuedUsers\x12,\n" +
+	"\x12output_queue_depth\x18\t \x01(\x05R\x10outputQueueDepth\x123\n" +
+	"\x16max_output_queue_depth\x18\n" +
+	" \x01(\x05R\x13maxOutputQueueDepth\x12)\n" +
+	"\x10dropped_messages\x18\v \x01(\x03R\x0fdroppedMessages\x121\n" +
+	"\x14overflow_disconnects\x18\f \x01(\x03R\x13overflowDisconnects2g\n" +
 	"\rHealthService\x12V\n" +
 	"\tGetStatus\x12#.com.xealgo.muddy.api.StatusRequest\x1a$.com.xealgo.muddy.api.StatusResponseB\bZ\x06./;apib\x06proto3"
 
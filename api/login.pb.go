@@ -24,6 +24,7 @@ const (
 type LoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"` // Checked against the player's saved account, or sets its password on the first login
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,9 +66,17 @@ func (x *LoginRequest) GetUsername() string {
 	return ""
 }
 
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
 type LoginResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionUuid   string                 `protobuf:"bytes,1,opt,name=session_uuid,json=sessionUuid,proto3" json:"session_uuid,omitempty"`
+	QueuePosition int32                  `protobuf:"varint,2,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"` // 0 once the player can connect, otherwise their place in the login queue
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -109,17 +118,129 @@ func (x *LoginResponse) GetSessionUuid() string {
 	return ""
 }
 
+func (x *LoginResponse) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+type QueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionUuid   string                 `protobuf:"bytes,1,opt,name=session_uuid,json=sessionUuid,proto3" json:"session_uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueRequest) Reset() {
+	*x = QueueRequest{}
+	mi := &file_api_proto_login_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueRequest) ProtoMessage() {}
+
+func (x *QueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_login_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueRequest.ProtoReflect.Descriptor instead.
+func (*QueueRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_login_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueueRequest) GetSessionUuid() string {
+	if x != nil {
+		return x.SessionUuid
+	}
+	return ""
+}
+
+type QueueStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      int32                  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"` // 0 once the player can connect
+	QueueLength   int32                  `protobuf:"varint,2,opt,name=queue_length,json=queueLength,proto3" json:"queue_length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueStatus) Reset() {
+	*x = QueueStatus{}
+	mi := &file_api_proto_login_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueStatus) ProtoMessage() {}
+
+func (x *QueueStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_login_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueStatus.ProtoReflect.Descriptor instead.
+func (*QueueStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_login_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QueueStatus) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *QueueStatus) GetQueueLength() int32 {
+	if x != nil {
+		return x.QueueLength
+	}
+	return 0
+}
+
 var File_api_proto_login_proto protoreflect.FileDescriptor
 
 const file_api_proto_login_proto_rawDesc = "" +
 	"\n" +
-	"\x15api/proto/login.proto\x12\x14com.xealgo.muddy.api\"*\n" +
+	"\x15api/proto/login.proto\x12\x14com.xealgo.muddy.api\"F\n" +
 	"\fLoginRequest\x12\x1a\n" +
-	"\busername\x18\x01 \x01(\tR\busername\"2\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"Y\n" +
 	"\rLoginResponse\x12!\n" +
-	"\fsession_uuid\x18\x01 \x01(\tR\vsessionUuid2`\n" +
+	"\fsession_uuid\x18\x01 \x01(\tR\vsessionUuid\x12%\n" +
+	"\x0equeue_position\x18\x02 \x01(\x05R\rqueuePosition\"1\n" +
+	"\fQueueRequest\x12!\n" +
+	"\fsession_uuid\x18\x01 \x01(\tR\vsessionUuid\"L\n" +
+	"\vQueueStatus\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x05R\bposition\x12!\n" +
+	"\fqueue_length\x18\x02 \x01(\x05R\vqueueLength2\x93\x02\n" +
 	"\fLoginService\x12P\n" +
-	"\x05Login\x12\".com.xealgo.muddy.api.LoginRequest\x1a#.com.xealgo.muddy.api.LoginResponseB\bZ\x06./;apib\x06proto3"
+	"\x05Login\x12\".com.xealgo.muddy.api.LoginRequest\x1a#.com.xealgo.muddy.api.LoginResponse\x12Y\n" +
+	"\x10GetQueuePosition\x12\".com.xealgo.muddy.api.QueueRequest\x1a!.com.xealgo.muddy.api.QueueStatus\x12V\n" +
+	"\vWaitInQueue\x12\".com.xealgo.muddy.api.QueueRequest\x1a!.com.xealgo.muddy.api.QueueStatus0\x01B\bZ\x06./;apib\x06proto3"
 
 var (
 	file_api_proto_login_proto_rawDescOnce sync.Once
@@ -133,16 +254,22 @@ func file_api_proto_login_proto_rawDescGZIP() []byte {
 	return file_api_proto_login_proto_rawDescData
 }
 
-var file_api_proto_login_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_api_proto_login_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_api_proto_login_proto_goTypes = []any{
 	(*LoginRequest)(nil),  // 0: com.xealgo.muddy.api.LoginRequest
 	(*LoginResponse)(nil), // 1: com.xealgo.muddy.api.LoginResponse
+	(*QueueRequest)(nil),  // 2: com.xealgo.muddy.api.QueueRequest
+	(*QueueStatus)(nil),   // 3: com.xealgo.muddy.api.QueueStatus
 }
 var file_api_proto_login_proto_depIdxs = []int32{
 	0, // 0: com.xealgo.muddy.api.LoginService.Login:input_type -> com.xealgo.muddy.api.LoginRequest
-	1, // 1: com.xealgo.muddy.api.LoginService.Login:output_type -> com.xealgo.muddy.api.LoginResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: com.xealgo.muddy.api.LoginService.GetQueuePosition:input_type -> com.xealgo.muddy.api.QueueRequest
+	2, // 2: com.xealgo.muddy.api.LoginService.WaitInQueue:input_type -> com.xealgo.muddy.api.QueueRequest
+	1, // 3: com.xealgo.muddy.api.LoginService.Login:output_type -> com.xealgo.muddy.api.LoginResponse
+	3, // 4: com.xealgo.muddy.api.LoginService.GetQueuePosition:output_type -> com.xealgo.muddy.api.QueueStatus
+	3, // 5: com.xealgo.muddy.api.LoginService.WaitInQueue:output_type -> com.xealgo.muddy.api.QueueStatus
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -159,7 +286,7 @@ func file_api_proto_login_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_login_proto_rawDesc), len(file_api_proto_login_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	LoginService_Login_FullMethodName = "/com.xealgo.muddy.api.LoginService/Login"
+	LoginService_Login_FullMethodName            = "/com.xealgo.muddy.api.LoginService/Login"
+	LoginService_GetQueuePosition_FullMethodName = "/com.xealgo.muddy.api.LoginService/GetQueuePosition"
+	LoginService_WaitInQueue_FullMethodName      = "/com.xealgo.muddy.api.LoginService/WaitInQueue"
 )
 
 // LoginServiceClient is the client API for LoginService service.
@@ -27,6 +29,11 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type LoginServiceClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// Polls the player's place in the login queue.
+	GetQueuePosition(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*QueueStatus, error)
+	// Streams the player's place in the login queue until they can connect.
+	// Leaving the stream gives up the place.
+	WaitInQueue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QueueStatus], error)
 }
 
 type loginServiceClient struct {
@@ -47,11 +54,45 @@ func (c *loginServiceClient) Login(ctx context.Context, in *LoginRequest, opts .
 	return out, nil
 }
 
+func (c *loginServiceClient) GetQueuePosition(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*QueueStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueStatus)
+	err := c.cc.Invoke(ctx, LoginService_GetQueuePosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loginServiceClient) WaitInQueue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QueueStatus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LoginService_ServiceDesc.Streams[0], LoginService_WaitInQueue_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[QueueRequest, QueueStatus]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoginService_WaitInQueueClient = grpc.ServerStreamingClient[QueueStatus]
+
 // LoginServiceServer is the server API for LoginService service.
 // All implementations must embed UnimplementedLoginServiceServer
 // for forward compatibility.
 type LoginServiceServer interface {
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	// Polls the player's place in the login queue.
+	GetQueuePosition(context.Context, *QueueRequest) (*QueueStatus, error)
+	// Streams the player's place in the login queue until they can connect.
+	// Leaving the stream gives up the place.
+	WaitInQueue(*QueueRequest, grpc.ServerStreamingServer[QueueStatus]) error
 	mustEmbedUnimplementedLoginServiceServer()
 }
 
@@ -65,6 +106,12 @@ type UnimplementedLoginServiceServer struct{}
 func (UnimplementedLoginServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
+func (UnimplementedLoginServiceServer) GetQueuePosition(context.Context, *QueueRequest) (*QueueStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQueuePosition not implemented")
+}
+func (UnimplementedLoginServiceServer) WaitInQueue(*QueueRequest, grpc.ServerStreamingServer[QueueStatus]) error {
+	return status.Errorf(codes.Unimplemented, "method WaitInQueue not implemented")
+}
 func (UnimplementedLoginServiceServer) mustEmbedUnimplementedLoginServiceServer() {}
 func (UnimplementedLoginServiceServer) testEmbeddedByValue()                      {}
 
@@ -104,6 +151,35 @@ func _LoginService_Login_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LoginService_GetQueuePosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoginServiceServer).GetQueuePosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LoginService_GetQueuePosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoginServiceServer).GetQueuePosition(ctx, req.(*QueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoginService_WaitInQueue_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueueRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LoginServiceServer).WaitInQueue(m, &grpc.GenericServerStream[QueueRequest, QueueStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoginService_WaitInQueueServer = grpc.ServerStreamingServer[QueueStatus]
+
 // LoginService_ServiceDesc is the grpc.ServiceDesc for LoginService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +191,17 @@ var LoginService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _LoginService_Login_Handler,
 		},
+		{
+			MethodName: "GetQueuePosition",
+			Handler:    _LoginService_GetQueuePosition_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WaitInQueue",
+			Handler:       _LoginService_WaitInQueue_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/login.proto",
 }
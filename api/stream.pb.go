@@ -0,0 +1,1502 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.21.12
+// source: api/proto/stream.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PlayerMovement_Kind int32
+
+const (
+	PlayerMovement_ARRIVED  PlayerMovement_Kind = 0
+	PlayerMovement_DEPARTED PlayerMovement_Kind = 1
+	PlayerMovement_JOINED   PlayerMovement_Kind = 2
+	PlayerMovement_QUIT     PlayerMovement_Kind = 3
+)
+
+// Enum value maps for PlayerMovement_Kind.
+var (
+	PlayerMovement_Kind_name = map[int32]string{
+		0: "ARRIVED",
+		1: "DEPARTED",
+		2: "JOINED",
+		3: "QUIT",
+	}
+	PlayerMovement_Kind_value = map[string]int32{
+		"ARRIVED":  0,
+		"DEPARTED": 1,
+		"JOINED":   2,
+		"QUIT":     3,
+	}
+)
+
+func (x PlayerMovement_Kind) Enum() *PlayerMovement_Kind {
+	p := new(PlayerMovement_Kind)
+	*p = x
+	return p
+}
+
+func (x PlayerMovement_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlayerMovement_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_stream_proto_enumTypes[0].Descriptor()
+}
+
+func (PlayerMovement_Kind) Type() protoreflect.EnumType {
+	return &file_api_proto_stream_proto_enumTypes[0]
+}
+
+func (x PlayerMovement_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlayerMovement_Kind.Descriptor instead.
+func (PlayerMovement_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{8, 0}
+}
+
+// ClientMessage is sent from the client to the server.
+type ClientMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ClientMessage_Command
+	//	*ClientMessage_Oob
+	//	*ClientMessage_Typing
+	Payload       isClientMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientMessage) Reset() {
+	*x = ClientMessage{}
+	mi := &file_api_proto_stream_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage) ProtoMessage() {}
+
+func (x *ClientMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage.ProtoReflect.Descriptor instead.
+func (*ClientMessage) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ClientMessage) GetPayload() isClientMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ClientMessage) GetCommand() *CommandRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientMessage_Command); ok {
+			return x.Command
+		}
+	}
+	return nil
+}
+
+func (x *ClientMessage) GetOob() *OutOfBand {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientMessage_Oob); ok {
+			return x.Oob
+		}
+	}
+	return nil
+}
+
+func (x *ClientMessage) GetTyping() *Typing {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientMessage_Typing); ok {
+			return x.Typing
+		}
+	}
+	return nil
+}
+
+type isClientMessage_Payload interface {
+	isClientMessage_Payload()
+}
+
+type ClientMessage_Command struct {
+	Command *CommandRequest `protobuf:"bytes,1,opt,name=command,proto3,oneof"`
+}
+
+type ClientMessage_Oob struct {
+	Oob *OutOfBand `protobuf:"bytes,2,opt,name=oob,proto3,oneof"`
+}
+
+type ClientMessage_Typing struct {
+	Typing *Typing `protobuf:"bytes,3,opt,name=typing,proto3,oneof"`
+}
+
+func (*ClientMessage_Command) isClientMessage_Payload() {}
+
+func (*ClientMessage_Oob) isClientMessage_Payload() {}
+
+func (*ClientMessage_Typing) isClientMessage_Payload() {}
+
+// ServerMessage is sent from the server to the client.
+type ServerMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ServerMessage_Output
+	//	*ServerMessage_Prompt
+	//	*ServerMessage_Error
+	//	*ServerMessage_RoomChat
+	//	*ServerMessage_Room
+	//	*ServerMessage_Inventory
+	//	*ServerMessage_Vitals
+	//	*ServerMessage_Oob
+	//	*ServerMessage_Typing
+	//	*ServerMessage_Presence
+	//	*ServerMessage_Session
+	//	*ServerMessage_Movement
+	//	*ServerMessage_Emote
+	Payload       isServerMessage_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerMessage) Reset() {
+	*x = ServerMessage{}
+	mi := &file_api_proto_stream_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage) ProtoMessage() {}
+
+func (x *ServerMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServerMessage) GetPayload() isServerMessage_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetOutput() *TextOutput {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Output); ok {
+			return x.Output
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetPrompt() *Prompt {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Prompt); ok {
+			return x.Prompt
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetRoomChat() *RoomChat {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_RoomChat); ok {
+			return x.RoomChat
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetRoom() *RoomDescription {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Room); ok {
+			return x.Room
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetInventory() *InventorySnapshot {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Inventory); ok {
+			return x.Inventory
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetVitals() *Vitals {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Vitals); ok {
+			return x.Vitals
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetOob() *OutOfBand {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Oob); ok {
+			return x.Oob
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetTyping() *Typing {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Typing); ok {
+			return x.Typing
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetPresence() *Presence {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Presence); ok {
+			return x.Presence
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetSession() *Session {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Session); ok {
+			return x.Session
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetMovement() *PlayerMovement {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Movement); ok {
+			return x.Movement
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetEmote() *RoomEmote {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Emote); ok {
+			return x.Emote
+		}
+	}
+	return nil
+}
+
+type isServerMessage_Payload interface {
+	isServerMessage_Payload()
+}
+
+type ServerMessage_Output struct {
+	Output *TextOutput `protobuf:"bytes,1,opt,name=output,proto3,oneof"`
+}
+
+type ServerMessage_Prompt struct {
+	Prompt *Prompt `protobuf:"bytes,2,opt,name=prompt,proto3,oneof"`
+}
+
+type ServerMessage_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+type ServerMessage_RoomChat struct {
+	RoomChat *RoomChat `protobuf:"bytes,4,opt,name=room_chat,json=roomChat,proto3,oneof"`
+}
+
+type ServerMessage_Room struct {
+	Room *RoomDescription `protobuf:"bytes,5,opt,name=room,proto3,oneof"`
+}
+
+type ServerMessage_Inventory struct {
+	Inventory *InventorySnapshot `protobuf:"bytes,6,opt,name=inventory,proto3,oneof"`
+}
+
+type ServerMessage_Vitals struct {
+	Vitals *Vitals `protobuf:"bytes,7,opt,name=vitals,proto3,oneof"`
+}
+
+type ServerMessage_Oob struct {
+	Oob *OutOfBand `protobuf:"bytes,8,opt,name=oob,proto3,oneof"`
+}
+
+type ServerMessage_Typing struct {
+	Typing *Typing `protobuf:"bytes,9,opt,name=typing,proto3,oneof"`
+}
+
+type ServerMessage_Presence struct {
+	Presence *Presence `protobuf:"bytes,10,opt,name=presence,proto3,oneof"`
+}
+
+type ServerMessage_Session struct {
+	Session *Session `protobuf:"bytes,11,opt,name=session,proto3,oneof"`
+}
+
+type ServerMessage_Movement struct {
+	Movement *PlayerMovement `protobuf:"bytes,12,opt,name=movement,proto3,oneof"`
+}
+
+type ServerMessage_Emote struct {
+	Emote *RoomEmote `protobuf:"bytes,13,opt,name=emote,proto3,oneof"`
+}
+
+func (*ServerMessage_Output) isServerMessage_Payload() {}
+
+func (*ServerMessage_Prompt) isServerMessage_Payload() {}
+
+func (*ServerMessage_Error) isServerMessage_Payload() {}
+
+func (*ServerMessage_RoomChat) isServerMessage_Payload() {}
+
+func (*ServerMessage_Room) isServerMessage_Payload() {}
+
+func (*ServerMessage_Inventory) isServerMessage_Payload() {}
+
+func (*ServerMessage_Vitals) isServerMessage_Payload() {}
+
+func (*ServerMessage_Oob) isServerMessage_Payload() {}
+
+func (*ServerMessage_Typing) isServerMessage_Payload() {}
+
+func (*ServerMessage_Presence) isServerMessage_Payload() {}
+
+func (*ServerMessage_Session) isServerMessage_Payload() {}
+
+func (*ServerMessage_Movement) isServerMessage_Payload() {}
+
+func (*ServerMessage_Emote) isServerMessage_Payload() {}
+
+// CommandRequest is a line of player input.
+type CommandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandRequest) Reset() {
+	*x = CommandRequest{}
+	mi := &file_api_proto_stream_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRequest) ProtoMessage() {}
+
+func (x *CommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
+func (*CommandRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CommandRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// TextOutput is free form text, such as a command response.
+type TextOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextOutput) Reset() {
+	*x = TextOutput{}
+	mi := &file_api_proto_stream_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextOutput) ProtoMessage() {}
+
+func (x *TextOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextOutput.ProtoReflect.Descriptor instead.
+func (*TextOutput) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TextOutput) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Prompt asks the client for the next command.
+type Prompt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prompt) Reset() {
+	*x = Prompt{}
+	mi := &file_api_proto_stream_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prompt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prompt) ProtoMessage() {}
+
+func (x *Prompt) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prompt.ProtoReflect.Descriptor instead.
+func (*Prompt) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Prompt) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Error reports a failed command or a server side problem.
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_api_proto_stream_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// RoomChat is something said by a player in the current room.
+type RoomChat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Talker        string                 `protobuf:"bytes,1,opt,name=talker,proto3" json:"talker,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix milliseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomChat) Reset() {
+	*x = RoomChat{}
+	mi := &file_api_proto_stream_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomChat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomChat) ProtoMessage() {}
+
+func (x *RoomChat) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomChat.ProtoReflect.Descriptor instead.
+func (*RoomChat) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RoomChat) GetTalker() string {
+	if x != nil {
+		return x.Talker
+	}
+	return ""
+}
+
+func (x *RoomChat) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *RoomChat) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// RoomEmote is an emote or social used by a player in the current room.
+type RoomEmote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`            // The complete emote, e.g. "Alice waves."
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix milliseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomEmote) Reset() {
+	*x = RoomEmote{}
+	mi := &file_api_proto_stream_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomEmote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomEmote) ProtoMessage() {}
+
+func (x *RoomEmote) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomEmote.ProtoReflect.Descriptor instead.
+func (*RoomEmote) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RoomEmote) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *RoomEmote) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *RoomEmote) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// PlayerMovement announces another player arriving in or leaving the room, or
+// joining or leaving the game.
+type PlayerMovement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          PlayerMovement_Kind    `protobuf:"varint,1,opt,name=kind,proto3,enum=com.xealgo.muddy.api.PlayerMovement_Kind" json:"kind,omitempty"`
+	Player        string                 `protobuf:"bytes,2,opt,name=player,proto3" json:"player,omitempty"`
+	Direction     string                 `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`  // Door the player came or went through, if known
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix milliseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerMovement) Reset() {
+	*x = PlayerMovement{}
+	mi := &file_api_proto_stream_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerMovement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerMovement) ProtoMessage() {}
+
+func (x *PlayerMovement) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerMovement.ProtoReflect.Descriptor instead.
+func (*PlayerMovement) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PlayerMovement) GetKind() PlayerMovement_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return PlayerMovement_ARRIVED
+}
+
+func (x *PlayerMovement) GetPlayer() string {
+	if x != nil {
+		return x.Player
+	}
+	return ""
+}
+
+func (x *PlayerMovement) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *PlayerMovement) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// Exit is a door leading out of a room.
+type Exit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	RoomId        int32                  `protobuf:"varint,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Exit) Reset() {
+	*x = Exit{}
+	mi := &file_api_proto_stream_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Exit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Exit) ProtoMessage() {}
+
+func (x *Exit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Exit.ProtoReflect.Descriptor instead.
+func (*Exit) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Exit) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Exit) GetRoomId() int32 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+// Item is an item in a room or inventory.
+type Item struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	mi := &file_api_proto_stream_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Item) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Item) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// RoomDescription describes the player's current room.
+type RoomDescription struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Exits         []*Exit                `protobuf:"bytes,4,rep,name=exits,proto3" json:"exits,omitempty"`
+	Items         []*Item                `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	Players       []string               `protobuf:"bytes,6,rep,name=players,proto3" json:"players,omitempty"` // Other players in the room
+	Npcs          []string               `protobuf:"bytes,7,rep,name=npcs,proto3" json:"npcs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoomDescription) Reset() {
+	*x = RoomDescription{}
+	mi := &file_api_proto_stream_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomDescription) ProtoMessage() {}
+
+func (x *RoomDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomDescription.ProtoReflect.Descriptor instead.
+func (*RoomDescription) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RoomDescription) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RoomDescription) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RoomDescription) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RoomDescription) GetExits() []*Exit {
+	if x != nil {
+		return x.Exits
+	}
+	return nil
+}
+
+func (x *RoomDescription) GetItems() []*Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *RoomDescription) GetPlayers() []string {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *RoomDescription) GetNpcs() []string {
+	if x != nil {
+		return x.Npcs
+	}
+	return nil
+}
+
+// InventorySnapshot lists every item the player is carrying.
+type InventorySnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Item                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InventorySnapshot) Reset() {
+	*x = InventorySnapshot{}
+	mi := &file_api_proto_stream_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventorySnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventorySnapshot) ProtoMessage() {}
+
+func (x *InventorySnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventorySnapshot.ProtoReflect.Descriptor instead.
+func (*InventorySnapshot) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *InventorySnapshot) GetItems() []*Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Vitals holds the player's health and gold.
+type Vitals struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Health        int32                  `protobuf:"varint,1,opt,name=health,proto3" json:"health,omitempty"`
+	MaxHealth     int32                  `protobuf:"varint,2,opt,name=max_health,json=maxHealth,proto3" json:"max_health,omitempty"`
+	Gold          int32                  `protobuf:"varint,3,opt,name=gold,proto3" json:"gold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vitals) Reset() {
+	*x = Vitals{}
+	mi := &file_api_proto_stream_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vitals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vitals) ProtoMessage() {}
+
+func (x *Vitals) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vitals.ProtoReflect.Descriptor instead.
+func (*Vitals) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Vitals) GetHealth() int32 {
+	if x != nil {
+		return x.Health
+	}
+	return 0
+}
+
+func (x *Vitals) GetMaxHealth() int32 {
+	if x != nil {
+		return x.MaxHealth
+	}
+	return 0
+}
+
+func (x *Vitals) GetGold() int32 {
+	if x != nil {
+		return x.Gold
+	}
+	return 0
+}
+
+// Typing signals that a player is typing. Clients send it empty, the server
+// fills in who is typing when relaying it to the room.
+type Typing struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Talker        string                 `protobuf:"bytes,1,opt,name=talker,proto3" json:"talker,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Typing) Reset() {
+	*x = Typing{}
+	mi := &file_api_proto_stream_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Typing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Typing) ProtoMessage() {}
+
+func (x *Typing) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Typing.ProtoReflect.Descriptor instead.
+func (*Typing) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Typing) GetTalker() string {
+	if x != nil {
+		return x.Talker
+	}
+	return ""
+}
+
+// Presence lists the other players in the current room.
+type Presence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int32                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Players       []string               `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Presence) Reset() {
+	*x = Presence{}
+	mi := &file_api_proto_stream_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Presence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Presence) ProtoMessage() {}
+
+func (x *Presence) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Presence.ProtoReflect.Descriptor instead.
+func (*Presence) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Presence) GetRoomId() int32 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
+func (x *Presence) GetPlayers() []string {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+// Session holds the token used to resume the session after a dropped
+// connection. A new token is issued every time the session is resumed.
+type Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResumeToken   string                 `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_api_proto_stream_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Session) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+// OutOfBand carries a GMCP package without a dedicated message, such as
+// Core.Supports.Set from the client. Data is JSON encoded.
+type OutOfBand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Package       string                 `protobuf:"bytes,1,opt,name=package,proto3" json:"package,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutOfBand) Reset() {
+	*x = OutOfBand{}
+	mi := &file_api_proto_stream_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutOfBand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutOfBand) ProtoMessage() {}
+
+func (x *OutOfBand) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_stream_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutOfBand.ProtoReflect.Descriptor instead.
+func (*OutOfBand) Descriptor() ([]byte, []int) {
+	return file_api_proto_stream_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *OutOfBand) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *OutOfBand) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_api_proto_stream_proto protoreflect.FileDescriptor
+
+const file_api_proto_stream_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/proto/stream.proto\x12\x14com.xealgo.muddy.api\"\xc9\x01\n" +
+	"\rClientMessage\x12@\n" +
+	"\acommand\x18\x01 \x01(\v2$.com.xealgo.muddy.api.CommandRequestH\x00R\acommand\x123\n" +
+	"\x03oob\x18\x02 \x01(\v2\x1f.com.xealgo.muddy.api.OutOfBandH\x00R\x03oob\x126\n" +
+	"\x06typing\x18\x03 \x01(\v2\x1c.com.xealgo.muddy.api.TypingH\x00R\x06typingB\t\n" +
+	"\apayload\"\xa3\x06\n" +
+	"\rServerMessage\x12:\n" +
+	"\x06output\x18\x01 \x01(\v2 .com.xealgo.muddy.api.TextOutputH\x00R\x06output\x126\n" +
+	"\x06prompt\x18\x02 \x01(\v2\x1c.com.xealgo.muddy.api.PromptH\x00R\x06prompt\x123\n" +
+	"\x05error\x18\x03 \x01(\v2\x1b.com.xealgo.muddy.api.ErrorH\x00R\x05error\x12=\n" +
+	"\troom_chat\x18\x04 \x01(\v2\x1e.com.xealgo.muddy.api.RoomChatH\x00R\broomChat\x12;\n" +
+	"\x04room\x18\x05 \x01(\v2%.com.xealgo.muddy.api.RoomDescriptionH\x00R\x04room\x12G\n" +
+	"\tinventory\x18\x06 \x01(\v2'.com.xealgo.muddy.api.InventorySnapshotH\x00R\tinventory\x126\n" +
+	"\x06vitals\x18\a \x01(\v2\x1c.com.xealgo.muddy.api.VitalsH\x00R\x06vitals\x123\n" +
+	"\x03oob\x18\b \x01(\v2\x1f.com.xealgo.muddy.api.OutOfBandH\x00R\x03oob\x126\n" +
+	"\x06typing\x18\t \x01(\v2\x1c.com.xealgo.muddy.api.TypingH\x00R\x06typing\x12<\n" +
+	"\bpresence\x18\n" +
+	" \x01(\v2\x1e.com.xealgo.muddy.api.PresenceH\x00R\bpresence\x129\n" +
+	"\asession\x18\v \x01(\v2\x1d.com.xealgo.muddy.api.SessionH\x00R\asession\x12B\n" +
+	"\bmovement\x18\f \x01(\v2$.com.xealgo.muddy.api.PlayerMovementH\x00R\bmovement\x127\n" +
+	"\x05emote\x18\r \x01(\v2\x1f.com.xealgo.muddy.api.RoomEmoteH\x00R\x05emoteB\t\n" +
+	"\apayload\"$\n" +
+	"\x0eCommandRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\" \n" +
+	"\n" +
+	"TextOutput\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"\x1c\n" +
+	"\x06Prompt\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"!\n" +
+	"\x05Error\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"T\n" +
+	"\bRoomChat\x12\x16\n" +
+	"\x06talker\x18\x01 \x01(\tR\x06talker\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"S\n" +
+	"\tRoomEmote\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\"\xdc\x01\n" +
+	"\x0ePlayerMovement\x12=\n" +
+	"\x04kind\x18\x01 \x01(\x0e2).com.xealgo.muddy.api.PlayerMovement.KindR\x04kind\x12\x16\n" +
+	"\x06player\x18\x02 \x01(\tR\x06player\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"7\n" +
+	"\x04Kind\x12\v\n" +
+	"\aARRIVED\x10\x00\x12\f\n" +
+	"\bDEPARTED\x10\x01\x12\n" +
+	"\n" +
+	"\x06JOINED\x10\x02\x12\b\n" +
+	"\x04QUIT\x10\x03\"3\n" +
+	"\x04Exit\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\x05R\x06roomId\"*\n" +
+	"\x04Item\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xe9\x01\n" +
+	"\x0fRoomDescription\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x120\n" +
+	"\x05exits\x18\x04 \x03(\v2\x1a.com.xealgo.muddy.api.ExitR\x05exits\x120\n" +
+	"\x05items\x18\x05 \x03(\v2\x1a.com.xealgo.muddy.api.ItemR\x05items\x12\x18\n" +
+	"\aplayers\x18\x06 \x03(\tR\aplayers\x12\x12\n" +
+	"\x04npcs\x18\a \x03(\tR\x04npcs\"E\n" +
+	"\x11InventorySnapshot\x120\n" +
+	"\x05items\x18\x01 \x03(\v2\x1a.com.xealgo.muddy.api.ItemR\x05items\"S\n" +
+	"\x06Vitals\x12\x16\n" +
+	"\x06health\x18\x01 \x01(\x05R\x06health\x12\x1d\n" +
+	"\n" +
+	"max_health\x18\x02 \x01(\x05R\tmaxHealth\x12\x12\n" +
+	"\x04gold\x18\x03 \x01(\x05R\x04gold\" \n" +
+	"\x06Typing\x12\x16\n" +
+	"\x06talker\x18\x01 \x01(\tR\x06talker\"=\n" +
+	"\bPresence\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x05R\x06roomId\x12\x18\n" +
+	"\aplayers\x18\x02 \x03(\tR\aplayers\",\n" +
+	"\aSession\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\"9\n" +
+	"\tOutOfBand\x12\x18\n" +
+	"\apackage\x18\x01 \x01(\tR\apackage\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04dataB\bZ\x06./;apib\x06proto3"
+
+var (
+	file_api_proto_stream_proto_rawDescOnce sync.Once
+	file_api_proto_stream_proto_rawDescData []byte
+)
+
+func file_api_proto_stream_proto_rawDescGZIP() []byte {
+	file_api_proto_stream_proto_rawDescOnce.Do(func() {
+		file_api_proto_stream_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_stream_proto_rawDesc), len(file_api_proto_stream_proto_rawDesc)))
+	})
+	return file_api_proto_stream_proto_rawDescData
+}
+
+var file_api_proto_stream_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_api_proto_stream_proto_goTypes = []any{
+	(PlayerMovement_Kind)(0),  // 0: com.xealgo.muddy.api.PlayerMovement.Kind
+	(*ClientMessage)(nil),     // 1: com.xealgo.muddy.api.ClientMessage
+	(*ServerMessage)(nil),     // 2: com.xealgo.muddy.api.ServerMessage
+	(*CommandRequest)(nil),    // 3: com.xealgo.muddy.api.CommandRequest
+	(*TextOutput)(nil),        // 4: com.xealgo.muddy.api.TextOutput
+	(*Prompt)(nil),            // 5: com.xealgo.muddy.api.Prompt
+	(*Error)(nil),             // 6: com.xealgo.muddy.api.Error
+	(*RoomChat)(nil),          // 7: com.xealgo.muddy.api.RoomChat
+	(*RoomEmote)(nil),         // 8: com.xealgo.muddy.api.RoomEmote
+	(*PlayerMovement)(nil),    // 9: com.xealgo.muddy.api.PlayerMovement
+	(*Exit)(nil),              // 10: com.xealgo.muddy.api.Exit
+	(*Item)(nil),              // 11: com.xealgo.muddy.api.Item
+	(*RoomDescription)(nil),   // 12: com.xealgo.muddy.api.RoomDescription
+	(*InventorySnapshot)(nil), // 13: com.xealgo.muddy.api.InventorySnapshot
+	(*Vitals)(nil),            // 14: com.xealgo.muddy.api.Vitals
+	(*Typing)(nil),            // 15: com.xealgo.muddy.api.Typing
+	(*Presence)(nil),          // 16: com.xealgo.muddy.api.Presence
+	(*Session)(nil),           // 17: com.xealgo.muddy.api.Session
+	(*OutOfBand)(nil),         // 18: com.xealgo.muddy.api.OutOfBand
+}
+var file_api_proto_stream_proto_depIdxs = []int32{
+	3,  // 0: com.xealgo.muddy.api.ClientMessage.command:type_name -> com.xealgo.muddy.api.CommandRequest
+	18, // 1: com.xealgo.muddy.api.ClientMessage.oob:type_name -> com.xealgo.muddy.api.OutOfBand
+	15, // 2: com.xealgo.muddy.api.ClientMessage.typing:type_name -> com.xealgo.muddy.api.Typing
+	4,  // 3: com.xealgo.muddy.api.ServerMessage.output:type_name -> com.xealgo.muddy.api.TextOutput
+	5,  // 4: com.xealgo.muddy.api.ServerMessage.prompt:type_name -> com.xealgo.muddy.api.Prompt
+	6,  // 5: com.xealgo.muddy.api.ServerMessage.error:type_name -> com.xealgo.muddy.api.Error
+	7,  // 6: com.xealgo.muddy.api.ServerMessage.room_chat:type_name -> com.xealgo.muddy.api.RoomChat
+	12, // 7: com.xealgo.muddy.api.ServerMessage.room:type_name -> com.xealgo.muddy.api.RoomDescription
+	13, // 8: com.xealgo.muddy.api.ServerMessage.inventory:type_name -> com.xealgo.muddy.api.InventorySnapshot
+	14, // 9: com.xealgo.muddy.api.ServerMessage.vitals:type_name -> com.xealgo.muddy.api.Vitals
+	18, // 10: com.xealgo.muddy.api.ServerMessage.oob:type_name -> com.xealgo.muddy.api.OutOfBand
+	15, // 11: com.xealgo.muddy.api.ServerMessage.typing:type_name -> com.xealgo.muddy.api.Typing
+	16, // 12: com.xealgo.muddy.api.ServerMessage.presence:type_name -> com.xealgo.muddy.api.Presence
+	17, // 13: com.xealgo.muddy.api.ServerMessage.session:type_name -> com.xealgo.muddy.api.Session
+	9,  // 14: com.xealgo.muddy.api.ServerMessage.movement:type_name -> com.xealgo.muddy.api.PlayerMovement
+	8,  // 15: com.xealgo.muddy.api.ServerMessage.emote:type_name -> com.xealgo.muddy.api.RoomEmote
+	0,  // 16: com.xealgo.muddy.api.PlayerMovement.kind:type_name -> com.xealgo.muddy.api.PlayerMovement.Kind
+	10, // 17: com.xealgo.muddy.api.RoomDescription.exits:type_name -> com.xealgo.muddy.api.Exit
+	11, // 18: com.xealgo.muddy.api.RoomDescription.items:type_name -> com.xealgo.muddy.api.Item
+	11, // 19: com.xealgo.muddy.api.InventorySnapshot.items:type_name -> com.xealgo.muddy.api.Item
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_stream_proto_init() }
+func file_api_proto_stream_proto_init() {
+	if File_api_proto_stream_proto != nil {
+		return
+	}
+	file_api_proto_stream_proto_msgTypes[0].OneofWrappers = []any{
+		(*ClientMessage_Command)(nil),
+		(*ClientMessage_Oob)(nil),
+		(*ClientMessage_Typing)(nil),
+	}
+	file_api_proto_stream_proto_msgTypes[1].OneofWrappers = []any{
+		(*ServerMessage_Output)(nil),
+		(*ServerMessage_Prompt)(nil),
+		(*ServerMessage_Error)(nil),
+		(*ServerMessage_RoomChat)(nil),
+		(*ServerMessage_Room)(nil),
+		(*ServerMessage_Inventory)(nil),
+		(*ServerMessage_Vitals)(nil),
+		(*ServerMessage_Oob)(nil),
+		(*ServerMessage_Typing)(nil),
+		(*ServerMessage_Presence)(nil),
+		(*ServerMessage_Session)(nil),
+		(*ServerMessage_Movement)(nil),
+		(*ServerMessage_Emote)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_stream_proto_rawDesc), len(file_api_proto_stream_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_proto_stream_proto_goTypes,
+		DependencyIndexes: file_api_proto_stream_proto_depIdxs,
+		EnumInfos:         file_api_proto_stream_proto_enumTypes,
+		MessageInfos:      file_api_proto_stream_proto_msgTypes,
+	}.Build()
+	File_api_proto_stream_proto = out.File
+	file_api_proto_stream_proto_goTypes = nil
+	file_api_proto_stream_proto_depIdxs = nil
+}
@@ -4,14 +4,16 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -21,8 +23,15 @@ import (
 	"github.com/quic-go/webtransport-go"
 	"github.com/xealgo/muddy/api"
 	"github.com/xealgo/muddy/internal/config"
+	"github.com/xealgo/muddy/internal/protocol"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ReconnectAttempts = 10              // Attempts to resume the session after the connection drops
+	ReconnectDelay    = 3 * time.Second // Delay between reconnect attempts
 )
 
 func main() {
@@ -85,7 +94,7 @@ func createGRPCClient(cfg *config.Config) (api.LoginServiceClient, *grpc.ClientC
 	return client, conn, nil
 }
 
-// login prompts the user for a username with validation.
+// login prompts the user for a username and password with validation.
 func login(client api.LoginServiceClient) (string, error) {
 	validate := func(input string) error {
 		l := len(input)
@@ -105,10 +114,27 @@ func login(client api.LoginServiceClient) (string, error) {
 		return "", err
 	}
 
+	passwordPrompt := promptui.Prompt{
+		Label: "Please enter a password",
+		Mask:  '*',
+		Validate: func(input string) error {
+			if l := len(input); l < 6 || l > 64 {
+				return fmt.Errorf("password must be between 6 and 64 characters")
+			}
+			return nil
+		},
+	}
+
+	password, err := passwordPrompt.Run()
+	if err != nil {
+		return "", err
+	}
+
 	// Call login service
 	ctx := context.Background()
 	req := &api.LoginRequest{
 		Username: username,
+		Password: password,
 	}
 
 	resp, err := client.Login(ctx, req)
@@ -116,103 +142,296 @@ func login(client api.LoginServiceClient) (string, error) {
 		return "", fmt.Errorf("login request failed: %w", err)
 	}
 
+	if resp.QueuePosition > 0 {
+		if err = waitInQueue(ctx, client, resp.SessionUuid); err != nil {
+			return "", err
+		}
+	}
+
 	return resp.SessionUuid, nil
 }
 
+// waitInQueue waits for a free slot when the server is full.
+func waitInQueue(ctx context.Context, client api.LoginServiceClient, uuid string) error {
+	stream, err := client.WaitInQueue(ctx, &api.QueueRequest{SessionUuid: uuid})
+	if err != nil {
+		return fmt.Errorf("unable to join login queue: %w", err)
+	}
+
+	for {
+		queueStatus, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("lost place in login queue: %w", err)
+		}
+
+		if queueStatus.Position == 0 {
+			return nil
+		}
+
+		color.Yellow.Printf("The server is full, you are number %d of %d in the queue...\n", queueStatus.Position, queueStatus.QueueLength)
+	}
+}
+
+// gameClient holds the connection to the game server. If the connection drops
+// it's re-established using the session's resume token.
+type gameClient struct {
+	cfg         *config.Config
+	dialer      webtransport.Dialer
+	session     *webtransport.Session
+	stream      *webtransport.Stream
+	resumeToken string
+	mutex       sync.Mutex
+}
+
 // connectWebTransport connects to the WebTransport server and starts the game session
 func connectWebTransport(ctx context.Context, cfg *config.Config, uuid string) error {
-	addr := fmt.Sprintf("https://localhost:%d/wt?uuid=%s", cfg.WTPort, uuid)
-
 	color.Blue.Println("Connecting to Game Server")
 
-	dialer := webtransport.Dialer{
-		// Configure TLS, QUIC options, ALPN, etc., here if needed.
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		QUICConfig: &quic.Config{
-			EnableDatagrams: true,
-			KeepAlivePeriod: 30 * time.Minute,
+	client := &gameClient{
+		cfg: cfg,
+		dialer: webtransport.Dialer{
+			// Configure TLS, QUIC options, ALPN, etc., here if needed.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			QUICConfig: &quic.Config{
+				EnableDatagrams: true,
+				KeepAlivePeriod: 30 * time.Minute,
+			},
 		},
 	}
 
+	if err := client.connect(ctx, "uuid="+url.QueryEscape(uuid)); err != nil {
+		return err
+	}
+
+	defer client.close()
+
+	// Start message handling
+	go client.handleIncomingMessages(ctx)
+
+	// Handle user input
+	return client.handleUserInput(ctx)
+}
+
+// connect opens a session and stream to the game server. query identifies the
+// player, either by the session uuid from login or a resume token.
+func (c *gameClient) connect(ctx context.Context, query string) error {
+	addr := fmt.Sprintf("https://localhost:%d/wt?%s", c.cfg.WTPort, query)
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	_, sess, err := dialer.Dial(timeoutCtx, addr, nil)
+	_, sess, err := c.dialer.Dial(timeoutCtx, addr, nil)
 	if err != nil {
 		return fmt.Errorf("failed to start session: %w", err)
 	}
 
-	cancel()
-
 	// Open a bidi stream
 	stream, err := sess.OpenStream()
 	if err != nil {
-		log.Fatalf("open stream: %v", err)
+		sess.CloseWithError(0, "failed to open stream")
+		return fmt.Errorf("failed to open stream: %w", err)
 	}
 
-	defer stream.Close()
+	datagrams, err := negotiateVersion(stream)
+	if err != nil {
+		sess.CloseWithError(0, "failed to negotiate protocol")
+		return err
+	}
 
-	// Start message handling
-	go handleIncomingMessages(stream)
+	c.mutex.Lock()
+	c.session = sess
+	c.stream = stream
+	c.mutex.Unlock()
 
-	// Handle user input
-	return handleUserInput(ctx, stream)
+	if datagrams {
+		go handleDatagrams(ctx, sess)
+	}
+
+	return nil
+}
+
+// reconnect resumes the session after the connection dropped.
+func (c *gameClient) reconnect(ctx context.Context) bool {
+	c.mutex.Lock()
+	token := c.resumeToken
+	c.mutex.Unlock()
+
+	if token == "" {
+		return false
+	}
+
+	color.Yellow.Println("Connection lost, reconnecting...")
+
+	for attempt := 0; attempt < ReconnectAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(ReconnectDelay):
+		}
+
+		err := c.connect(ctx, "resume="+url.QueryEscape(token))
+		if err == nil {
+			return true
+		}
+
+		slog.Debug("Reconnect attempt failed", "attempt", attempt+1, "error", err)
+	}
+
+	return false
+}
+
+// currentStream returns the stream of the current connection.
+func (c *gameClient) currentStream() *webtransport.Stream {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stream
+}
+
+// close closes the current connection.
+func (c *gameClient) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stream.Close()
+	c.session.CloseWithError(0, "client closed")
 }
 
-// handleIncomingMessages listens for messages from the server
-//
-// NOTE: This will eventually be entirely re-written.. just hacking away
-// for the time being.
-func handleIncomingMessages(stream *webtransport.Stream) {
-	stream.Write([]byte{'\n'})
+// negotiateVersion asks the server for the protobuf message protocol with
+// datagrams, and subscribes to room and chat updates. It returns whether the
+// server will send datagrams.
+func negotiateVersion(stream *webtransport.Stream) (bool, error) {
+	hello := protocol.Envelope{Type: protocol.TypeHello, Versions: []int{protocol.Version2}, Datagrams: true}
+
+	if err := protocol.WriteEnvelope(stream, hello); err != nil {
+		return false, fmt.Errorf("failed to send hello: %w", err)
+	}
+
+	env, err := protocol.ReadEnvelope(stream, protocol.MaxFrameSize)
+	if err != nil {
+		return false, fmt.Errorf("failed to read hello: %w", err)
+	}
+
+	if env.Type == protocol.TypeError {
+		return false, fmt.Errorf("server rejected connection: %s", env.Text)
+	}
+
+	if env.Type != protocol.TypeHello || env.Version != protocol.Version2 {
+		return false, fmt.Errorf("server does not support protocol version %d", protocol.Version2)
+	}
+
+	subscribe := &api.ClientMessage{
+		Payload: &api.ClientMessage_Oob{Oob: &api.OutOfBand{Package: "Core.Supports.Set", Data: []byte(`["Room 1", "Comm 1"]`)}},
+	}
+
+	return env.Datagrams, protocol.WriteMessage(stream, subscribe)
+}
 
-	buffer := make([]byte, 1024)
+// handleDatagrams renders ephemeral updates sent as datagrams.
+func handleDatagrams(ctx context.Context, sess *webtransport.Session) {
 	for {
-		n, err := stream.Read(buffer)
+		datagram, err := sess.ReceiveDatagram(ctx)
 		if err != nil {
+			return
+		}
+
+		msg := &api.ServerMessage{}
+		if err = proto.Unmarshal(datagram, msg); err != nil {
+			slog.Error("failed to unmarshal datagram", "error", err)
+			continue
+		}
+
+		if typing := msg.GetTyping(); typing != nil {
+			color.Gray.Printf("%s is typing...\n", typing.GetTalker())
+		}
+	}
+}
+
+// handleIncomingMessages listens for messages from the server, reconnecting
+// if the connection drops.
+func (c *gameClient) handleIncomingMessages(ctx context.Context) {
+	roomId := int32(-1)
+
+	for {
+		msg := &api.ServerMessage{}
+
+		err := protocol.ReadMessage(c.currentStream(), protocol.MaxFrameSize, msg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
 			var appError *quic.ApplicationError
+			var idleError *quic.IdleTimeoutError
+
+			if errors.As(err, &appError) || errors.As(err, &idleError) || errors.Is(err, io.EOF) {
+				if c.reconnect(ctx) {
+					continue
+				}
 
-			if errors.As(err, &appError) {
 				color.Red.Println("Disconnected from server.")
 				os.Exit(0)
 				return
 			}
 
-			if err.Error() != "EOF" {
-				color.Red.Printf("Error reading from server: %v\n", err)
-			}
+			color.Red.Printf("Error reading from server: %v\n", err)
 			return
 		}
 
-		message := string(buffer[:n])
-
-		if strings.Contains(message, "event:") {
-			type Event struct {
-				Type      string
-				Timestamp time.Time
-				Data      interface{}
+		switch payload := msg.GetPayload().(type) {
+		case *api.ServerMessage_Output:
+			color.Cyan.Print(payload.Output.GetText())
+		case *api.ServerMessage_Prompt:
+			fmt.Print(payload.Prompt.GetText())
+		case *api.ServerMessage_Error:
+			color.Red.Println(strings.TrimRight(payload.Error.GetMessage(), "\n"))
+		case *api.ServerMessage_RoomChat:
+			color.Yellow.Printf("%s: %s\n", payload.RoomChat.GetTalker(), payload.RoomChat.GetText())
+		case *api.ServerMessage_Emote:
+			color.Green.Println(payload.Emote.GetText())
+		case *api.ServerMessage_Movement:
+			color.Magenta.Println(describeMovement(payload.Movement))
+		case *api.ServerMessage_Typing:
+			// Sent on the stream when a datagram couldn't be delivered.
+			color.Gray.Printf("%s is typing...\n", payload.Typing.GetTalker())
+		case *api.ServerMessage_Session:
+			c.mutex.Lock()
+			c.resumeToken = payload.Session.GetResumeToken()
+			c.mutex.Unlock()
+		case *api.ServerMessage_Room:
+			// Announce the room by name whenever the player moves.
+			if payload.Room.GetId() != roomId {
+				roomId = payload.Room.GetId()
+				color.Green.Printf("== %s ==\n", payload.Room.GetName())
 			}
+		}
+	}
+}
 
-			e := Event{}
+// describeMovement describes a player arriving, departing, joining or quitting.
+func describeMovement(movement *api.PlayerMovement) string {
+	player, direction := movement.GetPlayer(), movement.GetDirection()
 
-			m := strings.ReplaceAll(message, "event:", "")
-			err = json.Unmarshal([]byte(m), &e)
-			if err != nil {
-				slog.Error("failed to unmarshal event", "error", err)
-				return
-			}
-
-			if e.Type == "RoomChat" {
-				color.Yellow.Println(e.Data)
-			}
-		} else {
-			color.Cyan.Print(message)
+	switch movement.GetKind() {
+	case api.PlayerMovement_ARRIVED:
+		if direction == "" {
+			return player + " arrives."
+		}
+		return player + " arrives from the " + direction + "."
+	case api.PlayerMovement_DEPARTED:
+		if direction == "" {
+			return player + " leaves."
 		}
+		return player + " leaves " + direction + "."
+	case api.PlayerMovement_JOINED:
+		return player + " has joined the game."
+	default:
+		return player + " has left the game."
 	}
 }
 
 // handleUserInput processes user commands and sends them to server
-func handleUserInput(ctx context.Context, stream *webtransport.Stream) error {
+func (c *gameClient) handleUserInput(ctx context.Context) error {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -222,25 +441,29 @@ func handleUserInput(ctx context.Context, stream *webtransport.Stream) error {
 		default:
 		}
 
-		fmt.Print("> ")
 		if !scanner.Scan() {
 			break
 		}
 
 		input := strings.TrimSpace(scanner.Text())
 		if input == "" {
+			fmt.Print(protocol.CommandPrompt)
 			continue
 		}
 
-		if input == "quit" || input == "exit" {
-			color.Yellow.Println("Until next time!")
-			return nil
-		}
-
 		// Send command to server
-		_, err := stream.Write([]byte(input))
+		command := &api.ClientMessage{Payload: &api.ClientMessage_Command{Command: &api.CommandRequest{Text: input}}}
+
+		err := protocol.WriteMessage(c.currentStream(), command)
 		if err != nil {
-			return fmt.Errorf("failed to send message: %w", err)
+			color.Red.Println("Not connected to the server, please try again.")
+			continue
+		}
+
+		// The server says goodbye and removes the player right away
+		if input == "quit" || input == "exit" {
+			time.Sleep(200 * time.Millisecond)
+			return nil
 		}
 	}
 
@@ -6,18 +6,25 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 
 	"github.com/gookit/color"
 
 	"github.com/xealgo/muddy/internal/config"
+	"github.com/xealgo/muddy/internal/event"
 	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/journal"
 	"github.com/xealgo/muddy/internal/server"
 	"github.com/xealgo/muddy/internal/services"
 )
 
 func main() {
+	if replayMain() || passwdMain() {
+		return
+	}
+
 	color.Green.Println("Starting Muddy!")
 
 	cfg, err := config.NewConfig(
@@ -30,7 +37,13 @@ func main() {
 	}
 
 	// Session manager instance used for managing player sessions
-	sm := game.NewSessionManager(64)
+	sm := game.NewSessionManager(cfg.MaxPlayers)
+	sm.SetSlots(cfg.MaxPlayers, cfg.ReservedSlots)
+	sm.SetLoginQueueSize(cfg.LoginQueueSize)
+	sm.SetOutputQueue(cfg.OutputQueueSize, game.OverflowPolicy(cfg.OutputQueuePolicy))
+	sm.SetResumeGracePeriod(cfg.ResumeGracePeriod)
+	sm.SetPendingTTL(cfg.PendingTTL)
+	sm.SetIdleTimeout(cfg.IdleTimeout, cfg.IdleWarning)
 
 	world := game.NewWorld()
 	err = world.LoadRoomsFromYaml("./data/test-world.yml")
@@ -39,8 +52,74 @@ func main() {
 		os.Exit(1)
 	}
 
+	socials, err := game.LoadSocialsFromYaml("./data/socials.yml")
+	if err != nil {
+		slog.Error("Failed to load socials", "error", err)
+		os.Exit(1)
+	}
+
+	guilds, err := game.LoadGuilds(filepath.Join(cfg.SaveDir, "guilds.json"))
+	if err != nil {
+		slog.Error("Failed to load guilds", "error", err)
+		os.Exit(1)
+	}
+
+	accounts, err := game.LoadAccounts(filepath.Join(cfg.SaveDir, "accounts.json"))
+	if err != nil {
+		slog.Error("Failed to load accounts", "error", err)
+		os.Exit(1)
+	}
+
+	bank, err := game.LoadBank(filepath.Join(cfg.SaveDir, "bank.json"), filepath.Join(cfg.SaveDir, "bank-audit.jsonl"))
+	if err != nil {
+		slog.Error("Failed to load bank", "error", err)
+		os.Exit(1)
+	}
+
+	chat, err := game.LoadChat(filepath.Join(cfg.SaveDir, "tells.json"), game.DefaultChannels...)
+	if err != nil {
+		slog.Error("Failed to load tells", "error", err)
+		os.Exit(1)
+	}
+
 	game := game.NewGame(world)
 	game.Sm = sm
+	game.Socials = socials
+	game.Guilds = guilds
+	game.Bank = bank
+	game.Accounts = accounts
+	game.Chat = chat
+	game.Moderation.SetRateLimit(cfg.ChatRateLimit, cfg.ChatRateWindow)
+	game.Moderation.SetFilter(cfg.ChatFilter)
+
+	// Broadcasts domain events, such as chat and movement, to the players they concern
+	sm.SetEvents(game.Events)
+	event.NewEventDispatcher(sm, game.Chat).Subscribe(game.Events)
+
+	// Parties and trades only hold players who are online
+	sm.OnRemove(game.LeaveParty)
+	sm.OnRemove(game.CancelTrade)
+
+	// Nobody can be traded with while they're disconnected, even if they resume
+	sm.OnDetach(game.CancelTrade)
+
+	// Records state changes so they can be replayed with `muddy replay`
+	gameJournal, err := journal.Open(cfg.JournalDir, journal.WithSegmentSize(int64(cfg.JournalSegmentSize)))
+	if err != nil {
+		slog.Error("Failed to open journal", "error", err)
+		os.Exit(1)
+	}
+	defer gameJournal.Close()
+
+	// Replaying starts over from here, since the world was just loaded
+	if err = game.RecordWorldLoaded(gameJournal); err != nil {
+		slog.Error("Failed to journal the world", "error", err)
+		os.Exit(1)
+	}
+
+	// Stopping writes the events still queued before the journal is closed
+	stopRecording := game.RecordTo(gameJournal)
+	defer stopRecording()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -56,13 +135,31 @@ func main() {
 		cancel()
 	}()
 
+	// Each room processes its players' commands on its own goroutine
+	wg.Add(1)
+	go world.Start(ctx, &wg)
+
+	// Pushes vitals and presence to players
+	wg.Add(1)
+	go game.StartTicker(ctx, &wg)
+
+	// Expires stale logins and disconnects idle players
+	wg.Add(1)
+	go sm.StartReaper(ctx, &wg)
+
+	loginService := services.NewLoginService(cfg, sm, accounts)
+	healthService := services.NewHealthService(cfg, game.State(), sm)
+
 	// HTTP server setup
 	httpServer, err := server.NewHttpServer(
 		cfg,
 		server.WithCORSHandler(),
+		server.WithWebSocketHandler(sm, game),
+		server.WithGateway(loginService, healthService),
 		server.WithStaticPageHandlers(
 			server.HttpServerStaticFileConfig{Path: "/", FilePath: "./public/index.html"},
-			// server.HttpServerStaticFileConfig{Path: "/game-client", FilePath: "./public/client.html"},
+			server.HttpServerStaticFileConfig{Path: "/styles.css", FilePath: "./public/styles.css"},
+			server.HttpServerStaticFileConfig{Path: "/client.js", FilePath: "./public/client.js"},
 		),
 	)
 
@@ -82,8 +179,8 @@ func main() {
 
 	// GRPC server setup
 	grpcServer := server.NewGrpcServer(cfg)
-	services.RegisterHealthService(cfg, grpcServer.Server, game.State(), sm)
-	services.RegisterLoginService(cfg, grpcServer.Server, sm)
+	services.RegisterHealthService(grpcServer.Server, healthService)
+	services.RegisterLoginService(grpcServer.Server, loginService)
 
 	wg.Add(1)
 	go func() {
@@ -106,6 +203,18 @@ func main() {
 		}
 	}()
 
+	// Telnet server setup (optional)
+	if cfg.TelnetPort > 0 {
+		telnetServer := server.NewTelnet(cfg, sm, game, loginService)
+
+		wg.Add(1)
+		go func() {
+			if err := telnetServer.StartServer(ctx, &wg); err != nil {
+				slog.Error("Telnet server failed", "error", err)
+			}
+		}()
+	}
+
 	wg.Wait()
 	slog.Info("Muddy server shutting down")
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/manifoldco/promptui"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// runPasswd sets a player's password, making their account if they don't have
+// one. Accounts for the names in ADMINS, BUILDERS and MODERATORS can only be
+// made this way. The server only reads the accounts file on startup, so it
+// should be stopped first.
+//
+//	muddy passwd [-accounts file] <username>
+func runPasswd(args []string) error {
+	flags := flag.NewFlagSet("passwd", flag.ContinueOnError)
+
+	accountsFile := flags.String("accounts", "./save/accounts.json", "accounts file, in SAVE_DIR")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: muddy passwd [-accounts file] <username>")
+	}
+
+	username := flags.Arg(0)
+
+	accounts, err := game.LoadAccounts(*accountsFile)
+	if err != nil {
+		return err
+	}
+
+	prompt := promptui.Prompt{
+		Label: fmt.Sprintf("New password for %s", username),
+		Mask:  '*',
+		Validate: func(input string) error {
+			if l := len(input); l < game.MinPasswordLength || l > game.MaxPasswordLength {
+				return fmt.Errorf("password must be between %d and %d characters", game.MinPasswordLength, game.MaxPasswordLength)
+			}
+			return nil
+		},
+	}
+
+	password, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	if err = accounts.SetPassword(username, password); err != nil {
+		return err
+	}
+
+	fmt.Printf("Password set for %s\n", username)
+	return nil
+}
+
+// passwdMain runs the password tool when it's requested on the command line,
+// returning false otherwise.
+func passwdMain() bool {
+	if len(os.Args) < 2 || os.Args[1] != "passwd" {
+		return false
+	}
+
+	if err := runPasswd(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	return true
+}
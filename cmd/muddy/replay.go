@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/journal"
+)
+
+// runReplay rebuilds the game's state from a snapshot plus the journal. The
+// world is reset to how it was loaded each time the journal shows the server
+// restarting.
+//
+//	muddy replay [-journal dir] [-world file | -snapshot file] [-player name] [-until seq] [-step] [-save file]
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+
+	journalDir := flags.String("journal", "./journal", "journal directory")
+	worldFile := flags.String("world", "./data/test-world.yml", "world the journal starts from")
+	snapshotFile := flags.String("snapshot", "", "snapshot to start from instead of the world")
+	player := flags.String("player", "", "only show entries and state for this player")
+	until := flags.Uint64("until", 0, "stop after this sequence number")
+	step := flags.Bool("step", false, "print every entry along with the player's state after it")
+	saveFile := flags.String("save", "", "save a snapshot of the replayed state to this file")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ws, err := loadReplayState(*worldFile, *snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	err = journal.Read(*journalDir, ws.Seq, func(entry journal.Entry) error {
+		if *until > 0 && entry.Seq > *until {
+			return journal.ErrorStop
+		}
+
+		description, username, err := ws.Apply(entry)
+		if err != nil {
+			return err
+		}
+
+		if !*step || (*player != "" && username != *player) {
+			return nil
+		}
+
+		fmt.Printf("#%d %s %s\n", entry.Seq, entry.Time.Format("2006-01-02 15:04:05"), description)
+
+		if state, ok := ws.Players[username]; ok {
+			fmt.Printf("    %s: %s\n", username, state.Describe())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replayed up to #%d\n", ws.Seq)
+
+	usernames := []string{}
+	for username := range ws.Players {
+		if *player == "" || username == *player {
+			usernames = append(usernames, username)
+		}
+	}
+
+	sort.Strings(usernames)
+
+	for _, username := range usernames {
+		fmt.Printf("%s: %s\n", username, ws.Players[username].Describe())
+	}
+
+	if *saveFile != "" {
+		return ws.Save(*saveFile)
+	}
+
+	return nil
+}
+
+// loadReplayState loads the state replaying starts from, a snapshot if one is
+// given and otherwise the world as it's loaded on startup.
+func loadReplayState(worldFile string, snapshotFile string) (*game.WorldState, error) {
+	if snapshotFile != "" {
+		return game.LoadWorldState(snapshotFile)
+	}
+
+	world := game.NewWorld()
+	if err := world.LoadRoomsFromYaml(worldFile); err != nil {
+		return nil, err
+	}
+
+	return game.NewWorldState(world), nil
+}
+
+// replayMain runs the replay tool when it's requested on the command line,
+// returning false otherwise.
+func replayMain() bool {
+	if len(os.Args) < 2 || os.Args[1] != "replay" {
+		return false
+	}
+
+	if err := runReplay(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	return true
+}
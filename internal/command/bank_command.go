@@ -0,0 +1,157 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// BankHistoryShown is how many recent transactions the balance lists.
+const BankHistoryShown = 5
+
+const (
+	MessageNoBanker    = "There's no banker here."
+	MessageVaultFull   = "Your vault is full."
+	MessageAccountGold = "Your bank account doesn't have that much gold."
+	MessageVaultNoItem = "Your vault doesn't have that item."
+)
+
+// bankName is how the bank is named in the journal.
+const bankName = "the bank"
+
+// BankCommand deposits gold and items with a banker in the room, takes them
+// back out, or shows the player's account.
+type BankCommand struct {
+	Action string // The command, deposit, withdraw or balance
+	Target string // Item id deposited or withdrawn
+	Gold   int    // Gold deposited or withdrawn, instead of an item
+}
+
+// Execute runs the bank action.
+func (cmd BankCommand) Execute(g *game.Game, ps *game.Player) string {
+	banker, ok := findBanker(g, ps)
+	if !ok {
+		return MessageNoBanker
+	}
+
+	switch cmd.Action {
+	case string(CommandDeposit):
+		return cmd.deposit(g, ps, banker)
+	case string(CommandWithdraw):
+		return cmd.withdraw(g, ps, banker)
+	default:
+		return bankBalance(g, ps)
+	}
+}
+
+// deposit puts gold or an item from the player's inventory in their account.
+func (cmd BankCommand) deposit(g *game.Game, ps *game.Player, banker *game.Banker) string {
+	if cmd.Gold > 0 {
+		if !ps.Inventory.TakeGold(cmd.Gold) {
+			return MessageNotEnoughGold
+		}
+
+		balance := g.Bank.DepositGold(ps.Username, banker.Name, cmd.Gold)
+
+		g.Events.Publish(game.Banked{Player: ps, Bank: bankName, Gold: cmd.Gold})
+		return fmt.Sprintf("You deposited %d gold with %s, your balance is %d gold.", cmd.Gold, banker.Name, balance)
+	}
+
+	item, ok := ps.Inventory.Remove(cmd.Target)
+	if !ok {
+		return MessageNoSuchItem
+	}
+
+	if _, err := g.Bank.DepositItem(ps.Username, banker.Name, item); err != nil {
+		ps.Inventory.Add(item)
+		return bankError(err)
+	}
+
+	g.Events.Publish(game.Banked{Player: ps, Bank: bankName, Item: &item})
+	return fmt.Sprintf("You put the %s in your vault with %s.", item.Name, banker.Name)
+}
+
+// withdraw takes gold or an item out of the player's account.
+func (cmd BankCommand) withdraw(g *game.Game, ps *game.Player, banker *game.Banker) string {
+	if cmd.Gold > 0 {
+		balance, err := g.Bank.WithdrawGold(ps.Username, banker.Name, cmd.Gold)
+		if err != nil {
+			return bankError(err)
+		}
+
+		ps.Inventory.AddGold(cmd.Gold)
+
+		g.Events.Publish(game.Banked{Player: ps, Bank: bankName, Gold: cmd.Gold, Withdraw: true})
+		return fmt.Sprintf("You withdrew %d gold from %s, your balance is %d gold.", cmd.Gold, banker.Name, balance)
+	}
+
+	item, err := g.Bank.WithdrawItem(ps.Username, banker.Name, cmd.Target)
+	if err != nil {
+		return bankError(err)
+	}
+
+	item = ps.Inventory.Add(item)
+
+	g.Events.Publish(game.Banked{Player: ps, Bank: bankName, Item: &item, Withdraw: true})
+	return fmt.Sprintf("You took the %s out of your vault with %s.", item.Name, banker.Name)
+}
+
+// bankBalance describes the player's account and their recent transactions.
+func bankBalance(g *game.Game, ps *game.Player) string {
+	gold, items := g.Bank.Account(ps.Username)
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Your bank account holds %d gold.\n", gold))
+	builder.WriteString(fmt.Sprintf("Your vault holds %d of %d items", len(items), game.VaultSize))
+
+	if len(items) == 0 {
+		builder.WriteString(".\n")
+	} else {
+		builder.WriteString(":\n")
+		for _, item := range items {
+			builder.WriteString(fmt.Sprintf("- %s (ID: %s)\n", item.Name, item.ID))
+		}
+	}
+
+	history := g.Bank.History(ps.Username, BankHistoryShown)
+	if len(history) > 0 {
+		builder.WriteString("Recent transactions:\n")
+		for _, tx := range history {
+			builder.WriteString(fmt.Sprintf("- %s\n", tx.Describe()))
+		}
+	}
+
+	return builder.String()
+}
+
+// findBanker returns a banker in the player's room.
+func findBanker(g *game.Game, ps *game.Player) (*game.Banker, bool) {
+	room, ok := g.World.GetRoomById(ps.RoomId())
+	if !ok {
+		return nil, false
+	}
+
+	for _, npc := range room.Npcs {
+		if banker, ok := npc.(*game.Banker); ok {
+			return banker, true
+		}
+	}
+
+	return nil, false
+}
+
+// bankError returns the message for an error from the bank.
+func bankError(err error) string {
+	switch {
+	case errors.Is(err, game.ErrorVaultFull):
+		return MessageVaultFull
+	case errors.Is(err, game.ErrorNotEnoughGold):
+		return MessageAccountGold
+	case errors.Is(err, game.ErrorItemNotFound):
+		return MessageVaultNoItem
+	default:
+		return MessageInvalidCmd
+	}
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageUnknownChannel = "There's no such channel."
+	MessageNotInChannel   = "You haven't joined the %s channel."
+)
+
+// Channel actions
+const (
+	ChannelJoin   = "join"
+	ChannelLeave  = "leave"
+	ChannelMute   = "mute"
+	ChannelUnmute = "unmute"
+)
+
+// channelMessages confirm the channel actions.
+var channelMessages = map[string]string{
+	ChannelLeave:  "You left the %s channel.",
+	ChannelMute:   "You muted the %s channel.",
+	ChannelUnmute: "You unmuted the %s channel.",
+}
+
+// ChannelCommand lists the global channels or changes the player's
+// membership of one.
+type ChannelCommand struct {
+	Action  string // Empty to list the channels
+	Channel string
+}
+
+// Execute joins, leaves, mutes or unmutes a channel, or lists them.
+func (cmd ChannelCommand) Execute(g *game.Game, ps *game.Player) string {
+	var err error
+
+	switch cmd.Action {
+	case "":
+		return listChannels(g, ps)
+	case ChannelJoin:
+		history, err := g.Chat.Join(ps.Username, cmd.Channel)
+		if err != nil {
+			return channelError(err, cmd.Channel)
+		}
+
+		builder := strings.Builder{}
+		builder.WriteString(fmt.Sprintf("You joined the %s channel.\n", cmd.Channel))
+
+		for _, message := range history {
+			builder.WriteString(fmt.Sprintf("[%s] %s: %s\n", message.Channel, message.Talker, message.Text))
+		}
+
+		return builder.String()
+	case ChannelLeave:
+		err = g.Chat.Leave(ps.Username, cmd.Channel)
+	case ChannelMute:
+		err = g.Chat.Mute(ps.Username, cmd.Channel, true)
+	case ChannelUnmute:
+		err = g.Chat.Mute(ps.Username, cmd.Channel, false)
+	}
+
+	if err != nil {
+		return channelError(err, cmd.Channel)
+	}
+
+	return fmt.Sprintf(channelMessages[cmd.Action], cmd.Channel)
+}
+
+// listChannels describes the channels and the player's membership of them.
+func listChannels(g *game.Game, ps *game.Player) string {
+	memberships := g.Chat.Memberships(ps.Username)
+
+	builder := strings.Builder{}
+	builder.WriteString("Channels:\n")
+
+	for _, name := range g.Chat.Channels() {
+		muted, joined := memberships[name]
+
+		switch {
+		case muted:
+			builder.WriteString(fmt.Sprintf("- %s (muted)\n", name))
+		case joined:
+			builder.WriteString(fmt.Sprintf("- %s (joined)\n", name))
+		default:
+			builder.WriteString(fmt.Sprintf("- %s\n", name))
+		}
+	}
+
+	return builder.String()
+}
+
+// channelError describes a channel error to the player.
+func channelError(err error, channel string) string {
+	if errors.Is(err, game.ErrorNotInChannel) {
+		return fmt.Sprintf(MessageNotInChannel, channel)
+	}
+
+	return MessageUnknownChannel
+}
+
+// ChatCommand sends a message on a global channel.
+type ChatCommand struct {
+	Channel string
+	Message string
+}
+
+// Execute sends the message to everyone listening to the channel.
+func (cmd ChatCommand) Execute(g *game.Game, ps *game.Player) string {
+	if message, ok := allowChat(g, ps); !ok {
+		return message
+	}
+
+	m := g.Moderation.Filter(strings.TrimRight(cmd.Message, "\n"))
+
+	listeners, err := g.Chat.Post(ps.Username, game.ChatMessage{
+		Channel:  cmd.Channel,
+		Talker:   ps.DisplayName,
+		Username: ps.Username,
+		Text:     m,
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return channelError(err, cmd.Channel)
+	}
+
+	g.Events.Publish(game.ChannelSaid{Player: ps, Channel: cmd.Channel, Text: m, Listeners: listeners})
+
+	return ""
+}
@@ -15,11 +15,38 @@ const (
 	CommandPickup    CommandType = "pickup"    // pickup {item-name} - adds an item to the player's inventory
 	CommandInventory CommandType = "inventory" // reports what's in the player's inventory
 	CommandSay       CommandType = "say"       // say hello everyone! broadcasts a chat message to everyone in the room
+	CommandUnlock    CommandType = "unlock"    // unlock {door} - unlocks a locked door with the key the player carries
 	CommandTalk      CommandType = "talk"      // talk {npc-name} - talk to an NPC in the room
 	CommandSell      CommandType = "sell"      // sell {npc-name} {item-name} - sell an item to a merchant NPC in the room
+	CommandNotify    CommandType = "notify"    // notify [on|off] - toggles game wide join and leave notices
+	CommandTell      CommandType = "tell"      // tell {player} {message} - sends a private message, stored if they're offline
+	CommandReply     CommandType = "reply"     // reply {message} - tells the last player who told you something
+	CommandChannel   CommandType = "channel"   // channel [join|leave|mute|unmute {channel}] - lists or changes your channels
+	CommandChat      CommandType = "chat"      // chat {channel} {message} or {channel} {message} - talks on a global channel
+	CommandEmote     CommandType = "emote"     // emote {text} - shows the room what you're doing, e.g. emote waves
+	CommandSocials   CommandType = "socials"   // lists the socials loaded from the socials table
+	CommandIgnore    CommandType = "ignore"    // ignore [player] - hides everything a player says, or lists who you ignore
+	CommandUnignore  CommandType = "unignore"  // unignore {player} - stops ignoring a player
+	CommandMute      CommandType = "mute"      // mute {player} {duration} - moderators stop a player chatting, e.g. mute bob 10m
+	CommandUnmute    CommandType = "unmute"    // unmute {player} - moderators let a muted player chat again
+	CommandReport    CommandType = "report"    // report {player} {reason} - reports a player along with recent room chat
+	CommandReports   CommandType = "reports"   // moderators list the reports filed
+	CommandParty     CommandType = "party"     // party [invite|accept|leave|kick|say|split ...] - groups up with other players
+	CommandGuild     CommandType = "guild"     // guild [create|invite|accept|leave|kick|promote|demote|roster|say|deposit|withdraw ...] - joins and runs guilds
+	CommandTrade     CommandType = "trade"     // trade [player|offer|remove|confirm|cancel ...] - swaps items and gold with another player
+	CommandDeposit   CommandType = "deposit"   // deposit {item id|amount gold} - puts gold or an item in your bank account at a banker
+	CommandWithdraw  CommandType = "withdraw"  // withdraw {item id|amount gold} - takes gold or an item out of your bank account at a banker
+	CommandBalance   CommandType = "balance"   // shows your bank account and recent transactions at a banker
 )
 
 // Command interface for executing commands
 type Command interface {
 	Execute(game *game.Game, ps *game.Player) string
 }
+
+// Handoff is implemented by commands which move the player to another room.
+// Execute runs on the room the player leaves, Arrive finishes the command on
+// the room they entered.
+type Handoff interface {
+	Arrive(game *game.Game, ps *game.Player, fromRoomId int) string
+}
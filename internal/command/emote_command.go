@@ -0,0 +1,144 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageTargetNotFound = "You don't see %s here."
+	MessageSocialNoTarget = "You can't %s anyone."
+	MessageSocialTarget   = "Who do you want to %s?"
+)
+
+// EmoteCommand shows the room the player doing something, e.g. "emote waves"
+// is shown as "Alice waves".
+type EmoteCommand struct {
+	Text string
+}
+
+// Execute shows the emote to everyone in the current room.
+func (cmd EmoteCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
+	if !ok {
+		return MessageInvalidCmd
+	}
+
+	if message, ok := allowChat(g, ps); !ok {
+		return message
+	}
+
+	text := ps.DisplayName + " " + g.Moderation.Filter(strings.TrimRight(cmd.Text, "\n"))
+
+	g.Moderation.RecordRoomChat(currentRoom.ID, game.ChatMessage{Talker: ps.DisplayName, Text: text, Time: time.Now()})
+	g.Events.Publish(game.Emoted{Player: ps, RoomId: currentRoom.ID, Text: text})
+
+	return text
+}
+
+// SocialCommand uses a social from the socials table, optionally on another
+// player or an NPC in the room.
+type SocialCommand struct {
+	Social game.Social
+	Target string
+}
+
+// Execute shows the social to the player, their target and the rest of the room.
+func (cmd SocialCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
+	if !ok {
+		return MessageInvalidCmd
+	}
+
+	social := cmd.Social
+	emoted := game.Emoted{Player: ps, RoomId: currentRoom.ID}
+	response := ""
+
+	if cmd.Target == "" {
+		if !social.Untargeted() {
+			return fmt.Sprintf(MessageSocialTarget, social.Name)
+		}
+
+		emoted.Text = social.Render(social.Others, ps.DisplayName, "")
+		response = social.Render(social.Actor, ps.DisplayName, "")
+	} else {
+		if !social.Targeted() {
+			return fmt.Sprintf(MessageSocialNoTarget, social.Name)
+		}
+
+		target, name, ok := findTarget(g, currentRoom, cmd.Target)
+		if !ok {
+			return fmt.Sprintf(MessageTargetNotFound, cmd.Target)
+		}
+
+		if target != nil && target.GetUUID() == ps.GetUUID() {
+			return fmt.Sprintf(MessageSocialNoTarget, social.Name)
+		}
+
+		emoted.Text = social.Render(social.TargetOthers, ps.DisplayName, name)
+		response = social.Render(social.TargetActor, ps.DisplayName, name)
+
+		if target != nil {
+			emoted.Target = target
+			emoted.TargetText = social.Render(social.Target, ps.DisplayName, name)
+		}
+	}
+
+	if message, ok := allowChat(g, ps); !ok {
+		return message
+	}
+
+	g.Moderation.RecordRoomChat(currentRoom.ID, game.ChatMessage{Talker: ps.DisplayName, Text: emoted.Text, Time: time.Now()})
+	g.Events.Publish(emoted)
+
+	return response
+}
+
+// SocialsCommand lists the socials.
+type SocialsCommand struct{}
+
+// Execute lists the socials and whether they can be used on a target.
+func (cmd SocialsCommand) Execute(g *game.Game, ps *game.Player) string {
+	if len(g.Socials) == 0 {
+		return "There are no socials."
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("The following socials are available\n")
+
+	for _, social := range g.Socials {
+		switch {
+		case social.Untargeted() && social.Targeted():
+			builder.WriteString(fmt.Sprintf("- %s [target]\n", social.Name))
+		case social.Targeted():
+			builder.WriteString(fmt.Sprintf("- %s <target>\n", social.Name))
+		default:
+			builder.WriteString(fmt.Sprintf("- %s\n", social.Name))
+		}
+	}
+
+	return builder.String()
+}
+
+// findTarget finds a player or NPC in the room by name. The player is nil when
+// the target is an NPC.
+func findTarget(g *game.Game, room *game.Room, name string) (*game.Player, string, bool) {
+	if g.Sm != nil {
+		for _, other := range g.Sm.GetPlayersInRoom(room.ID, "") {
+			if strings.EqualFold(other.Username, name) || strings.EqualFold(other.DisplayName, name) {
+				return other, other.DisplayName, true
+			}
+		}
+	}
+
+	for _, npc := range room.Npcs {
+		if strings.EqualFold(npc.GetData().Name, name) {
+			return nil, npc.GetData().Name, true
+		}
+	}
+
+	return nil, "", false
+}
@@ -0,0 +1,365 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// Guild actions
+const (
+	GuildInfo     = "info"
+	GuildCreate   = "create"
+	GuildInvite   = "invite"
+	GuildAccept   = "accept"
+	GuildLeave    = "leave"
+	GuildKick     = "kick"
+	GuildPromote  = "promote"
+	GuildDemote   = "demote"
+	GuildRoster   = "roster"
+	GuildSay      = "say"
+	GuildDeposit  = "deposit"
+	GuildWithdraw = "withdraw"
+	GuildDisband  = "disband" // Admins only
+	GuildRename   = "rename"  // Admins only
+)
+
+const (
+	MessageNotInGuild       = "You aren't in a guild."
+	MessageNotGuildMember   = "%s isn't in your guild."
+	MessageAlreadyInGuild   = "%s is already in a guild."
+	MessageGuildExists      = "There's already a guild called %s."
+	MessageUnknownGuild     = "There's no guild called %s."
+	MessageInvalidGuildName = "Guild names are 3 to 20 letters or digits, starting with a letter."
+	MessageNoGuildInvite    = "Nobody has invited you to a guild."
+	MessageGuildRank        = "Your rank in the guild doesn't allow that."
+	MessageGuildLeader      = "Promote an officer to leader before leaving the guild."
+	MessageNotEnoughGold    = "You don't have that much gold."
+	MessageBankNoGold       = "The guild bank doesn't have that much gold."
+	MessageNoSuchItem       = "You don't have that item."
+	MessageBankNoItem       = "The guild bank doesn't have that item."
+)
+
+// GuildCommand manages the player's guild, its bank, and talks to it.
+type GuildCommand struct {
+	Action  string
+	Target  string // Player, guild name or item id the action is for
+	NewName string // Name a guild is renamed to
+	Gold    int    // Gold deposited or withdrawn, instead of an item
+	Message string
+}
+
+// Execute runs the guild action.
+func (cmd GuildCommand) Execute(g *game.Game, ps *game.Player) string {
+	switch cmd.Action {
+	case GuildCreate:
+		if err := g.Guilds.Found(ps.Username, cmd.Target); err != nil {
+			return guildError(err, cmd.Target)
+		}
+
+		return fmt.Sprintf("You founded the guild %s.", cmd.Target)
+	case GuildInvite:
+		return cmd.invite(g, ps)
+	case GuildAccept:
+		name, err := g.Guilds.Accept(ps.Username)
+		if err != nil {
+			return guildError(err, ps.DisplayName)
+		}
+
+		g.TellGuild(g.Guilds.Members(name), fmt.Sprintf("%s joined the guild.", ps.DisplayName))
+		return ""
+	case GuildLeave:
+		name, err := g.Guilds.Leave(ps.Username)
+		if err != nil {
+			return guildError(err, ps.DisplayName)
+		}
+
+		g.TellGuild(g.Guilds.Members(name), fmt.Sprintf("%s left the guild.", ps.DisplayName))
+		return fmt.Sprintf("You left the guild %s.", name)
+	case GuildKick:
+		return cmd.kick(g, ps)
+	case GuildPromote, GuildDemote:
+		return cmd.rank(g, ps)
+	case GuildRoster:
+		return guildRoster(g, ps)
+	case GuildSay:
+		return cmd.say(g, ps)
+	case GuildDeposit:
+		return cmd.deposit(g, ps)
+	case GuildWithdraw:
+		return cmd.withdraw(g, ps)
+	case GuildDisband, GuildRename:
+		return cmd.admin(g, ps)
+	default:
+		return guildInfo(g, ps)
+	}
+}
+
+// invite invites the target into the player's guild.
+func (cmd GuildCommand) invite(g *game.Game, ps *game.Player) string {
+	target, ok := g.Sm.FindPlayer(cmd.Target)
+	if !ok {
+		return fmt.Sprintf(MessagePlayerOffline, cmd.Target)
+	}
+
+	name, err := g.Guilds.Invite(ps.Username, target.Username)
+	if err != nil {
+		return guildError(err, target.DisplayName)
+	}
+
+	g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf("%s invited you to join the guild %s, type 'guild accept' to join.", ps.DisplayName, name))
+
+	return fmt.Sprintf("You invited %s to join the guild.", target.DisplayName)
+}
+
+// kick removes the target from the player's guild, whether they're online or not.
+func (cmd GuildCommand) kick(g *game.Game, ps *game.Player) string {
+	if _, _, ok := g.Guilds.GuildOf(ps.Username); !ok {
+		return MessageNotInGuild
+	}
+
+	name, err := g.Guilds.Kick(ps.Username, cmd.Target)
+	if errors.Is(err, game.ErrorNotInGuild) {
+		return fmt.Sprintf(MessageNotGuildMember, cmd.Target)
+	}
+
+	if err != nil {
+		return guildError(err, cmd.Target)
+	}
+
+	if target, ok := g.Sm.FindPlayer(cmd.Target); ok {
+		g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf("%s removed you from the guild %s.", ps.DisplayName, name))
+	}
+
+	g.TellGuild(g.Guilds.Members(name), fmt.Sprintf("%s removed %s from the guild.", ps.DisplayName, cmd.Target))
+	return ""
+}
+
+// rank promotes or demotes the target.
+func (cmd GuildCommand) rank(g *game.Game, ps *game.Player) string {
+	name, _, ok := g.Guilds.GuildOf(ps.Username)
+	if !ok {
+		return MessageNotInGuild
+	}
+
+	rank := game.RankMember
+
+	var err error
+	if cmd.Action == GuildPromote {
+		rank, err = g.Guilds.Promote(ps.Username, cmd.Target)
+	} else {
+		err = g.Guilds.Demote(ps.Username, cmd.Target)
+	}
+
+	if errors.Is(err, game.ErrorNotInGuild) {
+		return fmt.Sprintf(MessageNotGuildMember, cmd.Target)
+	}
+
+	if err != nil {
+		return guildError(err, cmd.Target)
+	}
+
+	g.TellGuild(g.Guilds.Members(name), fmt.Sprintf("%s is now the guild's %s.", strings.ToLower(cmd.Target), rank))
+	return ""
+}
+
+// say talks to every guild member who is online.
+func (cmd GuildCommand) say(g *game.Game, ps *game.Player) string {
+	name, _, ok := g.Guilds.GuildOf(ps.Username)
+	if !ok {
+		return MessageNotInGuild
+	}
+
+	if response, ok := allowChat(g, ps); !ok {
+		return response
+	}
+
+	message := g.Moderation.Filter(strings.TrimRight(cmd.Message, "\n"))
+
+	for _, username := range g.Guilds.Members(name) {
+		member, online := g.Sm.FindPlayer(username)
+		if !online || (member != ps && g.Chat.IsIgnoring(member.Username, ps.Username)) {
+			continue
+		}
+
+		g.Sm.SendToPlayer(member.GetUUID(), fmt.Sprintf("[guild] %s: %s", ps.DisplayName, message))
+		member.SendOutOfBand(game.OOBCommChannel, game.ChannelData{Channel: "guild", Talker: ps.DisplayName, Text: message})
+	}
+
+	return ""
+}
+
+// deposit puts gold or an item from the player's inventory in the guild bank.
+func (cmd GuildCommand) deposit(g *game.Game, ps *game.Player) string {
+	name, _, ok := g.Guilds.GuildOf(ps.Username)
+	if !ok {
+		return MessageNotInGuild
+	}
+
+	if cmd.Gold > 0 {
+		if !ps.Inventory.TakeGold(cmd.Gold) {
+			return MessageNotEnoughGold
+		}
+
+		if err := g.Guilds.DepositGold(ps.Username, cmd.Gold); err != nil {
+			ps.Inventory.AddGold(cmd.Gold)
+			return guildError(err, ps.DisplayName)
+		}
+
+		g.Events.Publish(game.Banked{Player: ps, Bank: guildBank(name), Gold: cmd.Gold})
+		return fmt.Sprintf("You deposited %d gold in the guild bank.", cmd.Gold)
+	}
+
+	item, ok := ps.Inventory.Remove(cmd.Target)
+	if !ok {
+		return MessageNoSuchItem
+	}
+
+	if _, err := g.Guilds.DepositItem(ps.Username, item); err != nil {
+		ps.Inventory.Add(item)
+		return guildError(err, ps.DisplayName)
+	}
+
+	g.Events.Publish(game.Banked{Player: ps, Bank: guildBank(name), Item: &item})
+	return fmt.Sprintf("You deposited the %s in the guild bank.", item.Name)
+}
+
+// withdraw takes gold or an item out of the guild bank.
+func (cmd GuildCommand) withdraw(g *game.Game, ps *game.Player) string {
+	name, _, ok := g.Guilds.GuildOf(ps.Username)
+	if !ok {
+		return MessageNotInGuild
+	}
+
+	if cmd.Gold > 0 {
+		if err := g.Guilds.WithdrawGold(ps.Username, cmd.Gold); err != nil {
+			return guildError(err, ps.DisplayName)
+		}
+
+		ps.Inventory.AddGold(cmd.Gold)
+
+		g.Events.Publish(game.Banked{Player: ps, Bank: guildBank(name), Gold: cmd.Gold, Withdraw: true})
+		return fmt.Sprintf("You withdrew %d gold from the guild bank.", cmd.Gold)
+	}
+
+	item, err := g.Guilds.WithdrawItem(ps.Username, cmd.Target)
+	if err != nil {
+		return guildError(err, ps.DisplayName)
+	}
+
+	item = ps.Inventory.Add(item)
+
+	g.Events.Publish(game.Banked{Player: ps, Bank: guildBank(name), Item: &item, Withdraw: true})
+	return fmt.Sprintf("You withdrew the %s from the guild bank.", item.Name)
+}
+
+// admin disbands or renames a guild.
+func (cmd GuildCommand) admin(g *game.Game, ps *game.Player) string {
+	if ps.Role != game.RoleAdmin {
+		return MessageInvalidCmd
+	}
+
+	if cmd.Action == GuildDisband {
+		members, err := g.Guilds.Disband(cmd.Target)
+		if err != nil {
+			return guildError(err, cmd.Target)
+		}
+
+		g.TellGuild(members, fmt.Sprintf("The guild %s was disbanded by an admin.", cmd.Target))
+		return fmt.Sprintf("You disbanded the guild %s.", cmd.Target)
+	}
+
+	members, err := g.Guilds.Rename(cmd.Target, cmd.NewName)
+	if err != nil {
+		return guildError(err, cmd.NewName)
+	}
+
+	g.TellGuild(members, fmt.Sprintf("The guild was renamed to %s by an admin.", cmd.NewName))
+	return fmt.Sprintf("You renamed the guild %s to %s.", cmd.Target, cmd.NewName)
+}
+
+// guildInfo describes the player's guild and what's in its bank.
+func guildInfo(g *game.Game, ps *game.Player) string {
+	name, rank, ok := g.Guilds.GuildOf(ps.Username)
+	if !ok {
+		return MessageNotInGuild
+	}
+
+	gold, items, err := g.Guilds.BankContents(ps.Username)
+	if err != nil {
+		return guildError(err, ps.DisplayName)
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("You are a %s of the guild %s (ID: %d).\n", rank, name, g.Guilds.GuildIdOf(ps.Username)))
+	builder.WriteString(fmt.Sprintf("The guild bank holds %d gold", gold))
+
+	if len(items) == 0 {
+		builder.WriteString(".\n")
+		return builder.String()
+	}
+
+	builder.WriteString(" and:\n")
+	for _, item := range items {
+		builder.WriteString(fmt.Sprintf("- %s (ID: %s)\n", item.Name, item.ID))
+	}
+
+	return builder.String()
+}
+
+// guildRoster lists the members of the player's guild.
+func guildRoster(g *game.Game, ps *game.Player) string {
+	roster, err := g.Guilds.Roster(ps.Username)
+	if err != nil {
+		return guildError(err, ps.DisplayName)
+	}
+
+	name, _, _ := g.Guilds.GuildOf(ps.Username)
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Members of %s:\n", name))
+
+	for _, member := range roster {
+		builder.WriteString(fmt.Sprintf("- %s, %s", member.Username, member.Rank))
+		if _, online := g.Sm.FindPlayer(member.Username); online {
+			builder.WriteString(" (online)")
+		}
+
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+// guildBank returns the name guild banks are journaled under.
+func guildBank(name string) string {
+	return "guild " + name
+}
+
+// guildError returns the message for a guild error.
+func guildError(err error, name string) string {
+	switch {
+	case errors.Is(err, game.ErrorInvalidGuildName):
+		return MessageInvalidGuildName
+	case errors.Is(err, game.ErrorGuildExists):
+		return fmt.Sprintf(MessageGuildExists, name)
+	case errors.Is(err, game.ErrorUnknownGuild):
+		return fmt.Sprintf(MessageUnknownGuild, name)
+	case errors.Is(err, game.ErrorAlreadyInGuild):
+		return fmt.Sprintf(MessageAlreadyInGuild, name)
+	case errors.Is(err, game.ErrorNoGuildInvite):
+		return MessageNoGuildInvite
+	case errors.Is(err, game.ErrorGuildRank):
+		return MessageGuildRank
+	case errors.Is(err, game.ErrorGuildLeader):
+		return MessageGuildLeader
+	case errors.Is(err, game.ErrorNotEnoughGold):
+		return MessageBankNoGold
+	case errors.Is(err, game.ErrorItemNotFound):
+		return MessageBankNoItem
+	default:
+		return MessageNotInGuild
+	}
+}
@@ -15,10 +15,35 @@ func (h HelpCommand) Execute(g *game.Game, ps *game.Player) string {
 	builder.WriteString("The following commands are available\n")
 	builder.WriteString("- look: Describe your surroundings\n")
 	builder.WriteString("- move <direction>: Move in a direction (north, south, east, west)\n")
+	builder.WriteString("- unlock <door>: Unlock a locked door with its key\n")
 	builder.WriteString("- say <message>: Send a message to other players in the same room\n")
+	builder.WriteString("- tell <player> <message>: Send a private message, delivered on their next login if they're offline\n")
+	builder.WriteString("- reply <message>: Answer the last player who told you something\n")
+	builder.WriteString("- channel [join|leave|mute|unmute <channel>]: List the chat channels or change yours\n")
+	builder.WriteString("- <channel> <message>: Talk on a chat channel you joined, e.g. ooc hello\n")
+	builder.WriteString("- emote <text>: Show the room what you're doing, e.g. emote waves\n")
+	builder.WriteString("- socials: List the socials, such as smile and hug <player>\n")
+	builder.WriteString("- ignore [player] / unignore <player>: Hide everything a player says, or list who you ignore\n")
+	builder.WriteString("- report <player> <reason>: Report a player to the moderators\n")
 	builder.WriteString("- help: Show this help message\n")
 	builder.WriteString("- sell <merchant name> <item name>: Sell an inventory item\n")
 	builder.WriteString("- talk <merchant name>: Talk to an NPC\n")
+	builder.WriteString("- notify [on|off]: Toggle messages about players joining and leaving the game\n")
+	builder.WriteString("- party [invite|kick <player>|accept|leave]: Show your party or manage it, members follow the leader\n")
+	builder.WriteString("- party say <message> / party split on|off: Talk to your party, or share sale gold with members in the room\n")
+	builder.WriteString("- guild [create|invite|kick|promote|demote <name>|accept|leave|roster]: Show your guild or manage it\n")
+	builder.WriteString("- guild say <message> / guild deposit|withdraw <item id>|<amount> gold: Talk to your guild or use its bank\n")
+	builder.WriteString("- trade [<player>|confirm|cancel] / trade offer|remove <item id>|<amount> gold: Trade with a player in the room\n")
+	builder.WriteString("- deposit|withdraw <item id>|<amount> gold / balance: Use your bank account at a banker\n")
+
+	if ps.Role.CanModerate() {
+		builder.WriteString("- mute <player> <duration> / unmute <player>: Stop a player chatting, e.g. mute bob 10m\n")
+		builder.WriteString("- reports: List the reports players filed\n")
+	}
+
+	if ps.Role == game.RoleAdmin {
+		builder.WriteString("- guild disband <guild> / guild rename <guild> <new name>: Disband or rename a guild\n")
+	}
 
 	return builder.String()
 }
@@ -13,7 +13,7 @@ type LookCommand struct {
 
 // Execute allows the player to look around in the current room.
 func (cmd LookCommand) Execute(game *game.Game, ps *game.Player) string {
-	currentRoom, ok := game.World.GetRoomById(ps.CurrentRoomId)
+	currentRoom, ok := game.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidCmd
 	}
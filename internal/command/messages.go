@@ -10,4 +10,5 @@ const (
 	MessageInvalidMove string = "You can't move there"
 	MessageDoorLocked  string = "The door seems to be locked"
 	MessageMoveSuccess string = "You move to the %s"
+	MessageDoorGuild   string = "Only members of %s may pass that way"
 )
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageMuted          = "You can't chat for another %s."
+	MessageIgnoringYou    = "%s is ignoring you."
+	MessageIgnoreSelf     = "You can't ignore yourself."
+	MessageReportSent     = "Thanks, your report was sent to the moderators."
+	MessageNoReports      = "There are no reports."
+	MessagePlayerMuted    = "%s can't chat for %s."
+	MessagePlayerUnmuted  = "%s can chat again."
+	MessageMutedByStaff   = "You have been muted by a moderator for %s."
+	MessageUnmutedByStaff = "A moderator unmuted you."
+)
+
+// allowChat checks the player isn't muted or flooding chat, returning the
+// message to show them if they are.
+func allowChat(g *game.Game, ps *game.Player) (string, bool) {
+	muted, ok := g.Moderation.Allow(ps.Username)
+	if !ok {
+		return fmt.Sprintf(MessageMuted, muted.Round(time.Second)), false
+	}
+
+	return "", true
+}
+
+// IgnoreCommand hides or shows again everything another player says.
+type IgnoreCommand struct {
+	Target string // Empty to list the players ignored
+	Undo   bool
+}
+
+// Execute ignores or unignores the target, or lists who the player ignores.
+func (cmd IgnoreCommand) Execute(g *game.Game, ps *game.Player) string {
+	if cmd.Target == "" {
+		ignored := g.Chat.Ignored(ps.Username)
+		if len(ignored) == 0 {
+			return "You aren't ignoring anyone."
+		}
+
+		return "You are ignoring: " + strings.Join(ignored, ", ")
+	}
+
+	if strings.EqualFold(cmd.Target, ps.Username) {
+		return MessageIgnoreSelf
+	}
+
+	g.Chat.Ignore(ps.Username, cmd.Target, !cmd.Undo)
+
+	if cmd.Undo {
+		return fmt.Sprintf("You are no longer ignoring %s.", cmd.Target)
+	}
+
+	return fmt.Sprintf("You are now ignoring %s.", cmd.Target)
+}
+
+// MuteCommand lets moderators stop a player from chatting for a while.
+type MuteCommand struct {
+	Target   string
+	Duration time.Duration // 0 unmutes the player
+}
+
+// Execute mutes or unmutes the target, telling them if they're online.
+func (cmd MuteCommand) Execute(g *game.Game, ps *game.Player) string {
+	if !ps.Role.CanModerate() {
+		return MessageInvalidCmd
+	}
+
+	g.Moderation.Mute(cmd.Target, cmd.Duration)
+
+	target, online := g.Sm.FindPlayer(cmd.Target)
+
+	if cmd.Duration <= 0 {
+		if online {
+			g.Sm.SendToPlayer(target.GetUUID(), MessageUnmutedByStaff)
+		}
+
+		return fmt.Sprintf(MessagePlayerUnmuted, cmd.Target)
+	}
+
+	if online {
+		g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf(MessageMutedByStaff, cmd.Duration))
+	}
+
+	return fmt.Sprintf(MessagePlayerMuted, cmd.Target, cmd.Duration)
+}
+
+// ReportCommand reports a player to the moderators, along with what was
+// recently said in the room.
+type ReportCommand struct {
+	Target string
+	Reason string
+}
+
+// Execute files the report and tells the moderators who are online. Reports
+// count towards the chat rate limit, so they can't be used to flood moderators.
+func (cmd ReportCommand) Execute(g *game.Game, ps *game.Player) string {
+	if message, ok := allowChat(g, ps); !ok {
+		return message
+	}
+
+	report := g.Moderation.Report(ps.Username, cmd.Target, ps.RoomId(), strings.TrimRight(cmd.Reason, "\n"))
+
+	for _, other := range g.Sm.GetActivePlayers() {
+		if other.Role.CanModerate() {
+			g.Sm.SendToPlayer(other.GetUUID(), fmt.Sprintf("[report] %s reported %s: %s", report.Reporter, report.Target, report.Reason))
+		}
+	}
+
+	return MessageReportSent
+}
+
+// ReportsCommand lets moderators read the reports filed.
+type ReportsCommand struct{}
+
+// Execute lists the reports along with the chat captured with them.
+func (cmd ReportsCommand) Execute(g *game.Game, ps *game.Player) string {
+	if !ps.Role.CanModerate() {
+		return MessageInvalidCmd
+	}
+
+	reports := g.Moderation.Reports()
+	if len(reports) == 0 {
+		return MessageNoReports
+	}
+
+	builder := strings.Builder{}
+
+	for i, report := range reports {
+		builder.WriteString(fmt.Sprintf("#%d [%s] %s reported %s in room %d: %s\n",
+			i+1, report.Time.Format("Jan 2 15:04"), report.Reporter, report.Target, report.RoomId, report.Reason))
+
+		for _, message := range report.Context {
+			builder.WriteString(fmt.Sprintf("    [%s] %s: %s\n", message.Time.Format("15:04:05"), message.Talker, message.Text))
+		}
+	}
+
+	return builder.String()
+}
@@ -21,8 +21,8 @@ type MoveCommand struct {
 }
 
 // Execute allows the player to move to an adjacent room if the door is not locked.
-func (cmd MoveCommand) Execute(game *game.Game, ps *game.Player) string {
-	currentRoom, ok := game.World.GetRoomById(ps.CurrentRoomId)
+func (cmd MoveCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidMove
 	}
@@ -40,18 +40,42 @@ func (cmd MoveCommand) Execute(game *game.Game, ps *game.Player) string {
 			return MessageDoorLocked
 		}
 
-		ps.CurrentRoomId = door.RoomId
+		if !door.Allows(g.Guilds.GuildIdOf(ps.Username)) {
+			guild, ok := g.Guilds.Name(door.Restriction.GuildId)
+			if !ok {
+				guild = "another guild"
+			}
+
+			return fmt.Sprintf(MessageDoorGuild, guild)
+		}
+
+		g.MovePlayer(ps, door.RoomId)
+		g.Events.Publish(game.PlayerLeft{Player: ps, RoomId: currentRoom.ID, ToRoomId: door.RoomId, Direction: door.Name})
 	}
 
-	builder := strings.Builder{}
-	builder.WriteString(fmt.Sprintf(MessageMoveSuccess, cmd.Choice))
-	builder.WriteString("\nYou entered the ")
+	return fmt.Sprintf(MessageMoveSuccess, cmd.Choice)
+}
 
-	currentRoom, ok = game.World.GetRoomById(ps.CurrentRoomId)
+// Arrive describes the room the player moved into.
+func (cmd MoveCommand) Arrive(g *game.Game, ps *game.Player, fromRoomId int) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return "The void..no there is a bug here"
 	}
 
+	// Arriving through the door leading back to where the player came from
+	direction := ""
+	for _, door := range currentRoom.Doors {
+		if door.RoomId == fromRoomId {
+			direction = door.Name
+			break
+		}
+	}
+
+	g.Events.Publish(game.PlayerEntered{Player: ps, RoomId: currentRoom.ID, FromRoomId: fromRoomId, Direction: direction})
+
+	builder := strings.Builder{}
+	builder.WriteString("\nYou entered the ")
 	builder.WriteString(currentRoom.GetBasicInfo())
 	builder.WriteByte('\n')
 
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageNotifyOn  = "You will be told when players join or leave the game."
+	MessageNotifyOff = "You will only be told when players in your room join or leave the game."
+)
+
+// NotifyCommand toggles game wide join and leave notices.
+type NotifyCommand struct {
+	Setting string // "on", "off" or empty to toggle
+}
+
+// Execute turns join and leave notices on or off for the player.
+func (cmd NotifyCommand) Execute(g *game.Game, ps *game.Player) string {
+	on := !ps.WantsJoinNotices()
+
+	switch cmd.Setting {
+	case "on":
+		on = true
+	case "off":
+		on = false
+	}
+
+	ps.SetJoinNotices(on)
+
+	if on {
+		return MessageNotifyOn
+	}
+
+	return MessageNotifyOff
+}
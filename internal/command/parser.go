@@ -3,7 +3,12 @@ package command
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/xealgo/muddy/internal/game"
 )
 
 type CommandParseFunc = func(input string) (Command, error)
@@ -27,16 +32,53 @@ func NewParser() *Parser {
 		{CommandMove, func(input string) (Command, error) { return p.ParseMoveCommand(input) }},
 		{CommandSay, func(input string) (Command, error) { return p.ParseSayCommand(input) }},
 		{CommandPickup, func(input string) (Command, error) { return p.ParsePickupCommand(input) }},
+		{CommandUnlock, func(input string) (Command, error) { return p.ParseUnlockCommand(input) }},
 		{CommandLook, func(input string) (Command, error) { return p.ParseLookCommand(input) }},
 		{CommandHelp, func(input string) (Command, error) { return p.ParseHelpCommand(input) }},
 		{CommandInventory, func(input string) (Command, error) { return p.ParseInventoryCommand(input) }},
 		{CommandTalk, func(input string) (Command, error) { return p.ParseTalkCommand(input) }},
 		{CommandSell, func(input string) (Command, error) { return p.ParseSellCommand(input) }},
+		{CommandNotify, func(input string) (Command, error) { return p.ParseNotifyCommand(input) }},
+		{CommandTell, func(input string) (Command, error) { return p.ParseTellCommand(input) }},
+		{CommandReply, func(input string) (Command, error) { return p.ParseReplyCommand(input) }},
+		{CommandChannel, func(input string) (Command, error) { return p.ParseChannelCommand(input) }},
+		{CommandTrade, func(input string) (Command, error) { return p.ParseTradeCommand(input) }},
+		{CommandChat, func(input string) (Command, error) { return p.ParseChatCommand(input) }},
+		{CommandEmote, func(input string) (Command, error) { return p.ParseEmoteCommand(input) }},
+		{CommandSocials, func(input string) (Command, error) { return p.ParseSocialsCommand(input) }},
+		{CommandIgnore, func(input string) (Command, error) { return p.ParseIgnoreCommand(input) }},
+		{CommandMute, func(input string) (Command, error) { return p.ParseMuteCommand(input) }},
+		{CommandReport, func(input string) (Command, error) { return p.ParseReportCommand(input) }},
+		{CommandReports, func(input string) (Command, error) { return p.ParseReportsCommand(input) }},
+		{CommandParty, func(input string) (Command, error) { return p.ParsePartyCommand(input) }},
+		{CommandGuild, func(input string) (Command, error) { return p.ParseGuildCommand(input) }},
+		{CommandDeposit, func(input string) (Command, error) { return p.ParseBankCommand(input) }},
 	}
 
 	return p
 }
 
+// Register adds a parser for a command which isn't built in, such as a social.
+// Commands are tried in the order they're registered, after the built in ones.
+func (p *Parser) Register(typ CommandType, fn CommandParseFunc) error {
+	if p.parseFuncs == nil {
+		*p = *NewParser()
+	}
+
+	for _, pf := range p.parseFuncs {
+		if pf.typ == typ {
+			return fmt.Errorf("command %s is already registered", typ)
+		}
+	}
+
+	p.parseFuncs = append(p.parseFuncs, struct {
+		typ CommandType
+		fn  CommandParseFunc
+	}{typ, fn})
+
+	return nil
+}
+
 // ParseAnyCommand parses any command from the input string.
 func (p *Parser) ParseAnyCommand(input string) (CommandType, Command, error) {
 	if p.parseFuncs == nil {
@@ -139,6 +181,30 @@ func (p Parser) ParsePickupCommand(input string) (*PickupCommand, error) {
 	return &cmd, nil
 }
 
+// ParseUnlockCommand parses an unlock command from the input string.
+func (p Parser) ParseUnlockCommand(input string) (*UnlockCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 2)
+
+	if len(parts) != 2 || parts[0] != string(CommandUnlock) {
+		return nil, fmt.Errorf("invalid unlock command format")
+	}
+
+	if len(parts[1]) > 32 {
+		return nil, fmt.Errorf("invalid door name: %s", parts[1])
+	}
+
+	cmd := UnlockCommand{
+		Door: strings.TrimSpace(parts[1]),
+	}
+
+	return &cmd, nil
+}
+
 // ParseLookCommand parses a look command from the input string.
 func (p Parser) ParseLookCommand(input string) (*LookCommand, error) {
 	if len(input) == 0 {
@@ -216,8 +282,555 @@ func (p Parser) ParseSellCommand(input string) (*SellCommand, error) {
 	return &cmd, nil
 }
 
+// ParseNotifyCommand parses a notify command from the input string.
+func (p Parser) ParseNotifyCommand(input string) (*NotifyCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.Split(input, " ")
+
+	if len(parts) > 2 || parts[0] != string(CommandNotify) {
+		return nil, fmt.Errorf("invalid notify command format")
+	}
+
+	cmd := NotifyCommand{}
+
+	if len(parts) == 2 {
+		cmd.Setting = strings.ToLower(parts[1])
+
+		if cmd.Setting != "on" && cmd.Setting != "off" {
+			return nil, fmt.Errorf("invalid notify setting")
+		}
+	}
+
+	return &cmd, nil
+}
+
+// ParseTellCommand parses a tell command from the input string.
+func (p Parser) ParseTellCommand(input string) (*TellCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 3)
+
+	if len(parts) != 3 || parts[0] != string(CommandTell) {
+		return nil, fmt.Errorf("invalid tell command format")
+	}
+
+	if len(parts[1]) > 32 {
+		return nil, fmt.Errorf("invalid player name: %s", parts[1])
+	}
+
+	if len(parts[2]) > 128 {
+		return nil, fmt.Errorf("message too long: %d characters (max 128)", len(parts[2]))
+	}
+
+	cmd := TellCommand{
+		Target:  parts[1],
+		Message: parts[2],
+	}
+
+	return &cmd, nil
+}
+
+// ParseReplyCommand parses a reply command from the input string.
+func (p Parser) ParseReplyCommand(input string) (*ReplyCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 2)
+
+	if len(parts) != 2 || parts[0] != string(CommandReply) {
+		return nil, fmt.Errorf("invalid reply command format")
+	}
+
+	if len(parts[1]) > 128 {
+		return nil, fmt.Errorf("message too long: %d characters (max 128)", len(parts[1]))
+	}
+
+	cmd := ReplyCommand{
+		Message: parts[1],
+	}
+
+	return &cmd, nil
+}
+
+// ParseChannelCommand parses a channel command from the input string.
+func (p Parser) ParseChannelCommand(input string) (*ChannelCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.ToLower(strings.TrimSpace(input)))
+	parts := strings.Split(input, " ")
+
+	if parts[0] != string(CommandChannel) && parts[0] != "channels" {
+		return nil, fmt.Errorf("invalid channel command format")
+	}
+
+	if len(parts) == 1 {
+		return &ChannelCommand{}, nil
+	}
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid channel command format")
+	}
+
+	switch parts[1] {
+	case ChannelJoin, ChannelLeave, ChannelMute, ChannelUnmute:
+	default:
+		return nil, fmt.Errorf("invalid channel action: %s", parts[1])
+	}
+
+	cmd := ChannelCommand{
+		Action:  parts[1],
+		Channel: parts[2],
+	}
+
+	return &cmd, nil
+}
+
+// ParseChatCommand parses a chat command from the input string. The channel
+// name can be used as the command, e.g. "ooc hello".
+func (p Parser) ParseChatCommand(input string) (*ChatCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 2)
+
+	if len(parts) == 2 && parts[0] == string(CommandChat) {
+		parts = strings.SplitN(parts[1], " ", 2)
+	} else if !slices.Contains(game.DefaultChannels, strings.ToLower(parts[0])) {
+		return nil, fmt.Errorf("invalid chat command format")
+	}
+
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid chat command format")
+	}
+
+	if len(parts[1]) > 128 {
+		return nil, fmt.Errorf("message too long: %d characters (max 128)", len(parts[1]))
+	}
+
+	cmd := ChatCommand{
+		Channel: strings.ToLower(parts[0]),
+		Message: parts[1],
+	}
+
+	return &cmd, nil
+}
+
+// ParseEmoteCommand parses an emote command from the input string.
+func (p Parser) ParseEmoteCommand(input string) (*EmoteCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 2)
+
+	if len(parts) != 2 || parts[0] != string(CommandEmote) {
+		return nil, fmt.Errorf("invalid emote command format")
+	}
+
+	if len(parts[1]) > 128 {
+		return nil, fmt.Errorf("emote too long: %d characters (max 128)", len(parts[1]))
+	}
+
+	cmd := EmoteCommand{
+		Text: parts[1],
+	}
+
+	return &cmd, nil
+}
+
+// ParseSocialsCommand parses a socials command from the input string.
+func (p Parser) ParseSocialsCommand(input string) (*SocialsCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.Split(input, " ")
+
+	if len(parts) != 1 || parts[0] != string(CommandSocials) {
+		return nil, fmt.Errorf("invalid socials command format")
+	}
+
+	cmd := SocialsCommand{}
+
+	return &cmd, nil
+}
+
+// ParseIgnoreCommand parses an ignore or unignore command from the input string.
+func (p Parser) ParseIgnoreCommand(input string) (*IgnoreCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.Split(input, " ")
+
+	undo := parts[0] == string(CommandUnignore)
+
+	if len(parts) > 2 || (parts[0] != string(CommandIgnore) && !undo) || (undo && len(parts) != 2) {
+		return nil, fmt.Errorf("invalid ignore command format")
+	}
+
+	cmd := IgnoreCommand{
+		Undo: undo,
+	}
+
+	if len(parts) == 2 {
+		cmd.Target = parts[1]
+	}
+
+	return &cmd, nil
+}
+
+// ParseMuteCommand parses a mute or unmute command from the input string.
+func (p Parser) ParseMuteCommand(input string) (*MuteCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.Split(input, " ")
+
+	if len(parts) == 2 && parts[0] == string(CommandUnmute) {
+		return &MuteCommand{Target: parts[1]}, nil
+	}
+
+	if len(parts) != 3 || parts[0] != string(CommandMute) {
+		return nil, fmt.Errorf("invalid mute command format")
+	}
+
+	duration, err := time.ParseDuration(parts[2])
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid mute duration: %s", parts[2])
+	}
+
+	cmd := MuteCommand{
+		Target:   parts[1],
+		Duration: duration,
+	}
+
+	return &cmd, nil
+}
+
+// ParseReportCommand parses a report command from the input string.
+func (p Parser) ParseReportCommand(input string) (*ReportCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 3)
+
+	if len(parts) != 3 || parts[0] != string(CommandReport) {
+		return nil, fmt.Errorf("invalid report command format")
+	}
+
+	if len(parts[2]) > 256 {
+		return nil, fmt.Errorf("reason too long: %d characters (max 256)", len(parts[2]))
+	}
+
+	cmd := ReportCommand{
+		Target: parts[1],
+		Reason: parts[2],
+	}
+
+	return &cmd, nil
+}
+
+// ParseReportsCommand parses a reports command from the input string.
+func (p Parser) ParseReportsCommand(input string) (*ReportsCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.Split(input, " ")
+
+	if len(parts) != 1 || parts[0] != string(CommandReports) {
+		return nil, fmt.Errorf("invalid reports command format")
+	}
+
+	cmd := ReportsCommand{}
+
+	return &cmd, nil
+}
+
+// SocialParseFunc returns a parse func for a social, which is used by its name
+// and an optional target, e.g. "hug alice".
+func SocialParseFunc(social game.Social) CommandParseFunc {
+	return func(input string) (Command, error) {
+		if len(input) == 0 {
+			return nil, fmt.Errorf("empty command")
+		}
+
+		input = replaceNewlines(strings.TrimSpace(input))
+		parts := strings.Split(input, " ")
+
+		if len(parts) > 2 || strings.ToLower(parts[0]) != social.Name {
+			return nil, fmt.Errorf("invalid %s command format", social.Name)
+		}
+
+		cmd := SocialCommand{
+			Social: social,
+		}
+
+		if len(parts) == 2 {
+			cmd.Target = parts[1]
+		}
+
+		return &cmd, nil
+	}
+}
+
 // replaceNewlines replaces newline characters with spaces in the input string.
 func replaceNewlines(input string) string {
 	re := regexp.MustCompile(`(\r\n|\r|\n)+| +`)
 	return re.ReplaceAllString(input, " ")
 }
+
+// ParsePartyCommand parses a party command from the input string.
+func (p Parser) ParsePartyCommand(input string) (*PartyCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 3)
+
+	if parts[0] != string(CommandParty) {
+		return nil, fmt.Errorf("invalid party command format")
+	}
+
+	cmd := PartyCommand{Action: PartyStatus}
+	if len(parts) == 1 {
+		return &cmd, nil
+	}
+
+	cmd.Action = strings.ToLower(parts[1])
+
+	switch cmd.Action {
+	case PartyStatus, PartyAccept, PartyLeave:
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid party %s command format", cmd.Action)
+		}
+	case PartyInvite, PartyKick:
+		if len(parts) != 3 || strings.Contains(parts[2], " ") || len(parts[2]) > 32 {
+			return nil, fmt.Errorf("invalid party %s command format", cmd.Action)
+		}
+
+		cmd.Target = parts[2]
+	case PartySplit:
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid party split command format")
+		}
+
+		cmd.Target = strings.ToLower(parts[2])
+
+		if cmd.Target != "on" && cmd.Target != "off" {
+			return nil, fmt.Errorf("invalid party split setting")
+		}
+	case PartySay:
+		if len(parts) != 3 || len(parts[2]) > 128 {
+			return nil, fmt.Errorf("invalid party say command format")
+		}
+
+		cmd.Message = parts[2]
+	default:
+		return nil, fmt.Errorf("unknown party action %s", cmd.Action)
+	}
+
+	return &cmd, nil
+}
+
+// ParseGuildCommand parses a guild command from the input string.
+func (p Parser) ParseGuildCommand(input string) (*GuildCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 3)
+
+	if parts[0] != string(CommandGuild) {
+		return nil, fmt.Errorf("invalid guild command format")
+	}
+
+	cmd := GuildCommand{Action: GuildInfo}
+	if len(parts) == 1 {
+		return &cmd, nil
+	}
+
+	cmd.Action = strings.ToLower(parts[1])
+
+	switch cmd.Action {
+	case GuildInfo, GuildAccept, GuildLeave, GuildRoster:
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid guild %s command format", cmd.Action)
+		}
+	case GuildCreate, GuildInvite, GuildKick, GuildPromote, GuildDemote, GuildDisband:
+		if len(parts) != 3 || strings.Contains(parts[2], " ") || len(parts[2]) > 32 {
+			return nil, fmt.Errorf("invalid guild %s command format", cmd.Action)
+		}
+
+		cmd.Target = parts[2]
+	case GuildRename:
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid guild rename command format")
+		}
+
+		names := strings.Split(parts[2], " ")
+		if len(names) != 2 || len(names[0]) > 32 || len(names[1]) > 32 {
+			return nil, fmt.Errorf("invalid guild rename command format")
+		}
+
+		cmd.Target, cmd.NewName = names[0], names[1]
+	case GuildSay:
+		if len(parts) != 3 || len(parts[2]) > 128 {
+			return nil, fmt.Errorf("invalid guild say command format")
+		}
+
+		cmd.Message = parts[2]
+	case GuildDeposit, GuildWithdraw:
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid guild %s command format", cmd.Action)
+		}
+
+		gold, err := parseGold(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Gold = gold
+		if gold == 0 {
+			cmd.Target = parts[2]
+		}
+	default:
+		return nil, fmt.Errorf("unknown guild action %s", cmd.Action)
+	}
+
+	return &cmd, nil
+}
+
+// ParseTradeCommand parses a trade command from the input string.
+func (p Parser) ParseTradeCommand(input string) (*TradeCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 3)
+
+	if parts[0] != string(CommandTrade) {
+		return nil, fmt.Errorf("invalid trade command format")
+	}
+
+	cmd := TradeCommand{Action: TradeStatus}
+	if len(parts) == 1 {
+		return &cmd, nil
+	}
+
+	cmd.Action = strings.ToLower(parts[1])
+
+	switch cmd.Action {
+	case TradeStatus, TradeConfirm, TradeCancel:
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid trade %s command format", cmd.Action)
+		}
+	case TradeOffer, TradeRemove:
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid trade %s command format", cmd.Action)
+		}
+
+		gold, err := parseGold(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Gold = gold
+		if gold == 0 {
+			cmd.Target = parts[2]
+		}
+	default:
+		// Anything else is the player to trade with
+		if len(parts) != 2 || len(parts[1]) > 32 {
+			return nil, fmt.Errorf("invalid trade command format")
+		}
+
+		cmd.Action, cmd.Target = TradeRequest, parts[1]
+	}
+
+	return &cmd, nil
+}
+
+// ParseBankCommand parses a deposit, withdraw or balance command from the
+// input string.
+func (p Parser) ParseBankCommand(input string) (*BankCommand, error) {
+	if len(input) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	input = replaceNewlines(strings.TrimSpace(input))
+	parts := strings.SplitN(input, " ", 2)
+
+	cmd := BankCommand{Action: parts[0]}
+
+	switch cmd.Action {
+	case string(CommandBalance):
+		if len(parts) != 1 {
+			return nil, fmt.Errorf("invalid balance command format")
+		}
+	case string(CommandDeposit), string(CommandWithdraw):
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s command format", cmd.Action)
+		}
+
+		gold, err := parseGold(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Gold = gold
+		if gold == 0 {
+			cmd.Target = parts[1]
+		}
+	default:
+		return nil, fmt.Errorf("invalid bank command format")
+	}
+
+	return &cmd, nil
+}
+
+// parseGold parses an amount of gold given as "<amount> gold". Anything else
+// is taken to be a single item id, for which 0 is returned.
+func parseGold(input string) (int, error) {
+	parts := strings.Split(input, " ")
+
+	if len(parts) == 1 {
+		return 0, nil
+	}
+
+	if len(parts) != 2 || strings.ToLower(parts[1]) != "gold" {
+		return 0, fmt.Errorf("invalid amount of gold")
+	}
+
+	amount, err := strconv.Atoi(parts[0])
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid amount of gold")
+	}
+
+	return amount, nil
+}
@@ -3,8 +3,10 @@ package command
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/internal/game"
 )
 
 func TestParseAnyCommand(t *testing.T) {
@@ -221,3 +223,426 @@ func TestSellCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestNotifyCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *NotifyCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "notify", expected: &NotifyCommand{}},
+		{input: "notify on", expected: &NotifyCommand{Setting: "on"}},
+		{input: "notify OFF", expected: &NotifyCommand{Setting: "off"}},
+		{input: "notify maybe", expected: nil, ExpectError: true},
+		{input: "notify on off", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseNotifyCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Setting, test.expected.Setting)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestTellCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *TellCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "tell bob hello there", expected: &TellCommand{Target: "bob", Message: "hello there"}},
+		{input: "tell  bob\nhi", expected: &TellCommand{Target: "bob", Message: "hi"}},
+		{input: "tell bob", expected: nil, ExpectError: true},
+		{input: "reply bob hi", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseTellCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Target, test.expected.Target)
+			assert.Equal(t, cmd.Message, test.expected.Message)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestChannelCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *ChannelCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "channel", expected: &ChannelCommand{}},
+		{input: "channels", expected: &ChannelCommand{}},
+		{input: "channel join OOC", expected: &ChannelCommand{Action: ChannelJoin, Channel: "ooc"}},
+		{input: "channel unmute market", expected: &ChannelCommand{Action: ChannelUnmute, Channel: "market"}},
+		{input: "channel join", expected: nil, ExpectError: true},
+		{input: "channel shout ooc", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseChannelCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Action, test.expected.Action)
+			assert.Equal(t, cmd.Channel, test.expected.Channel)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestChatCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *ChatCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "ooc hello everyone", expected: &ChatCommand{Channel: "ooc", Message: "hello everyone"}},
+		{input: "Market selling a sword", expected: &ChatCommand{Channel: "market", Message: "selling a sword"}},
+		{input: "chat newbie how do I move?", expected: &ChatCommand{Channel: "newbie", Message: "how do I move?"}},
+		{input: "ooc", expected: nil, ExpectError: true},
+		{input: "gossip hello", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseChatCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Channel, test.expected.Channel)
+			assert.Equal(t, cmd.Message, test.expected.Message)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestEmoteCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *EmoteCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "emote waves around", expected: &EmoteCommand{Text: "waves around"}},
+		{input: " emote  grins\n", expected: &EmoteCommand{Text: "grins"}},
+		{input: "emote", expected: nil, ExpectError: true},
+		{input: "emotes waves", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseEmoteCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Text, test.expected.Text)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestParserRegister(t *testing.T) {
+	bow := game.Social{Name: "bow", Actor: "You bow.", Others: "{actor} bows."}
+
+	p := NewParser()
+	assert.Nil(t, p.Register(CommandType(bow.Name), SocialParseFunc(bow)))
+	assert.NotNil(t, p.Register(CommandType(bow.Name), SocialParseFunc(bow)))
+	assert.NotNil(t, p.Register(CommandLook, SocialParseFunc(bow)))
+
+	typ, cmd, err := p.ParseAnyCommand("bow Alice")
+	assert.Nil(t, err)
+	assert.Equal(t, CommandType("bow"), typ)
+	assert.Equal(t, &SocialCommand{Social: bow, Target: "Alice"}, cmd)
+
+	_, _, err = p.ParseAnyCommand("bow to Alice")
+	assert.NotNil(t, err)
+}
+
+func TestMuteCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *MuteCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "mute bob 10m", expected: &MuteCommand{Target: "bob", Duration: 10 * time.Minute}},
+		{input: "mute bob 1h30m", expected: &MuteCommand{Target: "bob", Duration: 90 * time.Minute}},
+		{input: "unmute bob", expected: &MuteCommand{Target: "bob"}},
+		{input: "mute bob", expected: nil, ExpectError: true},
+		{input: "mute bob forever", expected: nil, ExpectError: true},
+		{input: "mute bob -5m", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseMuteCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Target, test.expected.Target)
+			assert.Equal(t, cmd.Duration, test.expected.Duration)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestIgnoreCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *IgnoreCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "ignore", expected: &IgnoreCommand{}},
+		{input: "ignore bob", expected: &IgnoreCommand{Target: "bob"}},
+		{input: "unignore bob", expected: &IgnoreCommand{Target: "bob", Undo: true}},
+		{input: "unignore", expected: nil, ExpectError: true},
+		{input: "ignore bob carol", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseIgnoreCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.NotNil(t, cmd)
+			assert.Equal(t, cmd.Target, test.expected.Target)
+			assert.Equal(t, cmd.Undo, test.expected.Undo)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestPartyCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *PartyCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "party", expected: &PartyCommand{Action: PartyStatus}},
+		{input: "party invite bob", expected: &PartyCommand{Action: PartyInvite, Target: "bob"}},
+		{input: "party KICK bob", expected: &PartyCommand{Action: PartyKick, Target: "bob"}},
+		{input: "party accept", expected: &PartyCommand{Action: PartyAccept}},
+		{input: "party split On", expected: &PartyCommand{Action: PartySplit, Target: "on"}},
+		{input: "party say meet at the hall", expected: &PartyCommand{Action: PartySay, Message: "meet at the hall"}},
+		{input: "party invite", expected: nil, ExpectError: true},
+		{input: "party invite bob and carol", expected: nil, ExpectError: true},
+		{input: "party leave now", expected: nil, ExpectError: true},
+		{input: "party split maybe", expected: nil, ExpectError: true},
+		{input: "party dance", expected: nil, ExpectError: true},
+		{input: "partyon", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParsePartyCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, cmd)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err, test.input)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestGuildCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *GuildCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "guild", expected: &GuildCommand{Action: GuildInfo}},
+		{input: "guild create Owls", expected: &GuildCommand{Action: GuildCreate, Target: "Owls"}},
+		{input: "guild Promote bob", expected: &GuildCommand{Action: GuildPromote, Target: "bob"}},
+		{input: "guild roster", expected: &GuildCommand{Action: GuildRoster}},
+		{input: "guild say hoot hoot", expected: &GuildCommand{Action: GuildSay, Message: "hoot hoot"}},
+		{input: "guild deposit 25 gold", expected: &GuildCommand{Action: GuildDeposit, Gold: 25}},
+		{input: "guild withdraw 101", expected: &GuildCommand{Action: GuildWithdraw, Target: "101"}},
+		{input: "guild rename Owls Ravens", expected: &GuildCommand{Action: GuildRename, Target: "Owls", NewName: "Ravens"}},
+		{input: "guild create", expected: nil, ExpectError: true},
+		{input: "guild create Night Owls", expected: nil, ExpectError: true},
+		{input: "guild deposit -5 gold", expected: nil, ExpectError: true},
+		{input: "guild deposit 5 silver", expected: nil, ExpectError: true},
+		{input: "guild rename Owls", expected: nil, ExpectError: true},
+		{input: "guild roster all", expected: nil, ExpectError: true},
+		{input: "guild fly", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseGuildCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err, test.input)
+			assert.Equal(t, test.expected, cmd)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err, test.input)
+			assert.Nil(t, cmd)
+		}
+	}
+}
+
+func TestTradeCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *TradeCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "trade", expected: &TradeCommand{Action: TradeStatus}},
+		{input: "trade bob", expected: &TradeCommand{Action: TradeRequest, Target: "bob"}},
+		{input: "trade Confirm", expected: &TradeCommand{Action: TradeConfirm}},
+		{input: "trade offer 101", expected: &TradeCommand{Action: TradeOffer, Target: "101"}},
+		{input: "trade remove 5 gold", expected: &TradeCommand{Action: TradeRemove, Gold: 5}},
+		{input: "trade offer", expected: nil, ExpectError: true},
+		{input: "trade offer 0 gold", expected: nil, ExpectError: true},
+		{input: "trade cancel now", expected: nil, ExpectError: true},
+		{input: "trade selling a sword", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseTradeCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err, test.input)
+			assert.Equal(t, test.expected, cmd)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err, test.input)
+			assert.Nil(t, cmd)
+		}
+	}
+
+	// Trading and chatting about it don't overlap, so a message is never taken
+	// for a trade and a trade is never said on a channel
+	parser := NewParser()
+
+	for input, expected := range map[string]CommandType{
+		"trade hi":              CommandTrade,
+		"trade offer 5 gold":    CommandTrade,
+		"market hi":             CommandChat,
+		"market offer 5 gold":   CommandChat,
+		"chat market hi":        CommandChat,
+		"trade selling a sword": "",
+	} {
+		typ, _, err := parser.ParseAnyCommand(input)
+		assert.Equal(t, expected, typ, input)
+		assert.Equal(t, expected == "", err != nil, input)
+	}
+}
+
+func TestBankCommand(t *testing.T) {
+	type CommandTest struct {
+		input       string
+		expected    *BankCommand
+		ExpectError bool
+	}
+
+	tests := []CommandTest{
+		{input: "balance", expected: &BankCommand{Action: "balance"}},
+		{input: "deposit 25 gold", expected: &BankCommand{Action: "deposit", Gold: 25}},
+		{input: "deposit 101", expected: &BankCommand{Action: "deposit", Target: "101"}},
+		{input: "withdraw 3 Gold", expected: &BankCommand{Action: "withdraw", Gold: 3}},
+		{input: "balance now", expected: nil, ExpectError: true},
+		{input: "deposit", expected: nil, ExpectError: true},
+		{input: "withdraw -3 gold", expected: nil, ExpectError: true},
+		{input: "withdraw all the gold", expected: nil, ExpectError: true},
+		{input: "bank 5 gold", expected: nil, ExpectError: true},
+	}
+
+	p := Parser{}
+
+	for _, test := range tests {
+		cmd, err := p.ParseBankCommand(test.input)
+
+		if test.expected != nil && test.ExpectError == false {
+			assert.Nil(t, err, test.input)
+			assert.Equal(t, test.expected, cmd)
+		}
+
+		if test.ExpectError {
+			assert.NotNil(t, err, test.input)
+			assert.Nil(t, cmd)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// Party actions
+const (
+	PartyStatus = "status"
+	PartyInvite = "invite"
+	PartyAccept = "accept"
+	PartyLeave  = "leave"
+	PartyKick   = "kick"
+	PartySay    = "say"
+	PartySplit  = "split"
+)
+
+const (
+	MessageNotInParty     = "You aren't in a party."
+	MessageNotPartyLeader = "Only the party leader can do that."
+	MessageAlreadyInParty = "%s is already in a party."
+	MessagePartyFull      = "The party is full."
+	MessageNoPartyInvite  = "Nobody has invited you to a party."
+	MessagePartySelf      = "You can't do that to yourself."
+	MessagePlayerOffline  = "%s isn't online."
+	MessagePartyFollow    = "You follow %s.\n"
+)
+
+// PartyCommand manages the player's party and talks to it.
+type PartyCommand struct {
+	Action  string
+	Target  string // Player to invite or kick, or on/off for splitting gold
+	Message string
+}
+
+// Execute runs the party action.
+func (cmd PartyCommand) Execute(g *game.Game, ps *game.Player) string {
+	switch cmd.Action {
+	case PartyInvite:
+		return cmd.invite(g, ps)
+	case PartyAccept:
+		return cmd.accept(g, ps)
+	case PartyLeave:
+		if members, _ := g.Parties.Members(ps); len(members) == 0 {
+			return MessageNotInParty
+		}
+
+		g.LeaveParty(ps)
+		return "You left the party."
+	case PartyKick:
+		return cmd.kick(g, ps)
+	case PartySay:
+		return cmd.say(g, ps)
+	case PartySplit:
+		if err := g.Parties.SetSplitGold(ps, cmd.Target == "on"); err != nil {
+			return partyError(err, ps.DisplayName)
+		}
+
+		members, _ := g.Parties.Members(ps)
+		g.TellParty(members, fmt.Sprintf("%s turned gold splitting %s.", ps.DisplayName, cmd.Target))
+		return ""
+	default:
+		return partyStatus(g, ps)
+	}
+}
+
+// invite invites the target to the player's party.
+func (cmd PartyCommand) invite(g *game.Game, ps *game.Player) string {
+	target, ok := g.Sm.FindPlayer(cmd.Target)
+	if !ok {
+		return fmt.Sprintf(MessagePlayerOffline, cmd.Target)
+	}
+
+	if target == ps {
+		return MessagePartySelf
+	}
+
+	if err := g.Parties.Invite(ps, target); err != nil {
+		return partyError(err, target.DisplayName)
+	}
+
+	g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf("%s invited you to their party, type 'party accept' to join.", ps.DisplayName))
+
+	return fmt.Sprintf("You invited %s to your party.", target.DisplayName)
+}
+
+// accept joins the party the player was invited to.
+func (cmd PartyCommand) accept(g *game.Game, ps *game.Player) string {
+	members, err := g.Parties.Accept(ps)
+	if err != nil {
+		return partyError(err, ps.DisplayName)
+	}
+
+	g.TellParty(members, fmt.Sprintf("%s joined the party.", ps.DisplayName))
+
+	return fmt.Sprintf("You joined %s's party.", members[0].DisplayName)
+}
+
+// kick removes the target from the player's party.
+func (cmd PartyCommand) kick(g *game.Game, ps *game.Player) string {
+	target, ok := g.Sm.FindPlayer(cmd.Target)
+	if !ok {
+		return fmt.Sprintf(MessagePlayerOffline, cmd.Target)
+	}
+
+	if target == ps {
+		return MessagePartySelf
+	}
+
+	members, err := g.Parties.Kick(ps, target)
+	if err != nil {
+		return partyError(err, target.DisplayName)
+	}
+
+	g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf("%s removed you from their party.", ps.DisplayName))
+	g.TellParty(members, fmt.Sprintf("%s was removed from the party.", target.DisplayName))
+
+	if len(members) == 1 {
+		g.TellParty(members, "The party broke up.")
+	}
+
+	return ""
+}
+
+// say talks to every party member, wherever they are.
+func (cmd PartyCommand) say(g *game.Game, ps *game.Player) string {
+	members, _ := g.Parties.Members(ps)
+	if len(members) == 0 {
+		return MessageNotInParty
+	}
+
+	if response, ok := allowChat(g, ps); !ok {
+		return response
+	}
+
+	message := g.Moderation.Filter(strings.TrimRight(cmd.Message, "\n"))
+
+	for _, member := range members {
+		if member != ps && g.Chat.IsIgnoring(member.Username, ps.Username) {
+			continue
+		}
+
+		g.Sm.SendToPlayer(member.GetUUID(), fmt.Sprintf("[party] %s: %s", ps.DisplayName, message))
+		member.SendOutOfBand(game.OOBCommChannel, game.ChannelData{Channel: "party", Talker: ps.DisplayName, Text: message})
+	}
+
+	return ""
+}
+
+// partyStatus lists the party members along with where they are and how
+// they're doing.
+func partyStatus(g *game.Game, ps *game.Player) string {
+	members, splitGold := g.Parties.Members(ps)
+	if len(members) == 0 {
+		return MessageNotInParty
+	}
+
+	split := "off"
+	if splitGold {
+		split = "on"
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Your party (gold splitting %s):\n", split))
+
+	for i, member := range members {
+		where := "somewhere"
+		if room, ok := g.World.GetRoomById(member.RoomId()); ok {
+			where = room.Name
+		}
+
+		builder.WriteString(fmt.Sprintf("- %s, %s, %d/%d health", member.DisplayName, where, member.Health, member.MaxHealth))
+		if i == 0 {
+			builder.WriteString(" (leader)")
+		}
+
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+// partyError returns the message for a party error.
+func partyError(err error, name string) string {
+	switch {
+	case errors.Is(err, game.ErrorNotPartyLeader):
+		return MessageNotPartyLeader
+	case errors.Is(err, game.ErrorAlreadyInParty):
+		return fmt.Sprintf(MessageAlreadyInParty, name)
+	case errors.Is(err, game.ErrorPartyFull):
+		return MessagePartyFull
+	case errors.Is(err, game.ErrorNoInvite):
+		return MessageNoPartyInvite
+	default:
+		return MessageNotInParty
+	}
+}
+
+// splitGold shares gold the player earned with the members of their party in
+// the same room, if the party splits gold. The player keeps any remainder.
+// The message describing the split is returned.
+func splitGold(g *game.Game, ps *game.Player, amount int) string {
+	members, split := g.Parties.Members(ps)
+	if !split {
+		return ""
+	}
+
+	sharers := []*game.Player{}
+	for _, member := range members {
+		if member != ps && member.RoomId() == ps.RoomId() {
+			sharers = append(sharers, member)
+		}
+	}
+
+	share := amount / (len(sharers) + 1)
+	if len(sharers) == 0 || share == 0 {
+		return ""
+	}
+
+	for _, member := range sharers {
+		if !ps.Inventory.TakeGold(share) {
+			break
+		}
+
+		member.Inventory.AddGold(share)
+		g.Events.Publish(game.GoldGiven{Player: ps, To: member, Amount: share})
+		g.Sm.SendToPlayer(member.GetUUID(), fmt.Sprintf("[party] %s shared %d gold with you.", ps.DisplayName, share))
+	}
+
+	return fmt.Sprintf("You shared %d gold with each party member here.\n", share)
+}
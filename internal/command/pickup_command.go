@@ -14,8 +14,8 @@ type PickupCommand struct {
 }
 
 // Execute allows the player to pick up an item from the current room.
-func (cmd PickupCommand) Execute(game *game.Game, ps *game.Player) string {
-	currentRoom, ok := game.World.GetRoomById(ps.CurrentRoomId)
+func (cmd PickupCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidCmd
 	}
@@ -25,7 +25,7 @@ func (cmd PickupCommand) Execute(game *game.Game, ps *game.Player) string {
 		return MessageItemNotFound
 	}
 
-	ps.Inventory.Add(item)
+	g.Events.Publish(game.ItemPickedUp{Player: ps, RoomId: currentRoom.ID, Item: ps.Inventory.Add(item)})
 
 	return "You picked up the " + item.Name + "."
 }
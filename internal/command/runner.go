@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/xealgo/muddy/internal/game"
 )
@@ -14,22 +15,95 @@ type Runner struct {
 
 // NewRunner creates a new command runner.
 func NewRunner(game *game.Game) *Runner {
+	parser := NewParser()
+
+	for _, social := range game.Socials {
+		if err := parser.Register(CommandType(social.Name), SocialParseFunc(social)); err != nil {
+			slog.Warn("Skipping social", "social", social.Name, "error", err)
+		}
+	}
+
 	return &Runner{
 		game:   game,
-		parser: NewParser(),
+		parser: parser,
 	}
 }
 
 // Execute processes and executes commands based on input from the players.
-func (r Runner) Execute(ps *game.Player, input string) string {
+// An error is returned if the input isn't a valid command.
+func (r Runner) Execute(ps *game.Player, input string) (string, error) {
+	ps.Touch()
+
 	if len(input) == 0 || input == "\n" || input == "\r" {
-		return ""
+		return "", nil
 	}
 
 	_, cmd, err := r.parser.ParseAnyCommand(input)
 	if err != nil {
-		return fmt.Sprintln(err.Error())
+		return "", err
+	}
+
+	from := ps.RoomId()
+	response := r.run(ps, cmd)
+
+	// Party members in the room the leader left follow them through the door.
+	if _, ok := cmd.(Handoff); ok && ps.RoomId() != from {
+		for _, follower := range r.game.Parties.Followers(ps) {
+			if follower.RoomId() != from {
+				continue
+			}
+
+			message := fmt.Sprintf(MessagePartyFollow, ps.DisplayName) + r.run(follower, cmd)
+			if err := follower.WriteString(message); err != nil {
+				slog.Error("failed to send to player", "player", follower.DisplayName, "error", err)
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// run executes a parsed command for the player.
+func (r Runner) run(ps *game.Player, cmd Command) string {
+	from := ps.RoomId()
+	response := r.runInRoom(ps, func() string { return cmd.Execute(r.game, ps) })
+
+	// Moving hands the player off to their new room, which finishes the command.
+	if handoff, ok := cmd.(Handoff); ok && ps.RoomId() != from {
+		response += r.runInRoom(ps, func() string { return handoff.Arrive(r.game, ps, from) })
 	}
 
-	return cmd.Execute(r.game, ps)
+	// Offers can't stand for anything the command took out of the inventory.
+	r.game.CheckTrade(ps)
+
+	// Push any state changes caused by the command to out-of-band subscribers.
+	r.game.SyncOutOfBand(ps)
+
+	return response
+}
+
+// runInRoom runs fn on the goroutine of the player's room, so it never races
+// with commands from other players in the same room.
+func (r Runner) runInRoom(ps *game.Player, fn func() string) string {
+	for {
+		roomId := ps.RoomId()
+
+		response, ran := "", false
+		exists := r.game.World.Do(roomId, func(room *game.Room) {
+			// The player may have moved while waiting for the room
+			if ps.RoomId() != roomId {
+				return
+			}
+
+			response, ran = fn(), true
+		})
+
+		if !exists {
+			return fn()
+		}
+
+		if ran {
+			return response
+		}
+	}
 }
@@ -0,0 +1,862 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/internal/event"
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// testConnection is a thread safe in-memory connection.
+type testConnection struct {
+	messages []string
+	mutex    sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newTestConnection() *testConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &testConnection{ctx: ctx, cancel: cancel}
+}
+
+func (c *testConnection) WriteMessage(typ game.MessageType, message []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.messages = append(c.messages, string(message))
+	return nil
+}
+
+func (c *testConnection) Close(reason string) error {
+	c.cancel()
+	return nil
+}
+
+func (c *testConnection) RemoteAddr() string {
+	return "test"
+}
+
+func (c *testConnection) Context() context.Context {
+	return c.ctx
+}
+
+// takeMessages returns and clears the messages sent to a player.
+func takeMessages(ps *game.Player) []string {
+	conn := ps.GetConnection().(*testConnection)
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	messages := conn.messages
+	conn.messages = nil
+
+	return messages
+}
+
+// received returns and clears the text sent to a player, skipping events such
+// as players moving.
+func received(ps *game.Player) string {
+	texts := []string{}
+	for _, message := range takeMessages(ps) {
+		if e, err := event.Unmarshal([]byte(message)); err != nil || e.Type == "" {
+			texts = append(texts, message)
+		}
+	}
+
+	return strings.Join(texts, "")
+}
+
+// newTestGame loads the test world and connects the given number of players.
+func newTestGame(t *testing.T, count int) (*game.Game, []*game.Player) {
+	world := game.NewWorld()
+	assert.Nil(t, world.LoadRoomsFromYaml("testdata/world.yml"))
+
+	g := game.NewGame(world)
+	g.Sm = game.NewSessionManager(count)
+	event.NewEventDispatcher(g.Sm, g.Chat).Subscribe(g.Events)
+	g.Sm.OnRemove(g.LeaveParty)
+	g.Sm.OnRemove(g.CancelTrade)
+	g.Sm.OnDetach(g.CancelTrade)
+
+	players := []*game.Player{}
+	for i := range count {
+		player := game.NewPlayer(fmt.Sprintf("player%d", i), fmt.Sprintf("Player%d", i))
+		assert.Nil(t, g.Sm.Register(player))
+
+		_, err := g.Sm.Connect(player.GetUUID(), newTestConnection())
+		assert.Nil(t, err)
+
+		players = append(players, player)
+	}
+
+	return g, players
+}
+
+func TestRunnerExecute(t *testing.T) {
+	g, players := newTestGame(t, 1)
+	alice := players[0]
+	runner := NewRunner(g)
+
+	events := []string{}
+	g.Events.SubscribeAll(func(e game.DomainEvent) {
+		events = append(events, e.EventName())
+	})
+
+	type RunnerTest struct {
+		input    string
+		expected string
+		roomId   int
+	}
+
+	tests := []RunnerTest{
+		{input: "move west", expected: MessageInvalidMove, roomId: 1},
+		{input: "move north", expected: "You move to the north\nYou entered the Library, Shelves of dusty books.\n", roomId: 2},
+		{input: "pickup book", expected: "You picked up the Book.", roomId: 2},
+		{input: "pickup book", expected: MessageItemNotFound, roomId: 2},
+		{input: "move south", expected: "You move to the south\nYou entered the Hall, A long hall.\n", roomId: 1},
+		{input: "sell Henry 101", expected: "You sold the item Book to Henry for $$3.\n", roomId: 1},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(alice, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+		assert.Equal(t, test.roomId, alice.RoomId(), test.input)
+	}
+
+	assert.Equal(t, 3, alice.Inventory.GetGold())
+	assert.Equal(t, []string{
+		game.EventPlayerLeft, game.EventPlayerEntered, game.EventItemPickedUp,
+		game.EventPlayerLeft, game.EventPlayerEntered, game.EventItemSold,
+	}, events)
+}
+
+func TestRunnerUnlock(t *testing.T) {
+	g, players := newTestGame(t, 1)
+	alice := players[0]
+	runner := NewRunner(g)
+
+	unlocked := []game.DoorUnlocked{}
+	game.Subscribe(g.Events, func(e game.DoorUnlocked) {
+		unlocked = append(unlocked, e)
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+		roomId   int
+	}{
+		{input: "unlock west", expected: MessageNoSuchDoor, roomId: 1},
+		{input: "unlock north", expected: "The north door isn't locked.", roomId: 1},
+		{input: "unlock east", expected: "You don't have the key to the east door.", roomId: 1},
+		{input: "move east", expected: MessageDoorLocked, roomId: 1},
+		{input: "pickup key", expected: "You picked up the Key.", roomId: 1},
+		{input: "unlock East", expected: "You unlock the east door.", roomId: 1},
+		{input: "unlock east", expected: "The east door isn't locked.", roomId: 1},
+		{input: "move east", expected: "You move to the east\nYou entered the Cellar, A damp cellar.\n", roomId: 3},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(alice, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+		assert.Equal(t, test.roomId, alice.RoomId(), test.input)
+	}
+
+	if assert.Len(t, unlocked, 1) {
+		assert.Equal(t, alice, unlocked[0].Player)
+		assert.Equal(t, 1, unlocked[0].RoomId)
+		assert.Equal(t, "east", unlocked[0].Door.Name)
+		assert.False(t, unlocked[0].Door.IsLocked)
+	}
+}
+
+func TestRunnerConcurrentPlayers(t *testing.T) {
+	g, players := newTestGame(t, 16)
+	runner := NewRunner(g)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	worldGroup := sync.WaitGroup{}
+	worldGroup.Add(1)
+	go g.World.Start(ctx, &worldGroup)
+
+	inputs := []string{
+		"pickup coin", "pickup key", "look", "say hello", "inventory", "sell Henry 101",
+		"move north", "pickup book", "pickup quill", "look", "say hello", "move south",
+		"sell Henry 101", "sell Henry 102",
+	}
+
+	wg := sync.WaitGroup{}
+	for _, player := range players {
+		wg.Add(1)
+		go func(player *game.Player) {
+			defer wg.Done()
+
+			for range 10 {
+				for _, input := range inputs {
+					_, err := runner.Execute(player, input)
+					assert.Nil(t, err, input)
+				}
+			}
+		}(player)
+	}
+
+	wg.Wait()
+	cancel()
+	worldGroup.Wait()
+
+	// Every item was picked up exactly once and nothing was duplicated
+	items := map[string]int{}
+	for _, roomId := range []int{1, 2} {
+		room, ok := g.World.GetRoomById(roomId)
+		assert.True(t, ok)
+
+		for _, item := range room.GetItems() {
+			items[item.Name]++
+		}
+	}
+
+	gold := 0
+	for _, player := range players {
+		gold += player.Inventory.GetGold()
+
+		for _, item := range player.Inventory.GetItems() {
+			items[item.Name]++
+		}
+
+		assert.Equal(t, 1, player.RoomId())
+	}
+
+	room, _ := g.World.GetRoomById(1)
+	npc, ok := room.GetNpcByName("Henry")
+	assert.True(t, ok)
+
+	for _, item := range npc.(*game.Merchant).Inventory.GetItems() {
+		items[item.Name]++
+	}
+
+	assert.Equal(t, map[string]int{"Coin": 1, "Key": 1, "Book": 1, "Quill": 1}, items)
+
+	soldGold := 0
+	for _, item := range npc.(*game.Merchant).Inventory.GetItems() {
+		soldGold += item.SellingPrice
+	}
+	assert.Equal(t, soldGold, gold)
+}
+
+func TestRunnerMovementNotices(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	g.Sm.SetEvents(g.Events)
+
+	alice, bob, carol := players[0], players[1], players[2]
+	runner := NewRunner(g)
+
+	// events returns the movement events a player has been sent since the last call.
+	events := func(ps *game.Player) []string {
+		texts := []string{}
+		for _, message := range takeMessages(ps) {
+			if e, err := event.Unmarshal([]byte(message)); err == nil && e.Text() != "" {
+				texts = append(texts, e.Text())
+			}
+		}
+
+		return texts
+	}
+
+	_, err := runner.Execute(carol, "move north")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Player2 leaves north."}, events(alice))
+	assert.Equal(t, []string{"Player2 leaves north."}, events(bob))
+
+	_, err = runner.Execute(alice, "move north")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Player0 leaves north."}, events(bob))
+	assert.Equal(t, []string{"Player0 arrives from the south."}, events(carol))
+
+	response, err := runner.Execute(carol, "notify off")
+	assert.Nil(t, err)
+	assert.Equal(t, MessageNotifyOff, response)
+
+	// Only Alice still wants to hear about players outside her room
+	g.Sm.Leave(bob.GetUUID())
+	assert.Equal(t, []string{"Player1 has left the game."}, events(alice))
+	assert.Empty(t, events(carol))
+
+	_, err = runner.Execute(alice, "move south")
+	assert.Nil(t, err)
+	events(carol)
+
+	response, err = runner.Execute(carol, "notify")
+	assert.Nil(t, err)
+	assert.Equal(t, MessageNotifyOn, response)
+
+	g.Sm.Leave(alice.GetUUID())
+	assert.Equal(t, []string{"Player0 has left the game."}, events(carol))
+
+	g.GreetPlayer(alice)
+	assert.Equal(t, []string{"Player0 has joined the game."}, events(carol))
+}
+
+func TestRunnerChat(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, carol := players[0], players[1], players[2]
+	runner := NewRunner(g)
+
+	// Tells are only kept for players who have an account
+	assert.Nil(t, g.Accounts.SetPassword("dave", "secret1"))
+	assert.Nil(t, g.Accounts.SetPassword("frank", "secret1"))
+
+	type RunnerTest struct {
+		ps       *game.Player
+		input    string
+		expected string
+		received map[*game.Player][]string
+	}
+
+	tests := []RunnerTest{
+		{ps: alice, input: "reply hi", expected: MessageNoReply},
+		{ps: alice, input: "tell player0 hi", expected: MessageTellSelf},
+		{ps: alice, input: "tell Player1 hi bob", expected: "You tell Player1: hi bob", received: map[*game.Player][]string{
+			bob: {"Player0 tells you: hi bob\n"},
+		}},
+		{ps: bob, input: "reply hi alice", expected: "You tell Player0: hi alice", received: map[*game.Player][]string{
+			alice: {"Player1 tells you: hi alice\n"},
+		}},
+		{ps: alice, input: "tell dave are you there?", expected: fmt.Sprintf(MessageTellOffline, "dave")},
+		{ps: carol, input: "tell nobody are you there?", expected: fmt.Sprintf(MessageNoAccount, "nobody")},
+		{ps: alice, input: "ooc hello", expected: fmt.Sprintf(MessageNotInChannel, "ooc")},
+		{ps: alice, input: "channel join ooc", expected: "You joined the ooc channel.\n"},
+		{ps: bob, input: "channel join ooc", expected: "You joined the ooc channel.\n"},
+		{ps: alice, input: "ooc hello", expected: "", received: map[*game.Player][]string{
+			alice: {"[ooc] Player0: hello\n"},
+			bob:   {"[ooc] Player0: hello\n"},
+		}},
+		{ps: bob, input: "channel mute ooc", expected: "You muted the ooc channel."},
+		{ps: alice, input: "ooc anyone?", expected: "", received: map[*game.Player][]string{
+			alice: {"[ooc] Player0: anyone?\n"},
+		}},
+		{ps: carol, input: "channel join ooc", expected: "You joined the ooc channel.\n[ooc] Player0: hello\n[ooc] Player0: anyone?\n"},
+		{ps: bob, input: "channel", expected: "Channels:\n- market\n- newbie\n- ooc (muted)\n"},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+
+		for _, ps := range players {
+			assert.Equal(t, test.received[ps], takeMessages(ps), test.input)
+		}
+	}
+
+	// Tells sent while offline are delivered on login
+	g.Sm.Leave(carol.GetUUID())
+
+	dave := game.NewPlayer("dave", "Dave")
+	assert.Nil(t, g.Sm.Register(dave))
+	_, err := g.Sm.Connect(dave.GetUUID(), newTestConnection())
+	assert.Nil(t, err)
+
+	g.GreetPlayer(dave)
+	assert.Contains(t, strings.Join(takeMessages(dave), ""), "While you were away:\n")
+
+	response, err := runner.Execute(dave, "reply yes")
+	assert.Nil(t, err)
+	assert.Equal(t, "You tell Player0: yes", response)
+
+	// Replies go to the sender's username, not their display name
+	g.Sm.Leave(alice.GetUUID())
+	bob.DisplayName = "Sir Bob"
+
+	response, err = runner.Execute(bob, "tell frank see you later")
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf(MessageTellOffline, "frank"), response)
+
+	frank := game.NewPlayer("frank", "Frank")
+	assert.Nil(t, g.Sm.Register(frank))
+	_, err = g.Sm.Connect(frank.GetUUID(), newTestConnection())
+	assert.Nil(t, err)
+
+	g.GreetPlayer(frank)
+	takeMessages(frank)
+
+	response, err = runner.Execute(frank, "reply bye")
+	assert.Nil(t, err)
+	assert.Equal(t, "You tell Sir Bob: bye", response)
+}
+
+func TestRunnerEmotes(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, carol := players[0], players[1], players[2]
+
+	socials, err := game.LoadSocialsFromYaml("testdata/socials.yml")
+	assert.Nil(t, err)
+	g.Socials = socials
+
+	runner := NewRunner(g)
+
+	// emotes returns the emotes a player has been shown since the last call.
+	emotes := func(ps *game.Player) []string {
+		texts := []string{}
+		for _, message := range takeMessages(ps) {
+			if e, err := event.Unmarshal([]byte(message)); err == nil && e.Type == event.RoomEmote {
+				texts = append(texts, e.Text())
+			}
+		}
+
+		return texts
+	}
+
+	type RunnerTest struct {
+		input    string
+		expected string
+		bob      []string
+		carol    []string
+	}
+
+	tests := []RunnerTest{
+		{input: "emote waves around", expected: "Player0 waves around", bob: []string{"Player0 waves around"}, carol: []string{"Player0 waves around"}},
+		{input: "smile", expected: "You smile.", bob: []string{"Player0 smiles."}, carol: []string{"Player0 smiles."}},
+		{input: "smile player1", expected: "You smile at Player1.", bob: []string{"Player0 smiles at you."}, carol: []string{"Player0 smiles at Player1."}},
+		{input: "HUG henry", expected: "You hug Henry.", bob: []string{"Player0 hugs Henry."}, carol: []string{"Player0 hugs Henry."}},
+		{input: "hug", expected: fmt.Sprintf(MessageSocialTarget, "hug")},
+		{input: "hug dave", expected: fmt.Sprintf(MessageTargetNotFound, "dave")},
+		{input: "hug player0", expected: fmt.Sprintf(MessageSocialNoTarget, "hug")},
+		{input: "shrug player1", expected: fmt.Sprintf(MessageSocialNoTarget, "shrug")},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(alice, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+
+		assert.Empty(t, emotes(alice), test.input)
+		assert.Equal(t, test.bob, nilIfEmpty(emotes(bob)), test.input)
+		assert.Equal(t, test.carol, nilIfEmpty(emotes(carol)), test.input)
+	}
+
+	// Socials are only registered with runners created after they're loaded
+	response, err := runner.Execute(alice, "socials")
+	assert.Nil(t, err)
+	assert.Equal(t, "The following socials are available\n- smile [target]\n- hug <target>\n- shrug\n", response)
+
+	_, err = NewRunner(game.NewGame(g.World)).Execute(alice, "smile")
+	assert.NotNil(t, err)
+}
+
+// nilIfEmpty returns nil for empty slices, so they compare equal to unset fields.
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+func TestRunnerModeration(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, mod := players[0], players[1], players[2]
+	mod.Role = game.RoleModerator
+
+	g.Moderation.SetFilter([]string{"darn"})
+	runner := NewRunner(g)
+
+	// chat returns the chat a player has been sent since the last call.
+	chat := func(ps *game.Player) []string {
+		texts := []string{}
+		for _, message := range takeMessages(ps) {
+			if e, err := event.Unmarshal([]byte(message)); err == nil && e.Type != "" {
+				texts = append(texts, e.Text())
+			} else {
+				texts = append(texts, message)
+			}
+		}
+
+		return nilIfEmpty(texts)
+	}
+
+	type RunnerTest struct {
+		ps       *game.Player
+		input    string
+		expected string
+		alice    []string
+		bob      []string
+		mod      []string
+	}
+
+	tests := []RunnerTest{
+		{ps: bob, input: "say darn it", bob: []string{"Player1: **** it"}, alice: []string{"Player1: **** it"}, mod: []string{"Player1: **** it"}},
+		{ps: alice, input: "ignore player1", expected: "You are now ignoring player1."},
+		{ps: alice, input: "ignore", expected: "You are ignoring: player1"},
+		{ps: bob, input: "say hello?", bob: []string{"Player1: hello?"}, mod: []string{"Player1: hello?"}},
+		{ps: bob, input: "emote waves", expected: "Player1 waves", mod: []string{"Player1 waves"}},
+		{ps: bob, input: "tell player0 hi", expected: fmt.Sprintf(MessageIgnoringYou, "player0")},
+		{ps: alice, input: "unignore Player1", expected: "You are no longer ignoring Player1."},
+		{ps: alice, input: "mute player1 10m", expected: MessageInvalidCmd},
+		{ps: mod, input: "mute player1 10m", expected: fmt.Sprintf(MessagePlayerMuted, "player1", "10m0s"), bob: []string{fmt.Sprintf(MessageMutedByStaff, "10m0s") + "\n"}},
+		{ps: bob, input: "say let me talk", expected: fmt.Sprintf(MessageMuted, "10m0s")},
+		{ps: bob, input: "report player0 spam", expected: fmt.Sprintf(MessageMuted, "10m0s")},
+		{ps: alice, input: "report player1 rude", expected: MessageReportSent, mod: []string{"[report] player0 reported player1: rude\n"}},
+		{ps: mod, input: "unmute player1", expected: fmt.Sprintf(MessagePlayerUnmuted, "player1"), bob: []string{MessageUnmutedByStaff + "\n"}},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+
+		assert.Equal(t, test.alice, chat(alice), test.input)
+		assert.Equal(t, test.bob, chat(bob), test.input)
+		assert.Equal(t, test.mod, chat(mod), test.input)
+	}
+
+	response, err := runner.Execute(mod, "reports")
+	assert.Nil(t, err)
+	assert.Contains(t, response, "player0 reported player1 in room 1: rude\n")
+	assert.Contains(t, response, "Player1: **** it\n")
+	assert.Contains(t, response, "Player1: Player1 waves\n")
+}
+
+func TestRunnerParty(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, carol := players[0], players[1], players[2]
+	runner := NewRunner(g)
+
+	type RunnerTest struct {
+		ps       *game.Player
+		input    string
+		expected string
+		alice    string
+		bob      string
+	}
+
+	tests := []RunnerTest{
+		{ps: carol, input: "party", expected: MessageNotInParty},
+		{ps: alice, input: "party invite dave", expected: fmt.Sprintf(MessagePlayerOffline, "dave")},
+		{ps: alice, input: "party invite player0", expected: MessagePartySelf},
+		{ps: bob, input: "party accept", expected: MessageNoPartyInvite},
+		{ps: alice, input: "party invite player1", expected: "You invited Player1 to your party.", bob: "Player0 invited you to their party, type 'party accept' to join.\n"},
+		{ps: bob, input: "party accept", expected: "You joined Player0's party.", alice: "[party] Player1 joined the party.\n"},
+		{ps: bob, input: "party split on", expected: MessageNotPartyLeader},
+		{ps: alice, input: "party split on", alice: "[party] Player0 turned gold splitting on.\n", bob: "[party] Player0 turned gold splitting on.\n"},
+		{ps: bob, input: "party say hi all", alice: "[party] Player1: hi all\n", bob: "[party] Player1: hi all\n"},
+		{ps: carol, input: "party say hi", expected: MessageNotInParty},
+		{ps: alice, input: "pickup key", expected: "You picked up the Key."},
+		{ps: alice, input: "sell Henry 101", expected: "You sold the item Key to Henry for $$2.\nYou shared 1 gold with each party member here.\n", bob: "[party] Player0 shared 1 gold with you.\n"},
+		{ps: alice, input: "move north", expected: "You move to the north\nYou entered the Library", bob: "You follow Player0.\nYou move to the north\nYou entered the Library"},
+		{ps: bob, input: "party", expected: "Your party (gold splitting on):\n- Player0, Library, 100/100 health (leader)\n- Player1, Library, 100/100 health\n"},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.True(t, strings.HasPrefix(response, test.expected), "%s: %q", test.input, response)
+
+		assert.True(t, strings.HasPrefix(received(alice), test.alice), test.input)
+		assert.True(t, strings.HasPrefix(received(bob), test.bob), test.input)
+		takeMessages(carol)
+	}
+
+	assert.Equal(t, 1, alice.Inventory.GetGold())
+	assert.Equal(t, 1, bob.Inventory.GetGold())
+	assert.Equal(t, 2, bob.RoomId())
+	assert.Equal(t, 1, carol.RoomId())
+
+	// Leaving the game breaks up the party
+	g.Sm.Leave(alice.GetUUID())
+	assert.True(t, strings.HasPrefix(received(bob), "[party] Player0 left the party.\n[party] The party broke up.\n"))
+
+	members, _ := g.Parties.Members(bob)
+	assert.Empty(t, members)
+}
+
+func TestRunnerGuild(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, admin := players[0], players[1], players[2]
+	admin.Role = game.RoleAdmin
+	runner := NewRunner(g)
+
+	// The way north leads to the hall of the first guild founded
+	hall, _ := g.World.GetRoomById(1)
+	hall.Doors[0].Restriction = &game.DoorRestriction{GuildId: 1}
+
+	type RunnerTest struct {
+		ps       *game.Player
+		input    string
+		expected string
+		alice    []string
+		bob      []string
+	}
+
+	tests := []RunnerTest{
+		{ps: alice, input: "guild", expected: MessageNotInGuild},
+		{ps: alice, input: "move north", expected: fmt.Sprintf(MessageDoorGuild, "another guild")},
+		{ps: alice, input: "guild create Owls", expected: "You founded the guild Owls."},
+		{ps: alice, input: "guild invite player1", expected: "You invited Player1 to join the guild.", bob: []string{"Player0 invited you to join the guild Owls, type 'guild accept' to join.\n"}},
+		{ps: bob, input: "guild accept", alice: []string{"[guild] Player1 joined the guild.\n"}, bob: []string{"[guild] Player1 joined the guild.\n"}},
+		{ps: bob, input: "guild say hoot", alice: []string{"[guild] Player1: hoot\n"}, bob: []string{"[guild] Player1: hoot\n"}},
+		{ps: bob, input: "guild kick player0", expected: MessageGuildRank},
+		{ps: bob, input: "pickup coin", expected: "You picked up the Coin."},
+		{ps: bob, input: "guild deposit 101", expected: "You deposited the Coin in the guild bank."},
+		{ps: bob, input: "guild deposit 5 gold", expected: MessageNotEnoughGold},
+		{ps: bob, input: "guild withdraw 101", expected: MessageGuildRank},
+		{ps: alice, input: "guild withdraw 101", expected: "You withdrew the Coin from the guild bank."},
+		{ps: alice, input: "guild", expected: "You are a leader of the guild Owls (ID: 1).\nThe guild bank holds 0 gold.\n"},
+		{ps: alice, input: "guild roster", expected: "Members of Owls:\n- player0, leader (online)\n- player1, member (online)\n"},
+		{ps: alice, input: "guild disband Owls", expected: MessageInvalidCmd},
+		{ps: admin, input: "guild rename Owls Ravens", expected: "You renamed the guild Owls to Ravens.", alice: []string{"[guild] The guild was renamed to Ravens by an admin.\n"}, bob: []string{"[guild] The guild was renamed to Ravens by an admin.\n"}},
+		{ps: admin, input: "guild disband ravens", expected: "You disbanded the guild ravens.", alice: []string{"[guild] The guild ravens was disbanded by an admin.\n"}, bob: []string{"[guild] The guild ravens was disbanded by an admin.\n"}},
+		{ps: bob, input: "guild", expected: MessageNotInGuild},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, response, test.input)
+
+		assert.Equal(t, test.alice, takeMessages(alice), test.input)
+		assert.Equal(t, test.bob, takeMessages(bob), test.input)
+	}
+
+	assert.Len(t, alice.Inventory.GetItems(), 1)
+	assert.Empty(t, bob.Inventory.GetItems())
+}
+
+func TestRunnerGuildHall(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, admin := players[0], players[1], players[2]
+	admin.Role = game.RoleAdmin
+	runner := NewRunner(g)
+
+	hall, _ := g.World.GetRoomById(1)
+	hall.Doors[0].Restriction = &game.DoorRestriction{GuildId: 1}
+
+	for _, input := range []string{"guild create Owls", "guild disband Owls"} {
+		_, err := runner.Execute(admin, input)
+		assert.Nil(t, err, input)
+	}
+
+	// A new guild with the old guild's name doesn't get its hall
+	_, err := runner.Execute(bob, "guild create Owls")
+	assert.Nil(t, err)
+
+	response, err := runner.Execute(bob, "move north")
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf(MessageDoorGuild, "another guild"), response)
+
+	// The hall follows its guild when it's renamed
+	hall.Doors[0].Restriction.GuildId = 2
+
+	_, err = runner.Execute(admin, "guild rename Owls Ravens")
+	assert.Nil(t, err)
+
+	response, err = runner.Execute(alice, "move north")
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf(MessageDoorGuild, "Ravens"), response)
+
+	response, err = runner.Execute(bob, "move north")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(response, fmt.Sprintf(MessageMoveSuccess, "north")), response)
+	assert.Equal(t, 2, bob.RoomId())
+}
+
+func TestRunnerTrade(t *testing.T) {
+	g, players := newTestGame(t, 3)
+	alice, bob, carol := players[0], players[1], players[2]
+	runner := NewRunner(g)
+
+	bob.Inventory.AddGold(3)
+
+	started := "You are now trading with %s. Use trade offer, trade remove and trade confirm."
+
+	type RunnerTest struct {
+		ps       *game.Player
+		input    string
+		expected string
+		alice    string
+		bob      string
+	}
+
+	tests := []RunnerTest{
+		{ps: alice, input: "trade", expected: MessageNotTrading},
+		{ps: alice, input: "trade player0", expected: MessageTradeSelf},
+		{ps: alice, input: "trade dave", expected: fmt.Sprintf(MessagePlayerOffline, "dave")},
+		{ps: alice, input: "trade player1", expected: "You asked Player1 to trade.", bob: "[trade] Player0 wants to trade with you, type 'trade player0' to start.\n"},
+		{ps: bob, input: "trade player0", expected: fmt.Sprintf(started, "Player0"), alice: "[trade] " + fmt.Sprintf(started, "Player1") + "\n"},
+		{ps: alice, input: "pickup coin", expected: "You picked up the Coin."},
+		{ps: alice, input: "trade offer 102", expected: MessageNoSuchItem},
+		{ps: alice, input: "trade offer 101", expected: "You offered Coin (ID: 101), any confirmations were taken back.", bob: "[trade] Player0 offered Coin (ID: 101), any confirmations were taken back.\n"},
+		{ps: alice, input: "trade offer 101", expected: MessageAlreadyOffered},
+		{ps: bob, input: "trade offer 4 gold", expected: MessageNotEnoughGold},
+		{ps: bob, input: "trade offer 3 gold", expected: "You offered 3 gold, any confirmations were taken back.", alice: "[trade] Player1 offered 3 gold, any confirmations were taken back.\n"},
+		{ps: alice, input: "trade confirm", expected: "You confirmed the trade, waiting for Player1 to confirm.", bob: "[trade] Player0 confirmed the trade.\n"},
+		{ps: bob, input: "trade remove 1 gold", expected: "You took back 1 gold, any confirmations were taken back.", alice: "[trade] Player1 took back 1 gold, any confirmations were taken back.\n"},
+		{ps: bob, input: "trade", expected: "Trading with Player0:\nYou offer: 2 gold\nPlayer0 offers: Coin (ID: 101)\n"},
+		{ps: bob, input: "trade confirm", expected: "You confirmed the trade, waiting for Player0 to confirm.", alice: "[trade] Player1 confirmed the trade.\n"},
+		{ps: alice, input: "trade", expected: "Trading with Player1:\nYou offer: Coin (ID: 101)\nPlayer1 offers: 2 gold (confirmed)\n"},
+		{ps: alice, input: "trade confirm", expected: "Your trade with Player1 is complete.", bob: "[trade] Your trade with Player0 is complete.\n"},
+		{ps: alice, input: "trade confirm", expected: MessageNotTrading},
+
+		// Moving away cancels the trade
+		{ps: alice, input: "trade player1", expected: "You asked Player1 to trade.", bob: "[trade] Player0 wants to trade with you, type 'trade player0' to start.\n"},
+		{ps: bob, input: "trade player0", expected: fmt.Sprintf(started, "Player0"), alice: "[trade] " + fmt.Sprintf(started, "Player1") + "\n"},
+		{ps: bob, input: "move north", expected: "You move to the north", alice: "[trade] The trade between Player1 and Player0 was cancelled.\n", bob: "[trade] The trade between Player1 and Player0 was cancelled.\n"},
+		{ps: alice, input: "trade player1", expected: fmt.Sprintf(MessageTradeNotHere, "Player1")},
+		{ps: alice, input: "trade cancel", expected: MessageNotTrading},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.True(t, strings.HasPrefix(response, test.expected), "%s: %q", test.input, response)
+
+		assert.Equal(t, test.alice, received(alice), test.input)
+		assert.Equal(t, test.bob, received(bob), test.input)
+	}
+
+	assert.Equal(t, 2, alice.Inventory.GetGold())
+	assert.Equal(t, 1, bob.Inventory.GetGold())
+	assert.Empty(t, alice.Inventory.GetItems())
+	assert.Len(t, bob.Inventory.GetItems(), 1)
+
+	// Leaving the game cancels the trade
+	_, err := runner.Execute(carol, "trade player0")
+	assert.Nil(t, err)
+	_, err = runner.Execute(alice, "trade player2")
+	assert.Nil(t, err)
+	takeMessages(alice)
+
+	g.Sm.Leave(carol.GetUUID())
+	assert.True(t, strings.HasPrefix(received(alice), "[trade] The trade between Player2 and Player0 was cancelled.\n"))
+
+	_, _, _, ok := g.Trades.Status(alice)
+	assert.False(t, ok)
+
+	// So does disconnecting, without waiting for the chance to resume to run out
+	g.Sm.SetResumeGracePeriod(time.Minute)
+
+	_, err = runner.Execute(bob, "move south")
+	assert.Nil(t, err)
+	_, err = runner.Execute(bob, "trade player0")
+	assert.Nil(t, err)
+	_, err = runner.Execute(alice, "trade player1")
+	assert.Nil(t, err)
+	takeMessages(alice)
+
+	assert.True(t, g.Sm.Detach(bob.GetUUID(), bob.GetConnection()))
+	assert.True(t, strings.HasPrefix(received(alice), "[trade] The trade between Player1 and Player0 was cancelled.\n"))
+
+	_, err = runner.Execute(alice, "trade confirm")
+	assert.Nil(t, err)
+	_, _, _, ok = g.Trades.Status(alice)
+	assert.False(t, ok)
+}
+
+func TestRunnerBank(t *testing.T) {
+	g, players := newTestGame(t, 1)
+	alice := players[0]
+	runner := NewRunner(g)
+
+	dir := t.TempDir()
+	bank, err := game.LoadBank(filepath.Join(dir, "bank.json"), filepath.Join(dir, "bank-audit.jsonl"))
+	assert.Nil(t, err)
+	g.Bank = bank
+
+	alice.Inventory.AddGold(10)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "balance", expected: MessageNoBanker},
+		{input: "move north", expected: "You move to the north"},
+		{input: "balance", expected: "Your bank account holds 0 gold.\nYour vault holds 0 of 10 items.\n"},
+		{input: "deposit 11 gold", expected: MessageNotEnoughGold},
+		{input: "deposit 10 gold", expected: "You deposited 10 gold with Beatrice, your balance is 10 gold."},
+		{input: "withdraw 11 gold", expected: MessageAccountGold},
+		{input: "withdraw 4 gold", expected: "You withdrew 4 gold from Beatrice, your balance is 6 gold."},
+		{input: "pickup quill", expected: "You picked up the Quill."},
+		{input: "deposit 102", expected: MessageNoSuchItem},
+		{input: "deposit 101", expected: "You put the Quill in your vault with Beatrice."},
+		{input: "withdraw 102", expected: MessageVaultNoItem},
+		{input: "balance", expected: "Your bank account holds 6 gold.\nYour vault holds 1 of 10 items:\n- Quill (ID: 101)\nRecent transactions:\n"},
+	}
+
+	for _, test := range tests {
+		response, err := runner.Execute(alice, test.input)
+		assert.Nil(t, err, test.input)
+		assert.True(t, strings.HasPrefix(response, test.expected), "%s: %q", test.input, response)
+	}
+
+	assert.Equal(t, 4, alice.Inventory.GetGold())
+	assert.Empty(t, alice.Inventory.GetItems())
+
+	// The balance lists the last few transactions
+	response, err := runner.Execute(alice, "balance")
+	assert.Nil(t, err)
+	assert.Contains(t, response, "deposit 10 gold with Beatrice, balance 10 gold\n")
+	assert.Contains(t, response, "deposit Quill (ID: 101) with Beatrice, balance 6 gold\n")
+
+	// Accounts last between sessions
+	g.Sm.Leave(alice.GetUUID())
+
+	loaded, err := game.LoadBank(filepath.Join(dir, "bank.json"), filepath.Join(dir, "bank-audit.jsonl"))
+	assert.Nil(t, err)
+
+	gold, items := loaded.Account("player0")
+	assert.Equal(t, 6, gold)
+	assert.Len(t, items, 1)
+}
+
+func TestRunnerTradeSwappedItem(t *testing.T) {
+	g, players := newTestGame(t, 2)
+	alice, bob := players[0], players[1]
+	runner := NewRunner(g)
+
+	changed := "[trade] Player0 no longer has everything they offered, so the offer changed and any confirmations were taken back.\n"
+
+	tests := []struct {
+		ps       *game.Player
+		input    string
+		expected string
+		alice    string
+		bob      string
+	}{
+		{ps: alice, input: "pickup coin", expected: "You picked up the Coin."},
+		{ps: alice, input: "trade player1", expected: "You asked Player1 to trade."},
+		{ps: bob, input: "trade player0", expected: "You are now trading with Player0."},
+		{ps: alice, input: "trade offer 101", expected: "You offered Coin (ID: 101)"},
+		{ps: bob, input: "trade confirm", expected: "You confirmed the trade, waiting for Player0 to confirm."},
+
+		// Selling the coin takes it off the offer, and the key picked up next
+		// gets its id
+		{ps: alice, input: "sell Henry 101", expected: "You sold the item Coin to Henry", alice: changed, bob: changed},
+		{ps: alice, input: "pickup key", expected: "You picked up the Key."},
+		{ps: alice, input: "trade confirm", expected: "You confirmed the trade, waiting for Player1 to confirm."},
+		{ps: bob, input: "trade", expected: "Trading with Player0:\nYou offer: nothing\nPlayer0 offers: nothing (confirmed)\n"},
+	}
+
+	for _, test := range tests {
+		takeMessages(alice)
+		takeMessages(bob)
+
+		response, err := runner.Execute(test.ps, test.input)
+		assert.Nil(t, err, test.input)
+		assert.True(t, strings.HasPrefix(response, test.expected), "%s: %q", test.input, response)
+
+		if test.alice != "" || test.bob != "" {
+			assert.Equal(t, test.alice, received(alice), test.input)
+			assert.Equal(t, test.bob, received(bob), test.input)
+		}
+	}
+
+	assert.Equal(t, []game.Item{{ID: "101", Type: game.Key, Name: "Key", Description: "A small brass key", SellingPrice: 2}}, alice.Inventory.GetItems())
+	assert.Empty(t, bob.Inventory.GetItems())
+}
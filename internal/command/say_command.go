@@ -4,7 +4,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/xealgo/muddy/internal/event"
 	"github.com/xealgo/muddy/internal/game"
 )
 
@@ -14,23 +13,20 @@ type SayCommand struct {
 }
 
 // Execute allows the player to say a message in the current room.
-func (cmd SayCommand) Execute(game *game.Game, ps *game.Player) string {
-	currentRoom, ok := game.World.GetRoomById(ps.CurrentRoomId)
+func (cmd SayCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidCmd
 	}
 
-	e := event.EventDispatcher{}
-
-	m := strings.TrimRight(cmd.Message, "\n")
-
-	event := event.Event{
-		Type:      "RoomChat",
-		Timestamp: time.Now(),
-		Data:      ps.DisplayName + ": " + m,
+	if message, ok := allowChat(g, ps); !ok {
+		return message
 	}
 
-	e.SendToRoom(event, game.Sm, currentRoom.ID)
+	m := g.Moderation.Filter(strings.TrimRight(cmd.Message, "\n"))
+
+	g.Moderation.RecordRoomChat(currentRoom.ID, game.ChatMessage{Talker: ps.DisplayName, Text: m, Time: time.Now()})
+	g.Events.Publish(game.ChatSaid{Player: ps, RoomId: currentRoom.ID, Text: m})
 
 	return ""
 }
@@ -14,7 +14,7 @@ type SellCommand struct {
 
 // Execute allows the player to talk to an NPC in the current room.
 func (cmd SellCommand) Execute(g *game.Game, ps *game.Player) string {
-	currentRoom, ok := g.World.GetRoomById(ps.CurrentRoomId)
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidCmd
 	}
@@ -34,5 +34,9 @@ func (cmd SellCommand) Execute(g *game.Game, ps *game.Player) string {
 		return "You don't have that item to sell."
 	}
 
-	return fmt.Sprintf("You sold the item %s to %s for $$%d.\n", item.Name, merchant.Name, item.SellingPrice)
+	g.Events.Publish(game.ItemSold{Player: ps, Merchant: merchant, RoomId: currentRoom.ID, Item: *item})
+
+	response := fmt.Sprintf("You sold the item %s to %s for $$%d.\n", item.Name, merchant.Name, item.SellingPrice)
+
+	return response + splitGold(g, ps, item.SellingPrice)
 }
@@ -8,7 +8,7 @@ type TalkCommand struct {
 
 // Execute allows the player to talk to an NPC in the current room.
 func (cmd TalkCommand) Execute(game *game.Game, ps *game.Player) string {
-	currentRoom, ok := game.World.GetRoomById(ps.CurrentRoomId)
+	currentRoom, ok := game.World.GetRoomById(ps.RoomId())
 	if !ok {
 		return MessageInvalidCmd
 	}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageTellSelf    = "You can't tell yourself anything."
+	MessageTellOffline = "%s isn't online, your message will be delivered when they log in."
+	MessageMailboxFull = "%s isn't online and has too many messages waiting."
+	MessageTellsFull   = "%s isn't online and messages can't be kept for them right now."
+	MessageNoAccount   = "Nobody called %s has played here."
+	MessageNoReply     = "Nobody has told you anything yet."
+)
+
+// TellCommand sends a private message to another player.
+type TellCommand struct {
+	Target  string
+	Message string
+}
+
+// Execute tells the target player the message, or stores it for them if
+// they're offline.
+func (cmd TellCommand) Execute(g *game.Game, ps *game.Player) string {
+	return tell(g, ps, cmd.Target, cmd.Message)
+}
+
+// ReplyCommand answers the last player who told the player something.
+type ReplyCommand struct {
+	Message string
+}
+
+// Execute tells the last player who told the player something the message.
+func (cmd ReplyCommand) Execute(g *game.Game, ps *game.Player) string {
+	target, ok := g.Chat.ReplyTo(ps.Username)
+	if !ok {
+		return MessageNoReply
+	}
+
+	return tell(g, ps, target, cmd.Message)
+}
+
+// tell sends a private message from the player to the target.
+func tell(g *game.Game, ps *game.Player, target string, message string) string {
+	if strings.EqualFold(target, ps.Username) {
+		return MessageTellSelf
+	}
+
+	if g.Chat.IsIgnoring(target, ps.Username) {
+		return fmt.Sprintf(MessageIgnoringYou, target)
+	}
+
+	if response, ok := allowChat(g, ps); !ok {
+		return response
+	}
+
+	message = g.Moderation.Filter(strings.TrimRight(message, "\n"))
+
+	other, ok := g.Sm.FindPlayer(target)
+	if !ok {
+		// Tells are only kept for names with an account, which nobody else can take
+		if !g.Accounts.Exists(target) {
+			return fmt.Sprintf(MessageNoAccount, target)
+		}
+
+		err := g.Chat.StoreTell(target, game.ChatMessage{Talker: ps.DisplayName, Username: ps.Username, Text: message, Time: time.Now()})
+		if errors.Is(err, game.ErrorMailboxFull) {
+			return fmt.Sprintf(MessageMailboxFull, target)
+		}
+
+		if errors.Is(err, game.ErrorTooManyTells) {
+			return fmt.Sprintf(MessageTellsFull, target)
+		}
+
+		return fmt.Sprintf(MessageTellOffline, target)
+	}
+
+	g.Sm.SendToPlayer(other.GetUUID(), fmt.Sprintf("%s tells you: %s", ps.DisplayName, message))
+	other.SendOutOfBand(game.OOBCommChannel, game.ChannelData{Channel: "tell", Talker: ps.DisplayName, Text: message})
+	g.Chat.SetReplyTo(other.Username, ps.Username)
+
+	return fmt.Sprintf("You tell %s: %s", other.DisplayName, message)
+}
@@ -0,0 +1,231 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+// Trade actions
+const (
+	TradeStatus  = "status"
+	TradeRequest = "request"
+	TradeOffer   = "offer"
+	TradeRemove  = "remove"
+	TradeConfirm = "confirm"
+	TradeCancel  = "cancel"
+)
+
+const (
+	MessageNotTrading      = "You aren't trading with anyone."
+	MessageTradeSelf       = "You can't trade with yourself."
+	MessageTradeNotHere    = "%s isn't here."
+	MessageAlreadyTrading  = "You or %s are already trading with someone."
+	MessageAlreadyOffered  = "You already offered that."
+	MessageNotOffered      = "You haven't offered that."
+	MessageTradeFailed     = "The trade failed because something offered is gone, both of you need to confirm again."
+	MessageTradeConfirmed  = "You confirmed the trade, waiting for %s to confirm."
+	MessageTradeComplete   = "Your trade with %s is complete."
+	MessageTradeRequested  = "You asked %s to trade."
+	MessageTradeStarted    = "You are now trading with %s. Use trade offer, trade remove and trade confirm."
+	MessageTradeOfferReset = "%s %s, any confirmations were taken back."
+)
+
+// TradeCommand negotiates a trade of items and gold with another player.
+// Nothing changes hands until both players confirm the same offers.
+type TradeCommand struct {
+	Action string
+	Target string // Player to trade with, or item id offered or removed
+	Gold   int    // Gold offered or removed, instead of an item
+}
+
+// Execute runs the trade action.
+func (cmd TradeCommand) Execute(g *game.Game, ps *game.Player) string {
+	switch cmd.Action {
+	case TradeRequest:
+		return cmd.request(g, ps)
+	case TradeOffer, TradeRemove:
+		return cmd.change(g, ps)
+	case TradeConfirm:
+		return cmd.confirm(g, ps)
+	case TradeCancel:
+		if _, _, _, ok := g.Trades.Status(ps); !ok {
+			g.Trades.Cancel(ps)
+			return MessageNotTrading
+		}
+
+		g.CancelTrade(ps)
+		return ""
+	default:
+		return tradeStatus(g, ps)
+	}
+}
+
+// request asks the target to trade, or starts trading if they already asked.
+func (cmd TradeCommand) request(g *game.Game, ps *game.Player) string {
+	target, ok := g.Sm.FindPlayer(cmd.Target)
+	if !ok {
+		return fmt.Sprintf(MessagePlayerOffline, cmd.Target)
+	}
+
+	if target == ps {
+		return MessageTradeSelf
+	}
+
+	if target.RoomId() != ps.RoomId() {
+		return fmt.Sprintf(MessageTradeNotHere, target.DisplayName)
+	}
+
+	started, err := g.Trades.Request(ps, target)
+	if err != nil {
+		return fmt.Sprintf(MessageAlreadyTrading, target.DisplayName)
+	}
+
+	if started {
+		g.Sm.SendToPlayer(target.GetUUID(), "[trade] "+fmt.Sprintf(MessageTradeStarted, ps.DisplayName))
+		return fmt.Sprintf(MessageTradeStarted, target.DisplayName)
+	}
+
+	g.Sm.SendToPlayer(target.GetUUID(), fmt.Sprintf("[trade] %s wants to trade with you, type 'trade %s' to start.", ps.DisplayName, ps.Username))
+
+	return fmt.Sprintf(MessageTradeRequested, target.DisplayName)
+}
+
+// change adds gold or an item to the player's offer, or takes it back off.
+func (cmd TradeCommand) change(g *game.Game, ps *game.Player) string {
+	other, offers, _, ok := g.Trades.Status(ps)
+	if !ok {
+		return MessageNotTrading
+	}
+
+	what := fmt.Sprintf("%d gold", cmd.Gold)
+
+	var err error
+	if cmd.Action == TradeRemove {
+		err = g.Trades.Withdraw(ps, cmd.Gold, cmd.Target)
+		if cmd.Gold == 0 {
+			what = "item " + cmd.Target
+		}
+	} else {
+		var item *game.Item
+		if cmd.Gold == 0 {
+			item = findItem(ps.Inventory, cmd.Target)
+			if item == nil {
+				return MessageNoSuchItem
+			}
+
+			what = fmt.Sprintf("%s (ID: %s)", item.Name, item.ID)
+		}
+
+		if ps.Inventory.GetGold() < offers[0].Gold+cmd.Gold {
+			return MessageNotEnoughGold
+		}
+
+		err = g.Trades.Offer(ps, cmd.Gold, item)
+	}
+
+	switch {
+	case errors.Is(err, game.ErrorAlreadyOffered):
+		return MessageAlreadyOffered
+	case errors.Is(err, game.ErrorNotOffered):
+		return MessageNotOffered
+	case err != nil:
+		return MessageNotTrading
+	}
+
+	verb := "offered " + what
+	if cmd.Action == TradeRemove {
+		verb = "took back " + what
+	}
+
+	g.Sm.SendToPlayer(other.GetUUID(), "[trade] "+fmt.Sprintf(MessageTradeOfferReset, ps.DisplayName, verb))
+
+	return fmt.Sprintf(MessageTradeOfferReset, "You", verb)
+}
+
+// confirm accepts the current offers, completing the trade once both players
+// have.
+func (cmd TradeCommand) confirm(g *game.Game, ps *game.Player) string {
+	other, _, _, ok := g.Trades.Status(ps)
+	if !ok {
+		return MessageNotTrading
+	}
+
+	result, err := g.Trades.Confirm(ps)
+	if errors.Is(err, game.ErrorTradeFailed) {
+		g.Sm.SendToPlayer(other.GetUUID(), "[trade] "+MessageTradeFailed)
+		return MessageTradeFailed
+	}
+
+	if err != nil {
+		return MessageNotTrading
+	}
+
+	if result == nil {
+		g.Sm.SendToPlayer(other.GetUUID(), fmt.Sprintf("[trade] %s confirmed the trade.", ps.DisplayName))
+		return fmt.Sprintf(MessageTradeConfirmed, other.DisplayName)
+	}
+
+	g.Events.Publish(game.Traded{
+		Player:    result.Players[0],
+		With:      result.Players[1],
+		Gold:      result.Gold[0],
+		Items:     result.Items[0],
+		WithGold:  result.Gold[1],
+		WithItems: result.Items[1],
+	})
+
+	// The other player's inventory changed too
+	g.SyncOutOfBand(other)
+	g.Sm.SendToPlayer(other.GetUUID(), "[trade] "+fmt.Sprintf(MessageTradeComplete, ps.DisplayName))
+
+	return fmt.Sprintf(MessageTradeComplete, other.DisplayName)
+}
+
+// tradeStatus describes both sides of the player's trade.
+func tradeStatus(g *game.Game, ps *game.Player) string {
+	other, offers, confirmed, ok := g.Trades.Status(ps)
+	if !ok {
+		return MessageNotTrading
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Trading with %s:\n", other.DisplayName))
+
+	for i, name := range []string{"You offer", other.DisplayName + " offers"} {
+		goods := []string{}
+		for _, item := range offers[i].Items {
+			goods = append(goods, fmt.Sprintf("%s (ID: %s)", item.Name, item.ID))
+		}
+
+		if offers[i].Gold > 0 {
+			goods = append(goods, fmt.Sprintf("%d gold", offers[i].Gold))
+		}
+
+		if len(goods) == 0 {
+			goods = append(goods, "nothing")
+		}
+
+		builder.WriteString(fmt.Sprintf("%s: %s", name, strings.Join(goods, ", ")))
+		if confirmed[i] {
+			builder.WriteString(" (confirmed)")
+		}
+
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+// findItem returns the item with the id in the inventory, or nil.
+func findItem(inv *game.Inventory, itemId string) *game.Item {
+	for _, item := range inv.GetItems() {
+		if strings.EqualFold(item.ID, itemId) {
+			return &item
+		}
+	}
+
+	return nil
+}
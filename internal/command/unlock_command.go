@@ -0,0 +1,66 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+)
+
+const (
+	MessageNoSuchDoor    = "There is no such door here."
+	MessageDoorNotLocked = "The %s door isn't locked."
+	MessageNoDoorKey     = "You don't have the key to the %s door."
+	MessageDoorUnlocked  = "You unlock the %s door."
+)
+
+// UnlockCommand type represents an unlock command for a door.
+type UnlockCommand struct {
+	Door string
+}
+
+// Execute unlocks a locked door in the current room when the player carries
+// its key.
+func (cmd UnlockCommand) Execute(g *game.Game, ps *game.Player) string {
+	currentRoom, ok := g.World.GetRoomById(ps.RoomId())
+	if !ok {
+		return MessageInvalidCmd
+	}
+
+	for _, door := range currentRoom.Doors {
+		if !strings.EqualFold(door.Name, cmd.Door) {
+			continue
+		}
+
+		if !door.IsLocked {
+			return fmt.Sprintf(MessageDoorNotLocked, door.Name)
+		}
+
+		if !hasKey(ps, door) {
+			return fmt.Sprintf(MessageNoDoorKey, door.Name)
+		}
+
+		currentRoom.UnlockDoor(door.Name)
+		door.IsLocked = false
+		g.Events.Publish(game.DoorUnlocked{Player: ps, RoomId: currentRoom.ID, Door: door})
+
+		return fmt.Sprintf(MessageDoorUnlocked, door.Name)
+	}
+
+	return MessageNoSuchDoor
+}
+
+// hasKey checks if the player carries the key item for the door.
+func hasKey(ps *game.Player, door game.Door) bool {
+	if door.Key == "" {
+		return false
+	}
+
+	for _, item := range ps.Inventory.GetItems() {
+		if item.Type == game.Key && strings.EqualFold(item.Name, door.Key) {
+			return true
+		}
+	}
+
+	return false
+}
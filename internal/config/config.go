@@ -3,27 +3,110 @@ package config
 import (
 	"crypto/tls"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 const (
-	ConfigHttpPort = "HTTP_PORT"
-	ConfigGrpcPort = "GRPC_PORT"
-	ConfigWtPort   = "WT_PORT"
-	ConfigCertFile = "CERT_FILE"
-	ConfigKeyFile  = "KEY_FILE"
+	ConfigHttpPort   = "HTTP_PORT"
+	ConfigGrpcPort   = "GRPC_PORT"
+	ConfigWtPort     = "WT_PORT"
+	ConfigTelnetPort = "TELNET_PORT"
+	ConfigCertFile   = "CERT_FILE"
+	ConfigKeyFile    = "KEY_FILE"
+
+	ConfigResumeGraceSeconds = "RESUME_GRACE_SECONDS"
+	ConfigPendingTTLSeconds  = "PENDING_TTL_SECONDS"
+	ConfigIdleTimeoutSeconds = "IDLE_TIMEOUT_SECONDS"
+	ConfigIdleWarningSeconds = "IDLE_WARNING_SECONDS"
+	ConfigMaxPlayers         = "MAX_PLAYERS"
+	ConfigReservedSlots      = "RESERVED_SLOTS"
+	ConfigLoginQueueSize     = "LOGIN_QUEUE_SIZE"
+	ConfigAdmins             = "ADMINS"
+	ConfigBuilders           = "BUILDERS"
+	ConfigOutputQueueSize    = "OUTPUT_QUEUE_SIZE"
+	ConfigOutputQueuePolicy  = "OUTPUT_QUEUE_POLICY"
+	ConfigJournalDir         = "JOURNAL_DIR"
+	ConfigJournalSegmentSize = "JOURNAL_SEGMENT_BYTES"
+	ConfigModerators         = "MODERATORS"
+	ConfigChatRateLimit      = "CHAT_RATE_LIMIT"
+	ConfigChatRateSeconds    = "CHAT_RATE_WINDOW_SECONDS"
+	ConfigChatFilter         = "CHAT_FILTER"
+	ConfigSaveDir            = "SAVE_DIR"
+
+	DefaultResumeGracePeriod = 60 * time.Second
+	DefaultPendingTTL        = 2 * time.Minute
+	DefaultIdleTimeout       = 30 * time.Minute
+	DefaultIdleWarning       = 60 * time.Second
+	DefaultMaxPlayers        = 64
+	DefaultReservedSlots     = 4
+	DefaultLoginQueueSize    = 100
+	DefaultOutputQueueSize   = 256
+	DefaultOutputQueuePolicy = "drop"
+	DefaultJournalDir        = "./journal"
+	DefaultJournalSegment    = 4 * 1024 * 1024
+	DefaultChatRateLimit     = 5
+	DefaultChatRateWindow    = 10 * time.Second
+	DefaultSaveDir           = "./save"
 )
 
 // Application configuration
 type Config struct {
-	HttpPort  int
-	GrpcPort  int
-	WTPort    int
-	CertFile  string
-	KeyFile   string
-	TLSConfig *tls.Config
+	HttpPort   int
+	GrpcPort   int
+	WTPort     int
+	TelnetPort int // Optional telnet listener, disabled when 0
+	CertFile   string
+	KeyFile    string
+	TLSConfig  *tls.Config
+
+	// How long a player whose connection dropped can resume their session, 0 disables resuming
+	ResumeGracePeriod time.Duration
+
+	// How long a login can wait before connecting, 0 keeps pending sessions forever
+	PendingTTL time.Duration
+
+	// How long a connected player can go without sending a command, 0 disables idle disconnects
+	IdleTimeout time.Duration
+
+	// How long before an idle disconnect the player is warned
+	IdleWarning time.Duration
+
+	// Maximum number of players, ReservedSlots of which only admins and builders can take
+	MaxPlayers    int
+	ReservedSlots int
+
+	// How many players can wait for a free slot, 0 disables the queue
+	LoginQueueSize int
+
+	// Usernames of admins, builders and moderators
+	Admins     []string
+	Builders   []string
+	Moderators []string
+
+	// Messages queued per player, 0 writes output directly to the connection
+	OutputQueueSize int
+
+	// What happens when a player's output queue is full, "drop" or "disconnect"
+	OutputQueuePolicy string
+
+	// Where game events are journaled, and how large each journal segment can grow
+	JournalDir         string
+	JournalSegmentSize int
+
+	// How many chat messages a player can send per window before they're muted, 0 disables the limit
+	ChatRateLimit  int
+	ChatRateWindow time.Duration
+
+	// Words replaced with asterisks in chat
+	ChatFilter []string
+
+	// Where data kept between logins, such as guilds, is saved
+	SaveDir string
 
 	// Internal
 	envPath string
@@ -40,6 +123,24 @@ func NewConfig(opts ...ConfigOption) (*Config, error) {
 		CertFile: "server.crt",
 		KeyFile:  "server.key",
 		envPath:  ".env",
+
+		ResumeGracePeriod: DefaultResumeGracePeriod,
+		PendingTTL:        DefaultPendingTTL,
+		IdleTimeout:       DefaultIdleTimeout,
+		IdleWarning:       DefaultIdleWarning,
+		MaxPlayers:        DefaultMaxPlayers,
+		ReservedSlots:     DefaultReservedSlots,
+		LoginQueueSize:    DefaultLoginQueueSize,
+		OutputQueueSize:   DefaultOutputQueueSize,
+		OutputQueuePolicy: DefaultOutputQueuePolicy,
+
+		JournalDir:         DefaultJournalDir,
+		JournalSegmentSize: DefaultJournalSegment,
+
+		ChatRateLimit:  DefaultChatRateLimit,
+		ChatRateWindow: DefaultChatRateWindow,
+
+		SaveDir: DefaultSaveDir,
 	}
 
 	for _, opts := range opts {
@@ -109,6 +210,103 @@ func WithDefaults(httpPort int, grpcPort int, wtPort int, certFile string, keyFi
 	}
 }
 
+// WithTelnetPort enables the telnet listener on the given port
+func WithTelnetPort(port int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.TelnetPort = port
+	}
+}
+
+// WithResumeGracePeriod sets how long a dropped player can resume their session
+func WithResumeGracePeriod(period time.Duration) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ResumeGracePeriod = period
+	}
+}
+
+// WithPendingTTL sets how long a login can wait before connecting
+func WithPendingTTL(ttl time.Duration) ConfigOption {
+	return func(cfg *Config) {
+		cfg.PendingTTL = ttl
+	}
+}
+
+// WithIdleTimeout sets how long a player can idle, and how long before the disconnect they're warned
+func WithIdleTimeout(timeout time.Duration, warning time.Duration) ConfigOption {
+	return func(cfg *Config) {
+		cfg.IdleTimeout = timeout
+		cfg.IdleWarning = warning
+	}
+}
+
+// WithMaxPlayers sets the maximum number of players and how many slots are reserved for admins and builders
+func WithMaxPlayers(maxPlayers int, reserved int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.MaxPlayers = maxPlayers
+		cfg.ReservedSlots = reserved
+	}
+}
+
+// WithLoginQueueSize sets how many players can wait for a free slot
+func WithLoginQueueSize(size int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.LoginQueueSize = size
+	}
+}
+
+// WithOutputQueue sets the per-player output queue size and overflow policy
+func WithOutputQueue(size int, policy string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.OutputQueueSize = size
+		cfg.OutputQueuePolicy = policy
+	}
+}
+
+// WithJournal sets where game events are journaled and how large each segment can grow
+func WithJournal(dir string, segmentSize int) ConfigOption {
+	return func(cfg *Config) {
+		cfg.JournalDir = dir
+		cfg.JournalSegmentSize = segmentSize
+	}
+}
+
+// WithChatLimits sets how many chat messages a player can send per window and the words filtered from chat
+func WithChatLimits(limit int, window time.Duration, filter []string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ChatRateLimit = limit
+		cfg.ChatRateWindow = window
+		cfg.ChatFilter = filter
+	}
+}
+
+// WithSaveDir sets where data kept between logins is saved
+func WithSaveDir(dir string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.SaveDir = dir
+	}
+}
+
+// IsAdmin checks if the username belongs to an admin
+func (cfg *Config) IsAdmin(username string) bool {
+	return slices.ContainsFunc(cfg.Admins, func(name string) bool {
+		return strings.EqualFold(name, username)
+	})
+}
+
+// IsBuilder checks if the username belongs to a builder
+func (cfg *Config) IsBuilder(username string) bool {
+	return slices.ContainsFunc(cfg.Builders, func(name string) bool {
+		return strings.EqualFold(name, username)
+	})
+}
+
+// IsModerator checks if the username belongs to a moderator
+func (cfg *Config) IsModerator(username string) bool {
+	return slices.ContainsFunc(cfg.Moderators, func(name string) bool {
+		return strings.EqualFold(name, username)
+	})
+}
+
 // Loads configuration from a .env file
 func (cfg *Config) LoadFromEnv() error {
 	// Check if file exists
@@ -172,6 +370,16 @@ func (cfg *Config) setPortsFromEnv() error {
 		cfg.WTPort = port
 	}
 
+	telnetPort := GetEnv(ConfigTelnetPort, "")
+	if telnetPort != "" {
+		port, err := strconv.Atoi(telnetPort)
+		if err != nil {
+			return ConfigError{Type: InvalidValue, Message: "Invalid telnet port value", EnvPath: cfg.envPath, Wrapped: err}
+		}
+
+		cfg.TelnetPort = port
+	}
+
 	return nil
 }
 
@@ -182,6 +390,80 @@ func (cfg *Config) SetFromEnv() error {
 		return err
 	}
 
+	cfg.ResumeGracePeriod, err = cfg.getSecondsFromEnv(ConfigResumeGraceSeconds, cfg.ResumeGracePeriod)
+	if err != nil {
+		return err
+	}
+
+	cfg.PendingTTL, err = cfg.getSecondsFromEnv(ConfigPendingTTLSeconds, cfg.PendingTTL)
+	if err != nil {
+		return err
+	}
+
+	cfg.IdleTimeout, err = cfg.getSecondsFromEnv(ConfigIdleTimeoutSeconds, cfg.IdleTimeout)
+	if err != nil {
+		return err
+	}
+
+	cfg.IdleWarning, err = cfg.getSecondsFromEnv(ConfigIdleWarningSeconds, cfg.IdleWarning)
+	if err != nil {
+		return err
+	}
+
+	cfg.MaxPlayers, err = cfg.getIntFromEnv(ConfigMaxPlayers, cfg.MaxPlayers)
+	if err != nil {
+		return err
+	}
+
+	cfg.ReservedSlots, err = cfg.getIntFromEnv(ConfigReservedSlots, cfg.ReservedSlots)
+	if err != nil {
+		return err
+	}
+
+	if cfg.ReservedSlots > cfg.MaxPlayers {
+		return ConfigError{Type: InvalidValue, Message: ConfigReservedSlots + " can't exceed " + ConfigMaxPlayers, EnvPath: cfg.envPath}
+	}
+
+	cfg.LoginQueueSize, err = cfg.getIntFromEnv(ConfigLoginQueueSize, cfg.LoginQueueSize)
+	if err != nil {
+		return err
+	}
+
+	cfg.OutputQueueSize, err = cfg.getIntFromEnv(ConfigOutputQueueSize, cfg.OutputQueueSize)
+	if err != nil {
+		return err
+	}
+
+	cfg.OutputQueuePolicy = GetEnv(ConfigOutputQueuePolicy, cfg.OutputQueuePolicy)
+	if cfg.OutputQueuePolicy != "drop" && cfg.OutputQueuePolicy != "disconnect" {
+		return ConfigError{Type: InvalidValue, Message: "Invalid " + ConfigOutputQueuePolicy + " value, use drop or disconnect", EnvPath: cfg.envPath}
+	}
+
+	cfg.JournalDir = GetEnv(ConfigJournalDir, cfg.JournalDir)
+
+	cfg.JournalSegmentSize, err = cfg.getIntFromEnv(ConfigJournalSegmentSize, cfg.JournalSegmentSize)
+	if err != nil {
+		return err
+	}
+
+	cfg.ChatRateLimit, err = cfg.getIntFromEnv(ConfigChatRateLimit, cfg.ChatRateLimit)
+	if err != nil {
+		return err
+	}
+
+	cfg.ChatRateWindow, err = cfg.getSecondsFromEnv(ConfigChatRateSeconds, cfg.ChatRateWindow)
+	if err != nil {
+		return err
+	}
+
+	cfg.ChatFilter = getListFromEnv(ConfigChatFilter, cfg.ChatFilter)
+
+	cfg.SaveDir = GetEnv(ConfigSaveDir, cfg.SaveDir)
+
+	cfg.Admins = getListFromEnv(ConfigAdmins, cfg.Admins)
+	cfg.Builders = getListFromEnv(ConfigBuilders, cfg.Builders)
+	cfg.Moderators = getListFromEnv(ConfigModerators, cfg.Moderators)
+
 	cfg.CertFile = GetEnv(ConfigCertFile, cfg.CertFile)
 
 	_, err = os.Stat(cfg.CertFile)
@@ -199,6 +481,53 @@ func (cfg *Config) SetFromEnv() error {
 	return nil
 }
 
+// Loads a duration given in whole seconds from the environment, or returns the current value if not provided
+func (cfg *Config) getSecondsFromEnv(name string, value time.Duration) (time.Duration, error) {
+	env := GetEnv(name, "")
+	if env == "" {
+		return value, nil
+	}
+
+	seconds, err := strconv.Atoi(env)
+	if err != nil || seconds < 0 {
+		return value, ConfigError{Type: InvalidValue, Message: "Invalid " + name + " value", EnvPath: cfg.envPath, Wrapped: err}
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// Loads a non-negative integer from the environment, or returns the current value if not provided
+func (cfg *Config) getIntFromEnv(name string, value int) (int, error) {
+	env := GetEnv(name, "")
+	if env == "" {
+		return value, nil
+	}
+
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 0 {
+		return value, ConfigError{Type: InvalidValue, Message: "Invalid " + name + " value", EnvPath: cfg.envPath, Wrapped: err}
+	}
+
+	return n, nil
+}
+
+// Loads a comma separated list from the environment, or returns the current value if not provided
+func getListFromEnv(name string, value []string) []string {
+	env := GetEnv(name, "")
+	if env == "" {
+		return value
+	}
+
+	list := []string{}
+	for _, item := range strings.Split(env, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
 // Attempts to load an env by name or returns the default value if not provided
 func GetEnv(name string, defValue string) string {
 	if value, exists := os.LookupEnv(name); exists {
@@ -4,11 +4,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/xealgo/muddy/internal/game"
 )
 
+// Event types
+const (
+	RoomChat       = "RoomChat"
+	RoomEmote      = "RoomEmote"      // A player emoted or used a social
+	PlayerArrived  = "PlayerArrived"  // Another player entered the room
+	PlayerDeparted = "PlayerDeparted" // Another player left the room
+	PlayerJoined   = "PlayerJoined"   // A player joined the game
+	PlayerQuit     = "PlayerQuit"     // A player left the game
+)
+
 // Simple event data type
 type Event struct {
 	Type      string      `json:"type"`
@@ -16,31 +27,314 @@ type Event struct {
 	Data      interface{} `json:"data"`
 }
 
+// RoomChatData is the data of a RoomChat event.
+type RoomChatData struct {
+	Talker string `json:"talker"`
+	Text   string `json:"text"`
+}
+
+// EmoteData is the data of a RoomEmote event. Text is the complete emote, e.g.
+// "Alice waves."
+type EmoteData struct {
+	Actor string `json:"actor"`
+	Text  string `json:"text"`
+}
+
+// MovementData is the data of PlayerArrived, PlayerDeparted, PlayerJoined and
+// PlayerQuit events. Direction is the door the player came or went through,
+// empty when it isn't known.
+type MovementData struct {
+	Player    string `json:"player"`
+	Direction string `json:"direction,omitempty"`
+}
+
+// Text describes the event for clients that render plain text. Events without
+// a description return an empty string.
+func (e Event) Text() string {
+	switch data := e.Data.(type) {
+	case RoomChatData:
+		return data.Talker + ": " + data.Text
+	case EmoteData:
+		return data.Text
+	case MovementData:
+		switch e.Type {
+		case PlayerArrived:
+			if data.Direction == "" {
+				return data.Player + " arrives."
+			}
+			return data.Player + " arrives from the " + data.Direction + "."
+		case PlayerDeparted:
+			if data.Direction == "" {
+				return data.Player + " leaves."
+			}
+			return data.Player + " leaves " + data.Direction + "."
+		case PlayerJoined:
+			return data.Player + " has joined the game."
+		case PlayerQuit:
+			return data.Player + " has left the game."
+		}
+	}
+
+	return ""
+}
+
+// Unmarshal decodes a JSON encoded event. Data of known event types is decoded
+// into its typed struct.
+func Unmarshal(data []byte) (Event, error) {
+	raw := struct {
+		Type      string          `json:"type"`
+		Timestamp time.Time       `json:"timestamp"`
+		Data      json.RawMessage `json:"data"`
+	}{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Event{}, fmt.Errorf("unable to decode event: %w", err)
+	}
+
+	e := Event{Type: raw.Type, Timestamp: raw.Timestamp}
+
+	switch raw.Type {
+	case RoomChat:
+		chat := RoomChatData{}
+		if err := json.Unmarshal(raw.Data, &chat); err != nil {
+			return e, fmt.Errorf("unable to decode %s event: %w", raw.Type, err)
+		}
+		e.Data = chat
+	case RoomEmote:
+		emote := EmoteData{}
+		if err := json.Unmarshal(raw.Data, &emote); err != nil {
+			return e, fmt.Errorf("unable to decode %s event: %w", raw.Type, err)
+		}
+		e.Data = emote
+	case PlayerArrived, PlayerDeparted, PlayerJoined, PlayerQuit:
+		movement := MovementData{}
+		if err := json.Unmarshal(raw.Data, &movement); err != nil {
+			return e, fmt.Errorf("unable to decode %s event: %w", raw.Type, err)
+		}
+		e.Data = movement
+	default:
+		if len(raw.Data) > 0 {
+			if err := json.Unmarshal(raw.Data, &e.Data); err != nil {
+				return e, fmt.Errorf("unable to decode %s event: %w", raw.Type, err)
+			}
+		}
+	}
+
+	return e, nil
+}
+
 // EventDispatcher is responsible for dispatching events to their respective handlers.
 type EventDispatcher struct {
-	//
+	sm   *game.SessionManager
+	chat *game.Chat // Who ignores whom
+}
+
+// NewEventDispatcher creates a new EventDispatcher instance. Players don't
+// receive chat from the players they ignore in chat.
+func NewEventDispatcher(sm *game.SessionManager, chat *game.Chat) *EventDispatcher {
+	return &EventDispatcher{sm: sm, chat: chat}
+}
+
+// Subscribe broadcasts the game's domain events to the players they concern
+// and logs every event.
+func (e EventDispatcher) Subscribe(bus *game.EventBus) {
+	game.Subscribe(bus, e.chatSaid)
+	game.Subscribe(bus, e.channelSaid)
+	game.Subscribe(bus, e.emoted)
+	game.Subscribe(bus, e.playerEntered)
+	game.Subscribe(bus, e.playerLeft)
+
+	bus.SubscribeAll(func(de game.DomainEvent) {
+		slog.Debug("Game event", "event", de.EventName(), "data", de)
+	}, game.WithAsync())
+}
+
+// chatSaid sends what a player said to everyone in their room.
+func (e EventDispatcher) chatSaid(said game.ChatSaid) {
+	event := Event{
+		Type:      RoomChat,
+		Timestamp: time.Now(),
+		Data:      RoomChatData{Talker: said.Player.DisplayName, Text: said.Text},
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("unable to encode chat", "roomId", said.RoomId, "error", err)
+		return
+	}
+
+	for _, ps := range e.sm.GetPlayersInRoom(said.RoomId, "") {
+		if e.ignores(ps, said.Player) {
+			continue
+		}
+
+		if err := ps.WriteEvent(data); err != nil {
+			slog.Error("failed to broadcast to player", "player", ps.DisplayName, "error", err)
+		}
+
+		err := ps.SendOutOfBand(game.OOBCommChannel, game.ChannelData{
+			Channel: "say",
+			Talker:  said.Player.DisplayName,
+			Text:    said.Text,
+		})
+		if err != nil {
+			slog.Error("failed to send out-of-band data", "player", ps.DisplayName, "package", game.OOBCommChannel, "error", err)
+		}
+	}
+}
+
+// ignores checks if a player ignores the talker.
+func (e EventDispatcher) ignores(ps *game.Player, talker *game.Player) bool {
+	return e.chat != nil && e.chat.IsIgnoring(ps.Username, talker.Username)
+}
+
+// emoted shows an emote to everyone else in the player's room, with its own
+// text for the target.
+func (e EventDispatcher) emoted(emoted game.Emoted) {
+	text, err := json.Marshal(Event{
+		Type:      RoomEmote,
+		Timestamp: time.Now(),
+		Data:      EmoteData{Actor: emoted.Player.DisplayName, Text: emoted.Text},
+	})
+	if err != nil {
+		slog.Error("unable to encode emote", "error", err)
+		return
+	}
+
+	targetText := text
+	if emoted.Target != nil {
+		targetText, err = json.Marshal(Event{
+			Type:      RoomEmote,
+			Timestamp: time.Now(),
+			Data:      EmoteData{Actor: emoted.Player.DisplayName, Text: emoted.TargetText},
+		})
+		if err != nil {
+			slog.Error("unable to encode emote", "error", err)
+			return
+		}
+	}
+
+	for _, ps := range e.sm.GetPlayersInRoom(emoted.RoomId, emoted.Player.GetUUID()) {
+		if e.ignores(ps, emoted.Player) {
+			continue
+		}
+
+		data := text
+		if emoted.Target != nil && ps.GetUUID() == emoted.Target.GetUUID() {
+			data = targetText
+		}
+
+		if err := ps.WriteEvent(data); err != nil {
+			slog.Error("failed to broadcast to player", "player", ps.DisplayName, "error", err)
+		}
+	}
+}
+
+// channelSaid sends a channel message to the members listening to it.
+func (e EventDispatcher) channelSaid(said game.ChannelSaid) {
+	listeners := make(map[string]bool, len(said.Listeners))
+	for _, username := range said.Listeners {
+		listeners[username] = true
+	}
+
+	text := fmt.Sprintf("[%s] %s: %s", said.Channel, said.Player.DisplayName, said.Text)
+
+	for _, ps := range e.sm.GetActivePlayers() {
+		if !listeners[strings.ToLower(ps.Username)] || e.ignores(ps, said.Player) {
+			continue
+		}
+
+		e.sm.SendToPlayer(ps.GetUUID(), text)
+
+		err := ps.SendOutOfBand(game.OOBCommChannel, game.ChannelData{
+			Channel: said.Channel,
+			Talker:  said.Player.DisplayName,
+			Text:    said.Text,
+		})
+		if err != nil {
+			slog.Error("failed to send out-of-band data", "player", ps.DisplayName, "package", game.OOBCommChannel, "error", err)
+		}
+	}
+}
+
+// playerEntered tells the room a player arrived. Players joining the game are
+// announced to their room, and to everyone else who wants join notices.
+func (e EventDispatcher) playerEntered(entered game.PlayerEntered) {
+	if entered.FromRoomId == 0 {
+		e.sendJoinNotice(PlayerJoined, entered.Player, entered.RoomId)
+		return
+	}
+
+	event := Event{
+		Type:      PlayerArrived,
+		Timestamp: time.Now(),
+		Data:      MovementData{Player: entered.Player.DisplayName, Direction: entered.Direction},
+	}
+
+	if err := sendToRoom(event, e.sm, entered.RoomId, entered.Player.GetUUID()); err != nil {
+		slog.Error("failed to announce arrival", "roomId", entered.RoomId, "error", err)
+	}
+}
+
+// playerLeft tells the room a player departed. Players leaving the game are
+// announced to their room, and to everyone else who wants join notices.
+func (e EventDispatcher) playerLeft(left game.PlayerLeft) {
+	if left.ToRoomId == 0 {
+		e.sendJoinNotice(PlayerQuit, left.Player, left.RoomId)
+		return
+	}
+
+	event := Event{
+		Type:      PlayerDeparted,
+		Timestamp: time.Now(),
+		Data:      MovementData{Player: left.Player.DisplayName, Direction: left.Direction},
+	}
+
+	if err := sendToRoom(event, e.sm, left.RoomId, left.Player.GetUUID()); err != nil {
+		slog.Error("failed to announce departure", "roomId", left.RoomId, "error", err)
+	}
+}
+
+// sendJoinNotice tells every other player that a player joined or left the
+// game. Players outside the player's room can turn these notices off.
+func (e EventDispatcher) sendJoinNotice(typ string, ps *game.Player, roomId int) {
+	data, err := json.Marshal(Event{
+		Type:      typ,
+		Timestamp: time.Now(),
+		Data:      MovementData{Player: ps.DisplayName},
+	})
+	if err != nil {
+		slog.Error("unable to encode join notice", "type", typ, "error", err)
+		return
+	}
+
+	for _, other := range e.sm.GetActivePlayers() {
+		if other.GetUUID() == ps.GetUUID() || (other.RoomId() != roomId && !other.WantsJoinNotices()) {
+			continue
+		}
+
+		if err := other.WriteEvent(data); err != nil {
+			slog.Error("failed to send join notice", "player", other.DisplayName, "error", err)
+		}
+	}
 }
 
 // SendToRoom sends an event to all players in a specific room.
 func (e EventDispatcher) SendToRoom(event Event, sm *game.SessionManager, roomId int) error {
+	return sendToRoom(event, sm, roomId, "")
+}
+
+// sendToRoom sends an event to everyone in a room except the given player.
+func sendToRoom(event Event, sm *game.SessionManager, roomId int, skipUUID string) error {
 	data, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("unable to send event %s to room %d: %w", event.Type, roomId, err)
 	}
 
-	message := string(data)
-	active := sm.GetActivePlayers()
-	prefixed := "event:" + message
-
-	// This will be slow if we ramp the max player count to 1000+
-	// At that point, we'll want to create a slice within the room struct
-	// or a shared map roomId -> []playerId.
-	for _, ps := range active {
-		if ps.CurrentRoomId == roomId {
-			err := ps.WriteString(prefixed)
-			if err != nil {
-				slog.Error("failed to broadcast to player %s: %w", ps.DisplayName, err)
-			}
+	for _, ps := range sm.GetPlayersInRoom(roomId, skipUUID) {
+		err := ps.WriteEvent(data)
+		if err != nil {
+			slog.Error("failed to broadcast to player", "player", ps.DisplayName, "error", err)
 		}
 	}
 
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MinPasswordLength = 6
+	MaxPasswordLength = 64
+
+	passwordIterations = 600_000 // PBKDF2-SHA256 rounds for new passwords
+	passwordSaltSize   = 16
+	passwordKeySize    = 32
+)
+
+var (
+	ErrorWrongPassword   = errors.New("wrong password")
+	ErrorInvalidPassword = errors.New("invalid password")
+)
+
+// savedLogin is how a player's account is written to the accounts file. Only
+// a salted hash of the password is kept.
+type savedLogin struct {
+	Username   string    `json:"username"`
+	Salt       []byte    `json:"salt"`
+	Hash       []byte    `json:"hash"`
+	Iterations int       `json:"iterations"`
+	Created    time.Time `json:"created"`
+}
+
+// Accounts keeps the players' passwords, so saved state kept by username,
+// such as bank accounts and guild ranks, only goes to the player who owns the
+// name. When it has a file, every change is saved to it. Passwords are hashed
+// and the file written without holding the lock, so a slow login never holds
+// up the others.
+type Accounts struct {
+	logins    map[string]*savedLogin // Lowercase username -> account, replaced rather than changed
+	file      string
+	mutex     *sync.Mutex
+	saveMutex *sync.Mutex // Keeps saves in order
+}
+
+// NewAccounts creates accounts which aren't saved.
+func NewAccounts() *Accounts {
+	return &Accounts{
+		logins:    make(map[string]*savedLogin),
+		mutex:     &sync.Mutex{},
+		saveMutex: &sync.Mutex{},
+	}
+}
+
+// LoadAccounts loads the accounts saved in the file, which is created when the
+// first account is made if it doesn't exist.
+func LoadAccounts(file string) (*Accounts, error) {
+	a := NewAccounts()
+	a.file = file
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load accounts %s: %w", file, err)
+	}
+
+	saved := []*savedLogin{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts %s: %w", file, err)
+	}
+
+	for _, login := range saved {
+		a.logins[login.Username] = login
+	}
+
+	return a, nil
+}
+
+// Authenticate checks the player's password. A name nobody has used yet gets
+// an account with the password, and true is returned.
+func (a *Accounts) Authenticate(username string, password string) (bool, error) {
+	for {
+		if login, ok := a.login(username); ok {
+			hash, err := pbkdf2.Key(sha256.New, password, login.Salt, login.Iterations, len(login.Hash))
+			if err != nil || subtle.ConstantTimeCompare(hash, login.Hash) != 1 {
+				return false, ErrorWrongPassword
+			}
+
+			return false, nil
+		}
+
+		login, err := newLogin(username, password, time.Now().UTC())
+		if err != nil {
+			return false, err
+		}
+
+		// Someone else may have made the account while the password was hashed,
+		// in which case the password is checked against theirs
+		a.mutex.Lock()
+		_, exists := a.logins[login.Username]
+		if !exists {
+			a.logins[login.Username] = login
+		}
+		a.mutex.Unlock()
+
+		if !exists {
+			return true, a.save()
+		}
+	}
+}
+
+// Exists checks whether the name has an account.
+func (a *Accounts) Exists(username string) bool {
+	_, ok := a.login(username)
+	return ok
+}
+
+// SetPassword sets the player's password, making their account if they don't
+// have one.
+func (a *Accounts) SetPassword(username string, password string) error {
+	created := time.Now().UTC()
+	if login, ok := a.login(username); ok {
+		created = login.Created
+	}
+
+	login, err := newLogin(username, password, created)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	a.logins[login.Username] = login
+	a.mutex.Unlock()
+
+	return a.save()
+}
+
+// login looks up the account for the name.
+func (a *Accounts) login(username string) (*savedLogin, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	login, ok := a.logins[strings.ToLower(username)]
+	return login, ok
+}
+
+// newLogin hashes the password for a new account, or a new password.
+func newLogin(username string, password string, created time.Time) (*savedLogin, error) {
+	if l := len(password); l < MinPasswordLength || l > MaxPasswordLength {
+		return nil, ErrorInvalidPassword
+	}
+
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to make salt: %w", err)
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return &savedLogin{
+		Username:   strings.ToLower(username),
+		Salt:       salt,
+		Hash:       hash,
+		Iterations: passwordIterations,
+		Created:    created,
+	}, nil
+}
+
+// save writes the accounts to their file, if they have one.
+func (a *Accounts) save() error {
+	if a.file == "" {
+		return nil
+	}
+
+	a.saveMutex.Lock()
+	defer a.saveMutex.Unlock()
+
+	a.mutex.Lock()
+	saved := []*savedLogin{}
+	for _, login := range a.logins {
+		saved = append(saved, login)
+	}
+	a.mutex.Unlock()
+
+	sort.Slice(saved, func(i, j int) bool {
+		return saved[i].Username < saved[j].Username
+	})
+
+	return writeJSON(a.file, saved)
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccounts(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "save", "accounts.json")
+
+	accounts, err := LoadAccounts(file)
+	assert.Nil(t, err)
+
+	_, err = accounts.Authenticate("alice", "short")
+	assert.ErrorIs(t, err, ErrorInvalidPassword)
+	assert.False(t, accounts.Exists("alice"))
+
+	// The first login makes the account
+	created, err := accounts.Authenticate("Alice", "secret1")
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.True(t, accounts.Exists("alice"))
+
+	created, err = accounts.Authenticate("alice", "secret1")
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	_, err = accounts.Authenticate("alice", "secret2")
+	assert.ErrorIs(t, err, ErrorWrongPassword)
+
+	assert.Nil(t, accounts.SetPassword("alice", "secret2"))
+
+	// Accounts are saved
+	loaded, err := LoadAccounts(file)
+	assert.Nil(t, err)
+
+	_, err = loaded.Authenticate("alice", "secret1")
+	assert.ErrorIs(t, err, ErrorWrongPassword)
+
+	created, err = loaded.Authenticate("ALICE", "secret2")
+	assert.Nil(t, err)
+	assert.False(t, created)
+}
+
+func TestAccountsCreatedOnce(t *testing.T) {
+	accounts := NewAccounts()
+
+	// Every login hashes the password before either account is made
+	results := make([]bool, 4)
+	wg := sync.WaitGroup{}
+
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			created, err := accounts.Authenticate("alice", "secret1")
+			assert.Nil(t, err)
+			results[i] = created
+		}()
+	}
+
+	wg.Wait()
+
+	made := 0
+	for _, created := range results {
+		if created {
+			made++
+		}
+	}
+
+	assert.Equal(t, 1, made)
+}
@@ -0,0 +1,329 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	VaultSize       = 10 // How many items a bank account's vault holds
+	BankHistorySize = 20 // Recent transactions kept in memory for each account
+)
+
+var ErrorVaultFull = errors.New("vault full")
+
+// Bank transaction actions
+const (
+	BankDeposit  = "deposit"
+	BankWithdraw = "withdraw"
+)
+
+// BankTransaction is a deposit or withdrawal, as written to the audit trail.
+type BankTransaction struct {
+	Time     time.Time `json:"time"`
+	Username string    `json:"username"`
+	Banker   string    `json:"banker"`
+	Action   string    `json:"action"`
+	Gold     int       `json:"gold,omitempty"`
+	Item     *Item     `json:"item,omitempty"` // With its id in the vault
+	Balance  int       `json:"balance"`        // Gold in the account afterwards
+}
+
+// Describe returns a summary of the transaction.
+func (tx BankTransaction) Describe() string {
+	what := fmt.Sprintf("%d gold", tx.Gold)
+	if tx.Item != nil {
+		what = fmt.Sprintf("%s (ID: %s)", tx.Item.Name, tx.Item.ID)
+	}
+
+	return fmt.Sprintf("%s %s %s with %s, balance %d gold", tx.Time.Format(time.DateTime), tx.Action, what, tx.Banker, tx.Balance)
+}
+
+// savedBankAccount is how a bank account is written to the bank file.
+type savedBankAccount struct {
+	Username string `json:"username"`
+	Gold     int    `json:"gold"`
+	Items    []Item `json:"items"`
+}
+
+// Bank keeps the players' gold and vaults, whichever banker they visit.
+// Accounts are kept by username, which only the player with its password can
+// log in as. When it has a file, every change is saved to it, and every
+// transaction is appended to the audit file. Each account's last transactions
+// are also kept in memory, so showing them doesn't read the audit file.
+type Bank struct {
+	accounts map[string]*Inventory        // Lowercase username -> account
+	history  map[string][]BankTransaction // Lowercase username -> recent transactions, oldest first
+	file     string
+	audit    string
+	mutex    *sync.Mutex
+}
+
+// NewBank creates a bank which isn't saved.
+func NewBank() *Bank {
+	return &Bank{
+		accounts: make(map[string]*Inventory),
+		history:  make(map[string][]BankTransaction),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// LoadBank loads the accounts saved in the file, which is created on the first
+// change if it doesn't exist. Transactions are appended to the audit file,
+// whose last few for each account are read back.
+func LoadBank(file string, audit string) (*Bank, error) {
+	b := NewBank()
+	b.file = file
+	b.audit = audit
+
+	if err := b.loadHistory(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank %s: %w", file, err)
+	}
+
+	saved := []savedBankAccount{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse bank %s: %w", file, err)
+	}
+
+	for _, s := range saved {
+		account := NewInventory()
+		account.Gold = s.Gold
+
+		for _, item := range s.Items {
+			account.ItemsMap[item.ID] = item
+		}
+
+		b.accounts[s.Username] = account
+	}
+
+	return b, nil
+}
+
+// DepositGold adds gold to the player's account, returning the new balance.
+func (b *Bank) DepositGold(username string, banker string, amount int) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account := b.account(username)
+	account.AddGold(amount)
+
+	b.record(BankTransaction{Username: username, Banker: banker, Action: BankDeposit, Gold: amount, Balance: account.GetGold()})
+	return account.GetGold()
+}
+
+// WithdrawGold takes gold out of the player's account, returning the new
+// balance.
+func (b *Bank) WithdrawGold(username string, banker string, amount int) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account := b.account(username)
+	if !account.TakeGold(amount) {
+		return account.GetGold(), ErrorNotEnoughGold
+	}
+
+	b.record(BankTransaction{Username: username, Banker: banker, Action: BankWithdraw, Gold: amount, Balance: account.GetGold()})
+	return account.GetGold(), nil
+}
+
+// DepositItem puts an item in the player's vault, returning it with its id in
+// the vault.
+func (b *Bank) DepositItem(username string, banker string, item Item) (Item, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account := b.account(username)
+	if len(account.GetItems()) >= VaultSize {
+		return item, ErrorVaultFull
+	}
+
+	item = account.Add(item)
+
+	b.record(BankTransaction{Username: username, Banker: banker, Action: BankDeposit, Item: &item, Balance: account.GetGold()})
+	return item, nil
+}
+
+// WithdrawItem takes an item out of the player's vault.
+func (b *Bank) WithdrawItem(username string, banker string, itemId string) (Item, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account := b.account(username)
+
+	item, ok := account.Remove(itemId)
+	if !ok {
+		return Item{}, ErrorItemNotFound
+	}
+
+	b.record(BankTransaction{Username: username, Banker: banker, Action: BankWithdraw, Item: &item, Balance: account.GetGold()})
+	return item, nil
+}
+
+// Account returns the gold and items in the player's account.
+func (b *Bank) Account(username string) (int, []Item) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account, ok := b.accounts[strings.ToLower(username)]
+	if !ok {
+		return 0, []Item{}
+	}
+
+	return account.GetGold(), account.GetItems()
+}
+
+// History returns the player's last transactions, up to the limit and
+// BankHistorySize, oldest first.
+func (b *Bank) History(username string, limit int) []BankTransaction {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	history := b.history[strings.ToLower(username)]
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	return append([]BankTransaction{}, history...)
+}
+
+// loadHistory reads each account's last transactions from the audit file.
+// Lines which can't be parsed, such as one cut short by a crash, are skipped.
+func (b *Bank) loadHistory() error {
+	if b.audit == "" {
+		return nil
+	}
+
+	f, err := os.Open(b.audit)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open bank audit %s: %w", b.audit, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		tx := BankTransaction{}
+		if err := json.Unmarshal(scanner.Bytes(), &tx); err != nil {
+			slog.Warn("Skipping bad bank audit line", "file", b.audit, "line", line, "error", err)
+			continue
+		}
+
+		b.remember(tx)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read bank audit %s: %w", b.audit, err)
+	}
+
+	return nil
+}
+
+// remember adds a transaction to its account's recent history, forgetting the
+// oldest once there are BankHistorySize. The caller must hold the lock.
+func (b *Bank) remember(tx BankTransaction) {
+	history := b.history[tx.Username]
+	if len(history) < BankHistorySize {
+		b.history[tx.Username] = append(history, tx)
+		return
+	}
+
+	copy(history, history[1:])
+	history[len(history)-1] = tx
+}
+
+// account returns the player's account, opening it if they don't have one.
+// The caller must hold the lock.
+func (b *Bank) account(username string) *Inventory {
+	username = strings.ToLower(username)
+
+	account, ok := b.accounts[username]
+	if !ok {
+		account = NewInventory()
+		b.accounts[username] = account
+	}
+
+	return account
+}
+
+// record saves the accounts after a transaction and appends it to the audit
+// file, if the bank has them. The caller must hold the lock.
+//
+// The whole bank file is rewritten for every transaction while the lock is
+// held, which is fine for the accounts of a single server. Should it grow
+// large, save a snapshot in the background and rely on the audit trail instead.
+func (b *Bank) record(tx BankTransaction) {
+	tx.Time = time.Now().UTC()
+	tx.Username = strings.ToLower(tx.Username)
+	b.remember(tx)
+
+	slog.Info("Bank transaction", "player", tx.Username, "banker", tx.Banker, "action", tx.Action, "gold", tx.Gold, "balance", tx.Balance)
+
+	if b.audit != "" {
+		if err := appendJSON(b.audit, tx); err != nil {
+			slog.Error("Failed to write bank audit", "file", b.audit, "error", err)
+		}
+	}
+
+	if b.file == "" {
+		return
+	}
+
+	saved := []savedBankAccount{}
+	for username, account := range b.accounts {
+		saved = append(saved, savedBankAccount{Username: username, Gold: account.GetGold(), Items: account.GetItems()})
+	}
+
+	sort.Slice(saved, func(i, j int) bool {
+		return saved[i].Username < saved[j].Username
+	})
+
+	if err := writeJSON(b.file, saved); err != nil {
+		slog.Error("Failed to save bank", "file", b.file, "error", err)
+	}
+}
+
+// appendJSON appends a value to a file as a line of JSON, creating the file and
+// its directory if needed.
+func appendJSON(file string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", file, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBank(t *testing.T) {
+	dir := t.TempDir()
+	file, audit := filepath.Join(dir, "save", "bank.json"), filepath.Join(dir, "save", "bank-audit.jsonl")
+
+	bank, err := LoadBank(file, audit)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 25, bank.DepositGold("Alice", "Beatrice", 25))
+
+	_, err = bank.WithdrawGold("alice", "Beatrice", 26)
+	assert.ErrorIs(t, err, ErrorNotEnoughGold)
+
+	balance, err := bank.WithdrawGold("alice", "Beatrice", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, balance)
+
+	coin, err := bank.DepositItem("alice", "Beatrice", Item{ID: "105", Name: "Coin", Type: Trinket, SellingPrice: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "101", coin.ID)
+
+	_, err = bank.WithdrawItem("alice", "Beatrice", "102")
+	assert.ErrorIs(t, err, ErrorItemNotFound)
+
+	// Vaults only hold so many items
+	for i := 1; i < VaultSize; i++ {
+		_, err = bank.DepositItem("alice", "Beatrice", Item{Name: fmt.Sprintf("Pebble %d", i), Type: Trinket})
+		assert.Nil(t, err)
+	}
+
+	_, err = bank.DepositItem("alice", "Beatrice", Item{Name: "Book", Type: Trinket})
+	assert.ErrorIs(t, err, ErrorVaultFull)
+
+	for i := 1; i < VaultSize; i++ {
+		_, err = bank.WithdrawItem("alice", "Beatrice", fmt.Sprintf("%d", 101+i))
+		assert.Nil(t, err)
+	}
+
+	// Accounts are saved
+	loaded, err := LoadBank(file, audit)
+	assert.Nil(t, err)
+
+	gold, items := loaded.Account("alice")
+	assert.Equal(t, 20, gold)
+	assert.Equal(t, []Item{coin}, items)
+
+	gold, items = loaded.Account("bob")
+	assert.Equal(t, 0, gold)
+	assert.Empty(t, items)
+
+	// Every transaction is in the audit trail
+	data, err := os.ReadFile(audit)
+	assert.Nil(t, err)
+
+	all := []BankTransaction{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		tx := BankTransaction{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &tx))
+		all = append(all, tx)
+	}
+
+	assert.Len(t, all, 3+2*(VaultSize-1))
+	assert.Equal(t, BankTransaction{Time: all[0].Time, Username: "alice", Banker: "Beatrice", Action: BankDeposit, Gold: 25, Balance: 25}, all[0])
+	assert.Equal(t, BankTransaction{Time: all[2].Time, Username: "alice", Banker: "Beatrice", Action: BankDeposit, Item: &coin, Balance: 20}, all[2])
+
+	// The last few are kept in memory, and read back from the audit trail
+	assert.Equal(t, all[len(all)-3:], loaded.History("ALICE", 3))
+	assert.Equal(t, all[len(all)-BankHistorySize:], loaded.History("alice", 100))
+	assert.Equal(t, loaded.History("alice", 100), bank.History("alice", 100))
+	assert.Empty(t, loaded.History("bob", 100))
+
+	// A line cut short by a crash doesn't stop the bank loading
+	f, err := os.OpenFile(audit, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.Nil(t, err)
+	_, err = f.WriteString(`{"time":"2026-`)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	loaded, err = LoadBank(file, audit)
+	assert.Nil(t, err)
+	assert.Equal(t, all[len(all)-3:], loaded.History("alice", 3))
+}
+
+func TestBankerNpc(t *testing.T) {
+	src := NewRoom(2, "Vault", "Iron doors.")
+	src.RawNpcs = []any{map[string]any{"name": "Beatrice", "type": NpcBanker, "greeting": "Your gold is safe with me."}}
+
+	room := NewRoom(2, "Vault", "Iron doors.")
+	room.Copy(src)
+
+	npc, ok := room.GetNpcByName("Beatrice")
+	assert.True(t, ok)
+
+	banker, ok := npc.(*Banker)
+	assert.True(t, ok)
+	assert.Equal(t, "2-0", banker.ID)
+	assert.Equal(t, "Beatrice the banker", banker.Description())
+	assert.Equal(t, "Your gold is safe with me.", banker.Greet(nil))
+}
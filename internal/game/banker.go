@@ -0,0 +1,60 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Banker represents a banker in the game world. Bankers look after the
+// player's bank account, which is the same whichever banker they visit.
+type Banker struct {
+	NpcData
+}
+
+// NewBanker creates a new Banker instance.
+func NewBanker(id string) *Banker {
+	b := &Banker{}
+
+	b.ID = id
+	b.Type = NpcBanker
+
+	return b
+}
+
+// GetData returns the NPC data of the banker.
+func (b *Banker) GetData() *NpcData {
+	return &b.NpcData
+}
+
+// Greet sends a greeting message to the player.
+func (b *Banker) Greet(player *Player) string {
+	return b.Greeting
+}
+
+// Description returns a description of the banker.
+func (b *Banker) Description() string {
+	return fmt.Sprintf("%s the banker", b.Name)
+}
+
+// Convert converts raw NPC data into a Banker instance.
+func (b *Banker) Convert(rawNpc map[string]any) error {
+	if rawNpc["type"] != NpcBanker {
+		return fmt.Errorf("invalid NPC data format")
+	}
+
+	jsonBytes, err := json.Marshal(rawNpc)
+	if err != nil {
+		return fmt.Errorf("error marshaling NPC data: %w", err)
+	}
+
+	npcData := NewBanker("")
+
+	if err = json.Unmarshal(jsonBytes, npcData); err != nil {
+		return fmt.Errorf("error unmarshaling NPC data: %w", err)
+	}
+
+	b.Name = npcData.Name
+	b.Greeting = npcData.Greeting
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package game
+
+import (
+	"log/slog"
+	"sync"
+)
+
+const (
+	DefaultAsyncQueueSize = 64 // Events an async subscriber can fall behind by before they're dropped
+)
+
+// subscription is a handler registered with the event bus.
+type subscription struct {
+	id      uint64
+	name    string // Event name, empty for every event
+	handler func(e DomainEvent)
+	filter  func(e DomainEvent) bool
+	async   bool
+	block   bool // Publishers wait for room in a full queue instead of dropping the event
+	size    int  // Events an async subscriber can fall behind by
+	queue   chan DomainEvent
+	done    chan struct{} // Closed once an async subscriber handled its last event
+}
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(sub *subscription)
+
+// WithAsync delivers events on the subscriber's own goroutine instead of the
+// publisher's. Events are delivered in order, events which would overflow the
+// subscriber's queue are dropped.
+func WithAsync() SubscribeOption {
+	return func(sub *subscription) {
+		sub.async = true
+	}
+}
+
+// WithBlocking makes publishers wait while an async subscriber's queue is full
+// rather than dropping the event, for subscribers which can't miss any. The
+// handler mustn't publish, or it could wait on itself.
+func WithBlocking() SubscribeOption {
+	return func(sub *subscription) {
+		sub.block = true
+	}
+}
+
+// WithQueueSize sets how many events an async subscriber can fall behind by
+// before they're dropped, DefaultAsyncQueueSize by default.
+func WithQueueSize(size int) SubscribeOption {
+	return func(sub *subscription) {
+		if size > 0 {
+			sub.size = size
+		}
+	}
+}
+
+// WithFilter only delivers events the filter accepts. The filter runs on the
+// publisher's goroutine.
+func WithFilter[T DomainEvent](filter func(e T) bool) SubscribeOption {
+	return func(sub *subscription) {
+		sub.filter = func(e DomainEvent) bool {
+			typed, ok := e.(T)
+			return ok && filter(typed)
+		}
+	}
+}
+
+// EventBus delivers domain events to the handlers subscribed to them.
+type EventBus struct {
+	subscriptions map[string][]*subscription
+	nextId        uint64
+	mutex         *sync.RWMutex
+}
+
+// NewEventBus creates a new EventBus instance.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscriptions: make(map[string][]*subscription),
+		mutex:         &sync.RWMutex{},
+	}
+}
+
+// Subscribe registers a handler for events of type T. The returned function
+// removes the subscription, waiting for an async subscriber to handle the
+// events already queued.
+func Subscribe[T DomainEvent](bus *EventBus, handler func(e T), options ...SubscribeOption) func() {
+	var zero T
+
+	return bus.subscribe(zero.EventName(), func(e DomainEvent) {
+		if typed, ok := e.(T); ok {
+			handler(typed)
+		}
+	}, options...)
+}
+
+// SubscribeAll registers a handler for every event, e.g. for logging or
+// metrics. The returned function removes the subscription, like Subscribe's.
+func (bus *EventBus) SubscribeAll(handler func(e DomainEvent), options ...SubscribeOption) func() {
+	return bus.subscribe("", handler, options...)
+}
+
+// subscribe registers a handler for the named event.
+func (bus *EventBus) subscribe(name string, handler func(e DomainEvent), options ...SubscribeOption) func() {
+	sub := &subscription{name: name, handler: handler, size: DefaultAsyncQueueSize}
+
+	for _, option := range options {
+		option(sub)
+	}
+
+	if sub.async {
+		sub.queue = make(chan DomainEvent, sub.size)
+		sub.done = make(chan struct{})
+
+		go func() {
+			defer close(sub.done)
+
+			for e := range sub.queue {
+				deliver(sub, e)
+			}
+		}()
+	}
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	bus.nextId++
+	sub.id = bus.nextId
+	bus.subscriptions[name] = append(bus.subscriptions[name], sub)
+
+	return func() {
+		bus.unsubscribe(sub)
+	}
+}
+
+// unsubscribe removes a subscription. An async subscription's goroutine
+// handles the events already queued before it stops.
+func (bus *EventBus) unsubscribe(sub *subscription) {
+	bus.mutex.Lock()
+
+	subs := bus.subscriptions[sub.name]
+	for i, existing := range subs {
+		if existing.id != sub.id {
+			continue
+		}
+
+		bus.subscriptions[sub.name] = append(subs[:i:i], subs[i+1:]...)
+
+		if sub.async {
+			close(sub.queue)
+		}
+		break
+	}
+
+	bus.mutex.Unlock()
+
+	if sub.async {
+		<-sub.done
+	}
+}
+
+// Publish delivers an event to its subscribers. Sync handlers have run by the
+// time Publish returns.
+func (bus *EventBus) Publish(e DomainEvent) {
+	bus.mutex.RLock()
+	subs := append([]*subscription{}, bus.subscriptions[e.EventName()]...)
+	subs = append(subs, bus.subscriptions[""]...)
+
+	// Queue async events while holding the lock, so their queues can't be closed
+	for _, sub := range subs {
+		if !sub.async || (sub.filter != nil && !sub.filter(e)) {
+			continue
+		}
+
+		if sub.block {
+			sub.queue <- e
+			continue
+		}
+
+		select {
+		case sub.queue <- e:
+		default:
+			slog.Warn("Dropped event for slow subscriber", "event", e.EventName())
+		}
+	}
+	bus.mutex.RUnlock()
+
+	for _, sub := range subs {
+		if sub.async || (sub.filter != nil && !sub.filter(e)) {
+			continue
+		}
+
+		deliver(sub, e)
+	}
+}
+
+// deliver calls the subscription's handler, a panicking handler is logged
+// rather than taking down the publisher.
+func deliver(sub *subscription, e DomainEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Event handler panicked", "event", e.EventName(), "panic", r)
+		}
+	}()
+
+	sub.handler(e)
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBusSubscribe(t *testing.T) {
+	bus := NewEventBus()
+	alice := NewPlayer("alice", "Alice")
+
+	said := []string{}
+	unsubscribe := Subscribe(bus, func(e ChatSaid) {
+		said = append(said, e.Text)
+	})
+
+	entered := []int{}
+	Subscribe(bus, func(e PlayerEntered) {
+		entered = append(entered, e.RoomId)
+	}, WithFilter(func(e PlayerEntered) bool { return e.RoomId == 2 }))
+
+	all := []string{}
+	bus.SubscribeAll(func(e DomainEvent) {
+		all = append(all, e.EventName())
+	})
+
+	Subscribe(bus, func(e ItemSold) {
+		panic("handler failed")
+	})
+
+	bus.Publish(ChatSaid{Player: alice, RoomId: 1, Text: "hello"})
+	bus.Publish(PlayerEntered{Player: alice, RoomId: 1})
+	bus.Publish(PlayerEntered{Player: alice, RoomId: 2, FromRoomId: 1})
+	bus.Publish(ItemSold{Player: alice, RoomId: 2})
+
+	unsubscribe()
+	bus.Publish(ChatSaid{Player: alice, RoomId: 1, Text: "anyone?"})
+
+	assert.Equal(t, []string{"hello"}, said)
+	assert.Equal(t, []int{2}, entered)
+	assert.Equal(t, []string{EventChatSaid, EventPlayerEntered, EventPlayerEntered, EventItemSold, EventChatSaid}, all)
+}
+
+func TestEventBusAsync(t *testing.T) {
+	bus := NewEventBus()
+	alice := NewPlayer("alice", "Alice")
+
+	received := make(chan string, 10)
+	unsubscribe := Subscribe(bus, func(e ItemPickedUp) {
+		received <- e.Item.Name
+	}, WithAsync())
+
+	bus.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: Item{Name: "Coin"}})
+	bus.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: Item{Name: "Key"}})
+
+	for _, expected := range []string{"Coin", "Key"} {
+		select {
+		case name := <-received:
+			assert.Equal(t, expected, name)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for async event")
+		}
+	}
+
+	unsubscribe()
+	bus.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: Item{Name: "Book"}})
+
+	select {
+	case name := <-received:
+		t.Fatalf("unexpected event after unsubscribing: %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEventBusAsyncQueueSize(t *testing.T) {
+	bus := NewEventBus()
+	alice := NewPlayer("alice", "Alice")
+
+	release := make(chan struct{})
+	received := []string{}
+
+	unsubscribe := Subscribe(bus, func(e ItemPickedUp) {
+		<-release
+		received = append(received, e.Item.Name)
+	}, WithAsync(), WithQueueSize(2))
+
+	// One event is being handled and two are queued, so the last is dropped
+	for _, name := range []string{"Coin", "Key", "Book", "Quill"} {
+		bus.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: Item{Name: name}})
+		if name == "Coin" {
+			assert.Eventually(t, func() bool {
+				return len(bus.subscriptions[EventItemPickedUp][0].queue) == 0
+			}, time.Second, time.Millisecond)
+		}
+	}
+
+	close(release)
+
+	// Unsubscribing waits for the queued events to be handled
+	unsubscribe()
+	assert.Equal(t, []string{"Coin", "Key", "Book"}, received)
+}
+
+func TestEventBusBlocking(t *testing.T) {
+	bus := NewEventBus()
+	alice := NewPlayer("alice", "Alice")
+
+	release := make(chan struct{})
+	received := []string{}
+
+	unsubscribe := Subscribe(bus, func(e ItemPickedUp) {
+		<-release
+		received = append(received, e.Item.Name)
+	}, WithAsync(), WithBlocking(), WithQueueSize(1))
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+
+		for _, name := range []string{"Coin", "Key", "Book", "Quill"} {
+			bus.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: Item{Name: name}})
+		}
+	}()
+
+	// The publisher waits for the subscriber rather than dropping events
+	select {
+	case <-published:
+		t.Fatal("publishing didn't wait for the subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-published
+
+	unsubscribe()
+	assert.Equal(t, []string{"Coin", "Key", "Book", "Quill"}, received)
+}
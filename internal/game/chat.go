@@ -0,0 +1,350 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultChannelHistory  = 20   // Messages a channel keeps for players who join it
+	DefaultTellMailboxSize = 20   // Tells kept for a player while they're offline
+	DefaultTellMailboxes   = 1000 // Offline players who can have tells waiting at once
+)
+
+// DefaultChannels are the global chat channels every game has. Buying and
+// selling goes on market, since trade is the command for trading with a player.
+var DefaultChannels = []string{"ooc", "newbie", "market"}
+
+var (
+	ErrorUnknownChannel = errors.New("unknown channel")
+	ErrorNotInChannel   = errors.New("not in channel")
+	ErrorMailboxFull    = errors.New("mailbox full")
+	ErrorTooManyTells   = errors.New("too many players have tells waiting")
+)
+
+// ChatMessage is a message sent on a channel or told to a player.
+type ChatMessage struct {
+	Channel  string    `json:"channel,omitempty"`
+	Talker   string    `json:"talker"`   // Display name of the player who sent it
+	Username string    `json:"username"` // Username of the player who sent it, for replying
+	Text     string    `json:"text"`
+	Time     time.Time `json:"time"`
+}
+
+// channel is a global chat channel. Members are keyed by username and map to
+// whether they muted the channel.
+type channel struct {
+	name    string
+	members map[string]bool
+	history []ChatMessage
+}
+
+// Chat keeps track of global channels, who replies to whom, who ignores whom
+// and the tells waiting for offline players. Players are identified by
+// lowercase username so their channels and tells carry over between logins.
+// When it has a file, the waiting tells are saved to it on every change.
+type Chat struct {
+	channels    map[string]*channel
+	replyTo     map[string]string          // Username -> username of the last player to tell them something
+	mailboxes   map[string][]ChatMessage   // Username -> tells sent while they were offline
+	ignores     map[string]map[string]bool // Username -> usernames they ignore
+	historySize int
+	mailboxSize int
+	maxMailbox  int // Players who can have tells waiting at once
+	file        string
+	mutex       *sync.Mutex
+}
+
+// NewChat creates a new Chat instance with the given channels.
+func NewChat(channels ...string) *Chat {
+	c := &Chat{
+		channels:    make(map[string]*channel),
+		replyTo:     make(map[string]string),
+		mailboxes:   make(map[string][]ChatMessage),
+		ignores:     make(map[string]map[string]bool),
+		historySize: DefaultChannelHistory,
+		mailboxSize: DefaultTellMailboxSize,
+		maxMailbox:  DefaultTellMailboxes,
+		mutex:       &sync.Mutex{},
+	}
+
+	for _, name := range channels {
+		name = strings.ToLower(name)
+		c.channels[name] = &channel{name: name, members: make(map[string]bool)}
+	}
+
+	return c
+}
+
+// LoadChat creates chat with the given channels and the tells saved in the
+// file, which is created when a tell is first stored if it doesn't exist.
+func LoadChat(file string, channels ...string) (*Chat, error) {
+	c := NewChat(channels...)
+	c.file = file
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tells %s: %w", file, err)
+	}
+
+	if err := json.Unmarshal(data, &c.mailboxes); err != nil {
+		return nil, fmt.Errorf("failed to parse tells %s: %w", file, err)
+	}
+
+	if c.mailboxes == nil {
+		c.mailboxes = make(map[string][]ChatMessage)
+	}
+
+	return c, nil
+}
+
+// Channels returns the names of the channels, sorted.
+func (c *Chat) Channels() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	names := make([]string, 0, len(c.channels))
+	for name := range c.channels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Join adds the player to a channel and returns its recent messages, oldest
+// first, leaving out those from players they ignore. Joining a channel the
+// player muted unmutes it.
+func (c *Chat) Join(username string, name string) ([]ChatMessage, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, ok := c.channels[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrorUnknownChannel
+	}
+
+	username = strings.ToLower(username)
+	ch.members[username] = false
+
+	history := []ChatMessage{}
+	for _, message := range ch.history {
+		if !c.ignores[username][strings.ToLower(message.Username)] {
+			history = append(history, message)
+		}
+	}
+
+	return history, nil
+}
+
+// Leave removes the player from a channel.
+func (c *Chat) Leave(username string, name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, err := c.memberOf(username, name)
+	if err != nil {
+		return err
+	}
+
+	delete(ch.members, strings.ToLower(username))
+	return nil
+}
+
+// Mute stops or resumes delivering a channel's messages to a player, who
+// stays a member and can still talk on it.
+func (c *Chat) Mute(username string, name string, muted bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, err := c.memberOf(username, name)
+	if err != nil {
+		return err
+	}
+
+	ch.members[strings.ToLower(username)] = muted
+	return nil
+}
+
+// Memberships returns the channels a player is a member of, mapped to whether
+// they muted it.
+func (c *Chat) Memberships(username string) map[string]bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	memberships := make(map[string]bool)
+	for name, ch := range c.channels {
+		if muted, ok := ch.members[strings.ToLower(username)]; ok {
+			memberships[name] = muted
+		}
+	}
+
+	return memberships
+}
+
+// Post adds a message from a member to a channel's history and returns the
+// usernames of the members who should receive it, including the talker
+// unless they muted the channel.
+func (c *Chat) Post(username string, message ChatMessage) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, err := c.memberOf(username, message.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Channel = ch.name
+
+	ch.history = append(ch.history, message)
+	if len(ch.history) > c.historySize {
+		ch.history = ch.history[len(ch.history)-c.historySize:]
+	}
+
+	listeners := []string{}
+	for member, muted := range ch.members {
+		if !muted {
+			listeners = append(listeners, member)
+		}
+	}
+
+	sort.Strings(listeners)
+	return listeners, nil
+}
+
+// SetReplyTo records who last told the player something.
+func (c *Chat) SetReplyTo(username string, from string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.replyTo[strings.ToLower(username)] = strings.ToLower(from)
+}
+
+// ReplyTo returns the username of the last player to tell the player something.
+func (c *Chat) ReplyTo(username string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	from, ok := c.replyTo[strings.ToLower(username)]
+	return from, ok
+}
+
+// StoreTell keeps a tell for an offline player until they next log in. The
+// caller checks the player has an account, so tells aren't kept for names
+// nobody owns.
+func (c *Chat) StoreTell(username string, message ChatMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	username = strings.ToLower(username)
+
+	mailbox, ok := c.mailboxes[username]
+	if !ok && len(c.mailboxes) >= c.maxMailbox {
+		return ErrorTooManyTells
+	}
+
+	if len(mailbox) >= c.mailboxSize {
+		return ErrorMailboxFull
+	}
+
+	c.mailboxes[username] = append(mailbox, message)
+	c.save()
+
+	return nil
+}
+
+// TakeTells removes and returns the tells stored for a player, oldest first.
+func (c *Chat) TakeTells(username string) []ChatMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	username = strings.ToLower(username)
+
+	tells, ok := c.mailboxes[username]
+	if ok {
+		delete(c.mailboxes, username)
+		c.save()
+	}
+
+	return tells
+}
+
+// Ignore hides, or with on false shows again, everything another player says
+// from the player.
+func (c *Chat) Ignore(username string, other string, on bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	username, other = strings.ToLower(username), strings.ToLower(other)
+
+	if !on {
+		delete(c.ignores[username], other)
+		return
+	}
+
+	if c.ignores[username] == nil {
+		c.ignores[username] = make(map[string]bool)
+	}
+
+	c.ignores[username][other] = true
+}
+
+// IsIgnoring checks if the player ignores another player.
+func (c *Chat) IsIgnoring(username string, other string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.ignores[strings.ToLower(username)][strings.ToLower(other)]
+}
+
+// Ignored returns the usernames the player ignores, sorted.
+func (c *Chat) Ignored(username string) []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ignored := []string{}
+	for other := range c.ignores[strings.ToLower(username)] {
+		ignored = append(ignored, other)
+	}
+
+	sort.Strings(ignored)
+	return ignored
+}
+
+// save writes the waiting tells to the file, if there is one. The caller must
+// hold the lock.
+func (c *Chat) save() {
+	if c.file == "" {
+		return
+	}
+
+	if err := writeJSON(c.file, c.mailboxes); err != nil {
+		slog.Error("Failed to save tells", "file", c.file, "error", err)
+	}
+}
+
+// memberOf returns a channel the player is a member of. The caller must hold
+// the lock.
+func (c *Chat) memberOf(username string, name string) (*channel, error) {
+	ch, ok := c.channels[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrorUnknownChannel
+	}
+
+	if _, ok := ch.members[strings.ToLower(username)]; !ok {
+		return nil, ErrorNotInChannel
+	}
+
+	return ch, nil
+}
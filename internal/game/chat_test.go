@@ -0,0 +1,109 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatChannels(t *testing.T) {
+	chat := NewChat(DefaultChannels...)
+	chat.historySize = 2
+
+	assert.Equal(t, []string{"market", "newbie", "ooc"}, chat.Channels())
+
+	_, err := chat.Join("alice", "gossip")
+	assert.ErrorIs(t, err, ErrorUnknownChannel)
+
+	_, err = chat.Post("alice", ChatMessage{Channel: "ooc", Text: "hi"})
+	assert.ErrorIs(t, err, ErrorNotInChannel)
+
+	history, err := chat.Join("Alice", "OOC")
+	assert.Nil(t, err)
+	assert.Empty(t, history)
+
+	_, err = chat.Join("bob", "ooc")
+	assert.Nil(t, err)
+
+	for _, text := range []string{"one", "two", "three"} {
+		listeners, err := chat.Post("alice", ChatMessage{Channel: "ooc", Talker: "Alice", Username: "alice", Text: text})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"alice", "bob"}, listeners)
+	}
+
+	// Muted members can still talk, but don't hear the channel
+	assert.Nil(t, chat.Mute("bob", "ooc", true))
+	assert.Equal(t, map[string]bool{"ooc": true}, chat.Memberships("bob"))
+
+	listeners, err := chat.Post("bob", ChatMessage{Channel: "ooc", Talker: "Bob", Username: "Bob", Text: "four"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice"}, listeners)
+
+	// Only the most recent messages are kept
+	history, err = chat.Join("carol", "ooc")
+	assert.Nil(t, err)
+	assert.Equal(t, []ChatMessage{
+		{Channel: "ooc", Talker: "Alice", Username: "alice", Text: "three"},
+		{Channel: "ooc", Talker: "Bob", Username: "Bob", Text: "four"},
+	}, history)
+
+	// Messages from ignored players are left out of the history
+	chat.Ignore("dave", "Bob", true)
+
+	history, err = chat.Join("dave", "ooc")
+	assert.Nil(t, err)
+	assert.Equal(t, []ChatMessage{{Channel: "ooc", Talker: "Alice", Username: "alice", Text: "three"}}, history)
+
+	assert.Nil(t, chat.Leave("alice", "ooc"))
+	assert.ErrorIs(t, chat.Leave("alice", "ooc"), ErrorNotInChannel)
+	assert.Empty(t, chat.Memberships("alice"))
+}
+
+func TestChatTells(t *testing.T) {
+	chat := NewChat()
+	chat.mailboxSize = 2
+
+	_, ok := chat.ReplyTo("bob")
+	assert.False(t, ok)
+
+	chat.SetReplyTo("Bob", "Alice")
+	from, ok := chat.ReplyTo("bob")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", from)
+
+	assert.Nil(t, chat.StoreTell("Bob", ChatMessage{Talker: "Alice", Text: "one"}))
+	assert.Nil(t, chat.StoreTell("bob", ChatMessage{Talker: "Carol", Text: "two"}))
+	assert.ErrorIs(t, chat.StoreTell("bob", ChatMessage{Talker: "Alice", Text: "three"}), ErrorMailboxFull)
+
+	assert.Equal(t, []ChatMessage{{Talker: "Alice", Text: "one"}, {Talker: "Carol", Text: "two"}}, chat.TakeTells("BOB"))
+	assert.Empty(t, chat.TakeTells("bob"))
+}
+
+func TestChatTellsSaved(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "save", "tells.json")
+
+	chat, err := LoadChat(file)
+	assert.Nil(t, err)
+	chat.maxMailbox = 2
+
+	sent := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Nil(t, chat.StoreTell("bob", ChatMessage{Talker: "Alice", Username: "alice", Text: "one", Time: sent}))
+	assert.Nil(t, chat.StoreTell("carol", ChatMessage{Talker: "Alice", Username: "alice", Text: "two", Time: sent}))
+
+	// Only so many players can have tells waiting
+	assert.ErrorIs(t, chat.StoreTell("dave", ChatMessage{Talker: "Alice", Text: "three"}), ErrorTooManyTells)
+	assert.Nil(t, chat.StoreTell("carol", ChatMessage{Talker: "Alice", Username: "alice", Text: "four", Time: sent}))
+
+	// Waiting tells survive a restart, and taking them saves too
+	loaded, err := LoadChat(file)
+	assert.Nil(t, err)
+	assert.Equal(t, []ChatMessage{{Talker: "Alice", Username: "alice", Text: "one", Time: sent}}, loaded.TakeTells("bob"))
+
+	loaded, err = LoadChat(file)
+	assert.Nil(t, err)
+	assert.Empty(t, loaded.TakeTells("bob"))
+	assert.Len(t, loaded.TakeTells("carol"), 2)
+}
@@ -0,0 +1,28 @@
+package game
+
+import "context"
+
+type MessageType string
+
+// Types of messages written to a connection
+const (
+	MessageOutput  MessageType = "output"  // Regular text output
+	MessageEvent   MessageType = "event"   // JSON encoded event
+	MessagePrompt  MessageType = "prompt"  // Input prompt
+	MessageError   MessageType = "error"   // Error text
+	MessageSession MessageType = "session" // Resume token for the session
+)
+
+// Connection represents the transport a player is connected through. It allows
+// WebTransport, telnet, websockets or in-memory test connections to be used
+// interchangeably by the session manager and command runner.
+type Connection interface {
+	// WriteMessage writes a typed message to the remote client.
+	WriteMessage(typ MessageType, message []byte) error
+	// Close closes the connection, sending the reason to the client if supported.
+	Close(reason string) error
+	// RemoteAddr returns the address of the remote client.
+	RemoteAddr() string
+	// Context returns a context which is cancelled once the connection closes.
+	Context() context.Context
+}
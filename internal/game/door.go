@@ -7,6 +7,25 @@ type Door struct {
 	MoveCommand string `yaml:"moveCommand"` // Command to move through the door
 	IsLocked    bool   `yaml:"isLocked"`    // Is the door locked?
 	RoomId      int    `yaml:"roomId"`      // The room this door leads to
+	Key         string `yaml:"key"`         // Name of the key item which unlocks the door
+
+	Restriction *DoorRestriction `yaml:"restriction"` // Who may pass, anyone when nil
+}
+
+// DoorRestriction limits who can pass through a door, such as the way into a
+// guild hall.
+type DoorRestriction struct {
+	GuildId int `yaml:"guildId"` // Only members of the guild with the id may pass
+}
+
+// Allows checks if a player in the guild with the id, 0 for none, may pass
+// through the door.
+func (door Door) Allows(guildId int) bool {
+	if door.Restriction == nil || door.Restriction.GuildId == 0 {
+		return true
+	}
+
+	return door.Restriction.GuildId == guildId
 }
 
 // String returns the name of the door
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTickInterval = 2 * time.Second // How often vitals and presence are pushed
+)
+
+// Ephemeral packages are high-frequency and loss-tolerant, so they may be
+// delivered over an unreliable channel.
+const (
+	OOBCommTyping  = "Comm.Typing"
+	OOBRoomPlayers = "Room.Players"
+)
+
+// EphemeralConnection is implemented by connections with an unreliable channel,
+// such as WebTransport datagrams. Connections without one fall back to
+// WriteOutOfBand.
+type EphemeralConnection interface {
+	OutOfBandConnection
+
+	// WriteEphemeral writes a package and its JSON data to the client. Delivery
+	// isn't guaranteed.
+	WriteEphemeral(pkg string, data []byte) error
+}
+
+// TypingData is the Comm.Typing payload.
+type TypingData struct {
+	Talker string `json:"talker"`
+}
+
+// RoomPlayersData is the Room.Players payload. Players lists everyone else in the room.
+type RoomPlayersData struct {
+	ID      int      `json:"num"`
+	Players []string `json:"players"`
+}
+
+// SendEphemeral sends a loss-tolerant package to the player if they subscribed to it.
+func (p *Player) SendEphemeral(pkg string, data any) error {
+	current, ob := p.connection()
+
+	conn, ok := current.(OutOfBandConnection)
+	if !ok || !conn.IsSubscribed(pkg) {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s: %w", pkg, err)
+	}
+
+	if ob != nil {
+		return ob.enqueue(outboxItem{kind: outboxEphemeral, pkg: pkg, data: payload})
+	}
+
+	if ephemeral, ok := conn.(EphemeralConnection); ok {
+		return ephemeral.WriteEphemeral(pkg, payload)
+	}
+
+	return conn.WriteOutOfBand(pkg, payload)
+}
+
+// PlayerTyping lets everyone else in the player's room know they're typing.
+func (g Game) PlayerTyping(ps *Player) {
+	for _, other := range g.Sm.GetPlayersInRoom(ps.RoomId(), ps.GetUUID()) {
+		if err := other.SendEphemeral(OOBCommTyping, TypingData{Talker: ps.DisplayName}); err != nil {
+			slog.Error("failed to send ephemeral data", "player", other.DisplayName, "package", OOBCommTyping, "error", err)
+		}
+	}
+}
+
+// Tick pushes vitals and room presence to every active player. Since these are
+// sent on every tick, a lost update is corrected by the next one.
+func (g Game) Tick() {
+	for _, ps := range g.Sm.GetActivePlayers() {
+		vitals := VitalsData{
+			Health:    ps.Health,
+			MaxHealth: ps.MaxHealth,
+			Gold:      ps.Inventory.GetGold(),
+		}
+
+		if err := ps.SendEphemeral(OOBCharVitals, vitals); err != nil {
+			slog.Error("failed to send ephemeral data", "player", ps.DisplayName, "package", OOBCharVitals, "error", err)
+			continue
+		}
+
+		roomId := ps.RoomId()
+
+		presence := RoomPlayersData{ID: roomId, Players: []string{}}
+		for _, other := range g.Sm.GetPlayersInRoom(roomId, ps.GetUUID()) {
+			presence.Players = append(presence.Players, other.DisplayName)
+		}
+
+		sort.Strings(presence.Players)
+
+		if err := ps.SendEphemeral(OOBRoomPlayers, presence); err != nil {
+			slog.Error("failed to send ephemeral data", "player", ps.DisplayName, "package", OOBRoomPlayers, "error", err)
+		}
+	}
+}
+
+// StartTicker calls Tick every DefaultTickInterval until the context is cancelled.
+func (g Game) StartTicker(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(DefaultTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.Tick()
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ephemeralConnection is an in-memory EphemeralConnection.
+type ephemeralConnection struct {
+	*oobConnection
+	datagrams []string
+}
+
+func (c *ephemeralConnection) WriteEphemeral(pkg string, data []byte) error {
+	c.datagrams = append(c.datagrams, pkg+" "+string(data))
+	return nil
+}
+
+func TestEphemeral(t *testing.T) {
+	sm := NewSessionManager(3)
+	g := NewGame(NewWorld())
+	g.Sm = sm
+
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+	carol := NewPlayer("carol", "Carol")
+	carol.SetRoomId(2)
+
+	aliceConn := &ephemeralConnection{oobConnection: &oobConnection{memoryConnection: newMemoryConnection()}}
+	bobConn := &oobConnection{memoryConnection: newMemoryConnection()}
+	carolConn := &oobConnection{memoryConnection: newMemoryConnection()}
+
+	for player, conn := range map[*Player]Connection{alice: aliceConn, bob: bobConn, carol: carolConn} {
+		assert.Nil(t, sm.Register(player))
+		_, err := sm.Connect(player.GetUUID(), conn)
+		assert.Nil(t, err)
+	}
+
+	// Only players in the same room are told, and connections without an
+	// unreliable channel fall back to out-of-band packages
+	g.PlayerTyping(alice)
+	assert.Empty(t, aliceConn.datagrams)
+	assert.Equal(t, []string{`Comm.Typing {"talker":"Alice"}`}, bobConn.packages)
+	assert.Empty(t, carolConn.packages)
+
+	g.PlayerTyping(bob)
+	assert.Equal(t, []string{`Comm.Typing {"talker":"Bob"}`}, aliceConn.datagrams)
+	assert.Empty(t, aliceConn.packages)
+
+	aliceConn.datagrams = nil
+	g.Tick()
+	assert.Equal(t, []string{
+		`Char.Vitals {"hp":100,"maxhp":100,"gold":0}`,
+		`Room.Players {"num":1,"players":["Bob"]}`,
+	}, aliceConn.datagrams)
+	assert.Contains(t, carolConn.packages, `Room.Players {"num":2,"players":[]}`)
+}
@@ -0,0 +1,153 @@
+package game
+
+// Domain event names
+const (
+	EventPlayerEntered = "PlayerEntered"
+	EventPlayerLeft    = "PlayerLeft"
+	EventItemPickedUp  = "ItemPickedUp"
+	EventItemSold      = "ItemSold"
+	EventDoorUnlocked  = "DoorUnlocked"
+	EventChatSaid      = "ChatSaid"
+	EventChannelSaid   = "ChannelSaid"
+	EventEmoted        = "Emoted"
+	EventGoldGiven     = "GoldGiven"
+	EventBanked        = "Banked"
+	EventTraded        = "Traded"
+)
+
+// DomainEvent is something which happened in the game world, published on the
+// game's event bus.
+type DomainEvent interface {
+	EventName() string
+}
+
+// PlayerEntered is published when a player enters a room. FromRoomId is 0 when
+// the player just joined the game. Direction is the door they came through, if
+// the room has one leading back.
+type PlayerEntered struct {
+	Player     *Player
+	RoomId     int
+	FromRoomId int
+	Direction  string
+}
+
+// EventName returns the name of the event.
+func (e PlayerEntered) EventName() string { return EventPlayerEntered }
+
+// PlayerLeft is published when a player leaves a room. ToRoomId is 0 when the
+// player left the game. Direction is the door they left through.
+type PlayerLeft struct {
+	Player    *Player
+	RoomId    int
+	ToRoomId  int
+	Direction string
+}
+
+// EventName returns the name of the event.
+func (e PlayerLeft) EventName() string { return EventPlayerLeft }
+
+// ItemPickedUp is published when a player picks an item up from a room. Item
+// carries the id it was given in the player's inventory.
+type ItemPickedUp struct {
+	Player *Player
+	RoomId int
+	Item   Item
+}
+
+// EventName returns the name of the event.
+func (e ItemPickedUp) EventName() string { return EventItemPickedUp }
+
+// ItemSold is published when a player sells an item to a merchant.
+type ItemSold struct {
+	Player   *Player
+	Merchant *Merchant
+	RoomId   int
+	Item     Item
+}
+
+// EventName returns the name of the event.
+func (e ItemSold) EventName() string { return EventItemSold }
+
+// DoorUnlocked is published when a locked door is opened.
+type DoorUnlocked struct {
+	Player *Player
+	RoomId int
+	Door   Door
+}
+
+// EventName returns the name of the event.
+func (e DoorUnlocked) EventName() string { return EventDoorUnlocked }
+
+// ChatSaid is published when a player says something in a room.
+type ChatSaid struct {
+	Player *Player
+	RoomId int
+	Text   string
+}
+
+// EventName returns the name of the event.
+func (e ChatSaid) EventName() string { return EventChatSaid }
+
+// ChannelSaid is published when a player talks on a global channel. Listeners
+// are the usernames of the members who haven't muted it.
+type ChannelSaid struct {
+	Player    *Player
+	Channel   string
+	Text      string
+	Listeners []string
+}
+
+// EventName returns the name of the event.
+func (e ChannelSaid) EventName() string { return EventChannelSaid }
+
+// Emoted is published when a player emotes or uses a social in a room. Text
+// is what the room sees, the target, if any, sees TargetText instead.
+type Emoted struct {
+	Player     *Player
+	RoomId     int
+	Text       string
+	Target     *Player
+	TargetText string
+}
+
+// EventName returns the name of the event.
+func (e Emoted) EventName() string { return EventEmoted }
+
+// GoldGiven is published when a player gives gold to another player, such as
+// their share of a party's earnings.
+type GoldGiven struct {
+	Player *Player
+	To     *Player
+	Amount int
+}
+
+// EventName returns the name of the event.
+func (e GoldGiven) EventName() string { return EventGoldGiven }
+
+// Banked is published when a player deposits gold or an item in a bank, such
+// as their guild's, or withdraws it. Item carries its id in the player's
+// inventory.
+type Banked struct {
+	Player   *Player
+	Bank     string
+	Gold     int
+	Item     *Item
+	Withdraw bool
+}
+
+// EventName returns the name of the event.
+func (e Banked) EventName() string { return EventBanked }
+
+// Traded is published when two players complete a trade. Player gave Gold and
+// Items to With, who gave WithGold and WithItems in return.
+type Traded struct {
+	Player    *Player
+	With      *Player
+	Gold      int
+	Items     []TradedItem
+	WithGold  int
+	WithItems []TradedItem
+}
+
+// EventName returns the name of the event.
+func (e Traded) EventName() string { return EventTraded }
@@ -1,21 +1,40 @@
 package game
 
 import (
+	"fmt"
 	"log/slog"
 	"strings"
 )
 
 type Game struct {
-	World *World
-	Sm    *SessionManager
-	state *GameState
+	World      *World
+	Sm         *SessionManager
+	Events     *EventBus
+	Chat       *Chat
+	Moderation *Moderation
+	Parties    *Parties
+	Guilds     *Guilds
+	Trades     *Trades
+	Bank       *Bank
+	Accounts   *Accounts
+	Socials    []Social // Registered as commands by each command runner
+	state      *GameState
 }
 
 // NewGame creates a new Game instance.
 func NewGame(world *World) *Game {
 	g := &Game{
-		state: NewGameState(),
-		World: world,
+		state:  NewGameState(),
+		World:  world,
+		Events: NewEventBus(),
+		Chat:   NewChat(DefaultChannels...),
+
+		Moderation: NewModeration(),
+		Parties:    NewParties(),
+		Guilds:     NewGuilds(),
+		Trades:     NewTrades(),
+		Bank:       NewBank(),
+		Accounts:   NewAccounts(),
 	}
 
 	return g
@@ -26,6 +45,19 @@ func (g Game) State() *GameState {
 	return g.state
 }
 
+// MovePlayer moves the player to another room, cancelling any trade they're
+// in.
+func (g Game) MovePlayer(ps *Player, roomId int) {
+	g.CancelTrade(ps)
+
+	if g.Sm == nil {
+		ps.SetRoomId(roomId)
+		return
+	}
+
+	g.Sm.MovePlayer(ps, roomId)
+}
+
 // GreetPlayer sends a greeting message to the player upon joining the game.
 func (g Game) GreetPlayer(ps *Player) {
 	startingRoom, ok := g.World.GetRoomById(1)
@@ -44,4 +76,35 @@ func (g Game) GreetPlayer(ps *Player) {
 	builder.WriteByte('\n')
 
 	ps.WriteString(builder.String())
+	g.SyncOutOfBand(ps)
+	g.deliverTells(ps)
+
+	slog.Info("Player joined the game", "player", ps.DisplayName)
+	g.Events.Publish(PlayerEntered{Player: ps, RoomId: startingRoom.ID})
+}
+
+// ResumePlayer welcomes back a player who resumed their session and resends
+// their out-of-band state.
+func (g Game) ResumePlayer(ps *Player) {
+	ps.WriteString("Welcome back, " + ps.DisplayName + "!\n")
+	ps.ResetOutOfBand()
+	g.SyncOutOfBand(ps)
+}
+
+// deliverTells sends the player the tells they were sent while offline.
+func (g Game) deliverTells(ps *Player) {
+	tells := g.Chat.TakeTells(ps.Username)
+	if len(tells) == 0 {
+		return
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("While you were away:\n")
+
+	for _, tell := range tells {
+		builder.WriteString(fmt.Sprintf("[%s] %s told you: %s\n", tell.Time.Format("Jan 2 15:04"), tell.Talker, tell.Text))
+	}
+
+	ps.WriteString(builder.String())
+	g.Chat.SetReplyTo(ps.Username, tells[len(tells)-1].Username)
 }
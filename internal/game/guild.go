@@ -0,0 +1,718 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GuildRank is a member's standing in their guild, which decides what they're
+// allowed to do.
+type GuildRank string
+
+const (
+	RankMember  GuildRank = "member"
+	RankOfficer GuildRank = "officer"
+	RankLeader  GuildRank = "leader"
+)
+
+// level orders the ranks, members being the lowest.
+func (r GuildRank) level() int {
+	switch r {
+	case RankLeader:
+		return 2
+	case RankOfficer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CanInvite checks if the rank can invite players into the guild.
+func (r GuildRank) CanInvite() bool {
+	return r.level() >= RankOfficer.level()
+}
+
+// CanKick checks if the rank can remove a member of another rank.
+func (r GuildRank) CanKick(other GuildRank) bool {
+	return r.CanInvite() && r.level() > other.level()
+}
+
+// CanWithdraw checks if the rank can take gold and items out of the guild bank.
+func (r GuildRank) CanWithdraw() bool {
+	return r.level() >= RankOfficer.level()
+}
+
+// CanPromote checks if the rank can change the ranks of other members.
+func (r GuildRank) CanPromote() bool {
+	return r == RankLeader
+}
+
+var (
+	ErrorInvalidGuildName = errors.New("invalid guild name")
+	ErrorGuildExists      = errors.New("guild already exists")
+	ErrorUnknownGuild     = errors.New("unknown guild")
+	ErrorNotInGuild       = errors.New("not in a guild")
+	ErrorAlreadyInGuild   = errors.New("already in a guild")
+	ErrorNoGuildInvite    = errors.New("no guild invite")
+	ErrorGuildRank        = errors.New("rank too low")
+	ErrorGuildLeader      = errors.New("leaders can't leave a guild with members")
+	ErrorNotEnoughGold    = errors.New("not enough gold")
+	ErrorItemNotFound     = errors.New("item not found")
+)
+
+// guildNamePattern matches valid guild names.
+var guildNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{2,19}$`)
+
+// GuildMember is a member of a guild, as listed on its roster.
+type GuildMember struct {
+	Username string
+	Rank     GuildRank
+}
+
+// Guild is a group of players with a shared bank. Members are identified by
+// lowercase username, which only the player with its password can log in as,
+// so they stay members between logins. The id never changes and is never
+// reused, so guild halls are bound to it rather than the name.
+type Guild struct {
+	ID      int
+	Name    string
+	Founder string
+	Founded time.Time
+	Members map[string]GuildRank // Username -> rank
+	Bank    *Inventory
+}
+
+// savedGuilds is how the guilds are written to the guilds file.
+type savedGuilds struct {
+	NextId int          `json:"nextId"`
+	Guilds []savedGuild `json:"guilds"`
+}
+
+// savedGuild is how a guild is written to the guilds file.
+type savedGuild struct {
+	ID      int                  `json:"id"`
+	Name    string               `json:"name"`
+	Founder string               `json:"founder"`
+	Founded time.Time            `json:"founded"`
+	Members map[string]GuildRank `json:"members"`
+	Gold    int                  `json:"gold"`
+	Items   []Item               `json:"items"`
+}
+
+// Guilds keeps track of the guilds and their members. When it has a file,
+// every change is saved to it.
+type Guilds struct {
+	guilds  map[string]*Guild // Lowercase name -> guild
+	members map[string]*Guild // Username -> their guild
+	invites map[string]*Guild // Username -> the guild they were last invited to
+	nextId  int
+	file    string
+	mutex   *sync.Mutex
+}
+
+// NewGuilds creates a new Guilds instance which isn't saved.
+func NewGuilds() *Guilds {
+	return &Guilds{
+		guilds:  make(map[string]*Guild),
+		members: make(map[string]*Guild),
+		invites: make(map[string]*Guild),
+		nextId:  1,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// LoadGuilds loads the guilds saved to a file, which every change is then
+// saved to. No guilds are loaded if the file doesn't exist yet.
+func LoadGuilds(file string) (*Guilds, error) {
+	g := NewGuilds()
+	g.file = file
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return g, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load guilds %s: %w", file, err)
+	}
+
+	saved := savedGuilds{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		// Guilds used to be saved as a list, without ids
+		if err := json.Unmarshal(data, &saved.Guilds); err != nil {
+			return nil, fmt.Errorf("failed to parse guilds %s: %w", file, err)
+		}
+	}
+
+	g.nextId = max(g.nextId, saved.NextId)
+	for _, s := range saved.Guilds {
+		g.nextId = max(g.nextId, s.ID+1)
+	}
+
+	for _, s := range saved.Guilds {
+		guild := &Guild{ID: s.ID, Name: s.Name, Founder: s.Founder, Founded: s.Founded, Members: s.Members, Bank: NewInventory()}
+		guild.Bank.Gold = s.Gold
+
+		if guild.ID == 0 {
+			guild.ID = g.nextId
+			g.nextId++
+		}
+
+		for _, item := range s.Items {
+			guild.Bank.ItemsMap[item.ID] = item
+		}
+
+		g.guilds[strings.ToLower(guild.Name)] = guild
+		for username := range guild.Members {
+			g.members[username] = guild
+		}
+	}
+
+	return g, nil
+}
+
+// Found creates a guild led by the player.
+func (g *Guilds) Found(username string, name string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	username = strings.ToLower(username)
+
+	if !guildNamePattern.MatchString(name) {
+		return ErrorInvalidGuildName
+	}
+
+	if _, ok := g.members[username]; ok {
+		return ErrorAlreadyInGuild
+	}
+
+	if _, ok := g.guilds[strings.ToLower(name)]; ok {
+		return ErrorGuildExists
+	}
+
+	guild := &Guild{
+		ID:      g.nextId,
+		Name:    name,
+		Founder: username,
+		Founded: time.Now(),
+		Members: map[string]GuildRank{username: RankLeader},
+		Bank:    NewInventory(),
+	}
+
+	g.guilds[strings.ToLower(name)] = guild
+	g.members[username] = guild
+	g.nextId++
+
+	slog.Info("Guild founded", "guild", name, "id", guild.ID, "founder", username)
+
+	g.save()
+	return nil
+}
+
+// Invite invites a player into the inviter's guild, returning its name.
+func (g *Guilds) Invite(username string, target string) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return "", err
+	}
+
+	if !rank.CanInvite() {
+		return "", ErrorGuildRank
+	}
+
+	if _, ok := g.members[strings.ToLower(target)]; ok {
+		return "", ErrorAlreadyInGuild
+	}
+
+	g.invites[strings.ToLower(target)] = guild
+	return guild.Name, nil
+}
+
+// Accept joins the guild the player was last invited to, returning its name.
+func (g *Guilds) Accept(username string) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	username = strings.ToLower(username)
+
+	if _, ok := g.members[username]; ok {
+		return "", ErrorAlreadyInGuild
+	}
+
+	guild, ok := g.invites[username]
+	delete(g.invites, username)
+
+	// The guild may have been disbanded since the invite
+	if !ok || g.guilds[strings.ToLower(guild.Name)] != guild {
+		return "", ErrorNoGuildInvite
+	}
+
+	guild.Members[username] = RankMember
+	g.members[username] = guild
+
+	g.save()
+	return guild.Name, nil
+}
+
+// Leave takes the player out of their guild, returning its name. A leader can
+// only leave once they're the last member, which disbands the guild.
+func (g *Guilds) Leave(username string) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return "", err
+	}
+
+	if rank == RankLeader && len(guild.Members) > 1 {
+		return "", ErrorGuildLeader
+	}
+
+	g.removeMember(guild, strings.ToLower(username))
+
+	if len(guild.Members) == 0 {
+		delete(g.guilds, strings.ToLower(guild.Name))
+		slog.Info("Guild disbanded", "guild", guild.Name)
+	}
+
+	g.save()
+	return guild.Name, nil
+}
+
+// Kick removes a lower ranked member from the player's guild, returning its name.
+func (g *Guilds) Kick(username string, target string) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return "", err
+	}
+
+	targetRank, ok := guild.Members[strings.ToLower(target)]
+	if !ok {
+		return "", ErrorNotInGuild
+	}
+
+	if !rank.CanKick(targetRank) {
+		return "", ErrorGuildRank
+	}
+
+	g.removeMember(guild, strings.ToLower(target))
+
+	g.save()
+	return guild.Name, nil
+}
+
+// Promote raises a member of the leader's guild to officer. Promoting an
+// officer hands them the leadership, making the leader an officer. The
+// target's new rank is returned.
+func (g *Guilds) Promote(username string, target string) (GuildRank, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, targetRank, err := g.promotable(username, target)
+	if err != nil {
+		return "", err
+	}
+
+	username, target = strings.ToLower(username), strings.ToLower(target)
+
+	switch targetRank {
+	case RankMember:
+		guild.Members[target] = RankOfficer
+	case RankOfficer:
+		guild.Members[target] = RankLeader
+		guild.Members[username] = RankOfficer
+	}
+
+	g.save()
+	return guild.Members[target], nil
+}
+
+// Demote lowers an officer of the leader's guild to member.
+func (g *Guilds) Demote(username string, target string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, targetRank, err := g.promotable(username, target)
+	if err != nil {
+		return err
+	}
+
+	if targetRank != RankOfficer {
+		return ErrorGuildRank
+	}
+
+	guild.Members[strings.ToLower(target)] = RankMember
+
+	g.save()
+	return nil
+}
+
+// Disband removes a guild, returning the usernames of its members.
+func (g *Guilds) Disband(name string) ([]string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, ok := g.guilds[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrorUnknownGuild
+	}
+
+	members := []string{}
+	for username := range guild.Members {
+		delete(g.members, username)
+		members = append(members, username)
+	}
+
+	delete(g.guilds, strings.ToLower(name))
+	slog.Info("Guild disbanded", "guild", guild.Name)
+
+	sort.Strings(members)
+
+	g.save()
+	return members, nil
+}
+
+// Rename renames a guild, returning the usernames of its members. Its hall
+// keeps working since doors are bound to the guild's id.
+func (g *Guilds) Rename(name string, newName string) ([]string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, ok := g.guilds[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrorUnknownGuild
+	}
+
+	if !guildNamePattern.MatchString(newName) {
+		return nil, ErrorInvalidGuildName
+	}
+
+	if other, ok := g.guilds[strings.ToLower(newName)]; ok && other != guild {
+		return nil, ErrorGuildExists
+	}
+
+	delete(g.guilds, strings.ToLower(name))
+	guild.Name = newName
+	g.guilds[strings.ToLower(newName)] = guild
+
+	slog.Info("Guild renamed", "guild", name, "name", newName)
+
+	members := []string{}
+	for username := range guild.Members {
+		members = append(members, username)
+	}
+
+	sort.Strings(members)
+
+	g.save()
+	return members, nil
+}
+
+// GuildOf returns the name of the player's guild and their rank in it.
+func (g *Guilds) GuildOf(username string) (string, GuildRank, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return "", "", false
+	}
+
+	return guild.Name, rank, true
+}
+
+// GuildIdOf returns the id of the player's guild, or 0 if they aren't in one.
+func (g *Guilds) GuildIdOf(username string) int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, ok := g.members[strings.ToLower(username)]
+	if !ok {
+		return 0
+	}
+
+	return guild.ID
+}
+
+// Name returns the current name of the guild with the id.
+func (g *Guilds) Name(id int) (string, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for _, guild := range g.guilds {
+		if guild.ID == id {
+			return guild.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// IsMember checks if the player is a member of the named guild.
+func (g *Guilds) IsMember(name string, username string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, ok := g.members[strings.ToLower(username)]
+	return ok && strings.EqualFold(guild.Name, name)
+}
+
+// Members returns the usernames of the named guild's members, sorted.
+func (g *Guilds) Members(name string) []string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, ok := g.guilds[strings.ToLower(name)]
+	if !ok {
+		return nil
+	}
+
+	members := []string{}
+	for username := range guild.Members {
+		members = append(members, username)
+	}
+
+	sort.Strings(members)
+	return members
+}
+
+// Roster returns the members of the player's guild, highest rank first.
+func (g *Guilds) Roster(username string) ([]GuildMember, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, _, err := g.memberOf(username)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := []GuildMember{}
+	for member, rank := range guild.Members {
+		roster = append(roster, GuildMember{Username: member, Rank: rank})
+	}
+
+	sort.Slice(roster, func(i, j int) bool {
+		if roster[i].Rank != roster[j].Rank {
+			return roster[i].Rank.level() > roster[j].Rank.level()
+		}
+
+		return roster[i].Username < roster[j].Username
+	})
+
+	return roster, nil
+}
+
+// DepositGold adds gold to the bank of the player's guild.
+func (g *Guilds) DepositGold(username string, amount int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, _, err := g.memberOf(username)
+	if err != nil {
+		return err
+	}
+
+	guild.Bank.AddGold(amount)
+
+	g.save()
+	return nil
+}
+
+// WithdrawGold takes gold out of the bank of the player's guild.
+func (g *Guilds) WithdrawGold(username string, amount int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, err := g.withdrawer(username)
+	if err != nil {
+		return err
+	}
+
+	if !guild.Bank.TakeGold(amount) {
+		return ErrorNotEnoughGold
+	}
+
+	g.save()
+	return nil
+}
+
+// DepositItem adds an item to the bank of the player's guild, returning it
+// with its id in the bank.
+func (g *Guilds) DepositItem(username string, item Item) (Item, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, _, err := g.memberOf(username)
+	if err != nil {
+		return item, err
+	}
+
+	item = guild.Bank.Add(item)
+
+	g.save()
+	return item, nil
+}
+
+// WithdrawItem takes an item out of the bank of the player's guild.
+func (g *Guilds) WithdrawItem(username string, itemId string) (Item, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, err := g.withdrawer(username)
+	if err != nil {
+		return Item{}, err
+	}
+
+	item, ok := guild.Bank.Remove(itemId)
+	if !ok {
+		return Item{}, ErrorItemNotFound
+	}
+
+	g.save()
+	return item, nil
+}
+
+// BankContents returns the gold and items in the bank of the player's guild.
+func (g *Guilds) BankContents(username string) (int, []Item, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guild, _, err := g.memberOf(username)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return guild.Bank.GetGold(), guild.Bank.GetItems(), nil
+}
+
+// memberOf returns the player's guild and rank. The caller must hold the lock.
+func (g *Guilds) memberOf(username string) (*Guild, GuildRank, error) {
+	username = strings.ToLower(username)
+
+	guild, ok := g.members[username]
+	if !ok {
+		return nil, "", ErrorNotInGuild
+	}
+
+	return guild, guild.Members[username], nil
+}
+
+// promotable checks the player leads a guild the target is a member of,
+// returning the guild and the target's rank. The caller must hold the lock.
+func (g *Guilds) promotable(username string, target string) (*Guild, GuildRank, error) {
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !rank.CanPromote() {
+		return nil, "", ErrorGuildRank
+	}
+
+	targetRank, ok := guild.Members[strings.ToLower(target)]
+	if !ok || strings.EqualFold(username, target) {
+		return nil, "", ErrorNotInGuild
+	}
+
+	return guild, targetRank, nil
+}
+
+// withdrawer returns the player's guild if their rank lets them take from its
+// bank. The caller must hold the lock.
+func (g *Guilds) withdrawer(username string) (*Guild, error) {
+	guild, rank, err := g.memberOf(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rank.CanWithdraw() {
+		return nil, ErrorGuildRank
+	}
+
+	return guild, nil
+}
+
+// removeMember takes a player out of a guild. The caller must hold the lock.
+func (g *Guilds) removeMember(guild *Guild, username string) {
+	delete(guild.Members, username)
+	delete(g.members, username)
+}
+
+// save writes the guilds to their file, if they have one. The caller must
+// hold the lock.
+func (g *Guilds) save() {
+	if g.file == "" {
+		return
+	}
+
+	saved := savedGuilds{NextId: g.nextId, Guilds: []savedGuild{}}
+	for _, guild := range g.guilds {
+		saved.Guilds = append(saved.Guilds, savedGuild{
+			ID:      guild.ID,
+			Name:    guild.Name,
+			Founder: guild.Founder,
+			Founded: guild.Founded,
+			Members: guild.Members,
+			Gold:    guild.Bank.GetGold(),
+			Items:   guild.Bank.GetItems(),
+		})
+	}
+
+	sort.Slice(saved.Guilds, func(i, j int) bool {
+		return saved.Guilds[i].ID < saved.Guilds[j].ID
+	})
+
+	if err := writeJSON(g.file, saved); err != nil {
+		slog.Error("Failed to save guilds", "file", g.file, "error", err)
+	}
+}
+
+// writeJSON writes a value to a file as indented JSON, creating its directory
+// if needed. The file is replaced in one step so a crash never leaves it half
+// written.
+func writeJSON(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", file, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// TellGuild sends a notice to the members of the guild who are online.
+func (g Game) TellGuild(members []string, text string) {
+	for _, username := range members {
+		if member, ok := g.Sm.FindPlayer(username); ok {
+			g.Sm.SendToPlayer(member.GetUUID(), "[guild] "+text)
+		}
+	}
+}
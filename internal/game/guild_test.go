@@ -0,0 +1,176 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuilds(t *testing.T) {
+	guilds := NewGuilds()
+
+	assert.ErrorIs(t, guilds.Found("alice", "No Spaces"), ErrorInvalidGuildName)
+	assert.ErrorIs(t, guilds.Found("alice", "ab"), ErrorInvalidGuildName)
+	assert.Nil(t, guilds.Found("Alice", "Owls"))
+	assert.ErrorIs(t, guilds.Found("bob", "OWLS"), ErrorGuildExists)
+	assert.ErrorIs(t, guilds.Found("alice", "Hawks"), ErrorAlreadyInGuild)
+
+	_, err := guilds.Accept("bob")
+	assert.ErrorIs(t, err, ErrorNoGuildInvite)
+
+	for _, username := range []string{"bob", "carol"} {
+		_, err = guilds.Invite("alice", username)
+		assert.Nil(t, err)
+
+		name, err := guilds.Accept(username)
+		assert.Nil(t, err)
+		assert.Equal(t, "Owls", name)
+	}
+
+	// Members can't invite or kick
+	_, err = guilds.Invite("bob", "dave")
+	assert.ErrorIs(t, err, ErrorGuildRank)
+
+	_, err = guilds.Kick("bob", "carol")
+	assert.ErrorIs(t, err, ErrorGuildRank)
+
+	rank, err := guilds.Promote("alice", "bob")
+	assert.Nil(t, err)
+	assert.Equal(t, RankOfficer, rank)
+
+	assert.True(t, guilds.IsMember("owls", "Bob"))
+	assert.Equal(t, []string{"alice", "bob", "carol"}, guilds.Members("owls"))
+
+	roster, err := guilds.Roster("carol")
+	assert.Nil(t, err)
+	assert.Equal(t, []GuildMember{
+		{Username: "alice", Rank: RankLeader},
+		{Username: "bob", Rank: RankOfficer},
+		{Username: "carol", Rank: RankMember},
+	}, roster)
+
+	// Officers can kick members, but not each other or the leader
+	_, err = guilds.Kick("bob", "alice")
+	assert.ErrorIs(t, err, ErrorGuildRank)
+
+	_, err = guilds.Kick("bob", "carol")
+	assert.Nil(t, err)
+	assert.False(t, guilds.IsMember("owls", "carol"))
+
+	// Only officers and leaders can take from the bank
+	assert.Nil(t, guilds.DepositGold("bob", 10))
+	assert.ErrorIs(t, guilds.WithdrawGold("bob", 11), ErrorNotEnoughGold)
+	assert.Nil(t, guilds.WithdrawGold("bob", 4))
+
+	assert.Nil(t, guilds.Demote("alice", "bob"))
+	assert.ErrorIs(t, guilds.WithdrawGold("bob", 1), ErrorGuildRank)
+
+	_, err = guilds.WithdrawItem("alice", "101")
+	assert.ErrorIs(t, err, ErrorItemNotFound)
+
+	// Leaders hand over the guild before leaving it
+	_, err = guilds.Leave("alice")
+	assert.ErrorIs(t, err, ErrorGuildLeader)
+
+	_, err = guilds.Promote("alice", "bob")
+	assert.Nil(t, err)
+	rank, err = guilds.Promote("alice", "bob")
+	assert.Nil(t, err)
+	assert.Equal(t, RankLeader, rank)
+
+	_, aliceRank, _ := guilds.GuildOf("alice")
+	assert.Equal(t, RankOfficer, aliceRank)
+
+	_, err = guilds.Leave("alice")
+	assert.Nil(t, err)
+
+	// The last member leaving disbands the guild
+	_, err = guilds.Leave("bob")
+	assert.Nil(t, err)
+	assert.Nil(t, guilds.Members("owls"))
+}
+
+func TestGuildsSave(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "save", "guilds.json")
+
+	guilds, err := LoadGuilds(file)
+	assert.Nil(t, err)
+
+	assert.Nil(t, guilds.Found("alice", "Owls"))
+	assert.Nil(t, guilds.DepositGold("alice", 25))
+
+	item, err := guilds.DepositItem("alice", Item{ID: "105", Name: "Coin", Type: Trinket, SellingPrice: 1})
+	assert.Nil(t, err)
+
+	_, err = guilds.Invite("alice", "bob")
+	assert.Nil(t, err)
+	_, err = guilds.Accept("bob")
+	assert.Nil(t, err)
+
+	members, err := guilds.Rename("owls", "Ravens")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, members)
+
+	loaded, err := LoadGuilds(file)
+	assert.Nil(t, err)
+
+	name, rank, ok := loaded.GuildOf("bob")
+	assert.True(t, ok)
+	assert.Equal(t, "Ravens", name)
+	assert.Equal(t, RankMember, rank)
+
+	// Renaming keeps the id
+	assert.Equal(t, 1, loaded.GuildIdOf("bob"))
+	assert.Equal(t, 0, loaded.GuildIdOf("carol"))
+
+	name, ok = loaded.Name(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Ravens", name)
+
+	gold, items, err := loaded.BankContents("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, 25, gold)
+	assert.Equal(t, []Item{item}, items)
+
+	// Disbanding is saved too
+	_, err = loaded.Disband("ravens")
+	assert.Nil(t, err)
+
+	loaded, err = LoadGuilds(file)
+	assert.Nil(t, err)
+	assert.Nil(t, loaded.Members("ravens"))
+
+	// Ids aren't reused, even for a guild with the same name
+	assert.Nil(t, loaded.Found("carol", "Ravens"))
+	assert.Equal(t, 2, loaded.GuildIdOf("carol"))
+
+	_, ok = loaded.Name(1)
+	assert.False(t, ok)
+}
+
+func TestGuildsLoadWithoutIds(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "guilds.json")
+	assert.Nil(t, writeJSON(file, []savedGuild{
+		{Name: "Hawks", Founder: "bob", Members: map[string]GuildRank{"bob": RankLeader}},
+		{Name: "Owls", Founder: "alice", Members: map[string]GuildRank{"alice": RankLeader}},
+	}))
+
+	guilds, err := LoadGuilds(file)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, guilds.GuildIdOf("bob"))
+	assert.Equal(t, 2, guilds.GuildIdOf("alice"))
+
+	assert.Nil(t, guilds.Found("carol", "Ravens"))
+	assert.Equal(t, 3, guilds.GuildIdOf("carol"))
+}
+
+func TestDoorAllows(t *testing.T) {
+	door := Door{Name: "north"}
+	assert.True(t, door.Allows(0))
+
+	door.Restriction = &DoorRestriction{GuildId: 2}
+	assert.False(t, door.Allows(0))
+	assert.False(t, door.Allows(1))
+	assert.True(t, door.Allows(2))
+}
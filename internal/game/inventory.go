@@ -2,25 +2,34 @@ package game
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// Inventory represents a player's inventory.
+// Inventory represents a player's inventory. Gold and ItemsMap are guarded by
+// the inventory's lock, use the methods below once the inventory is shared.
 type Inventory struct {
 	Gold     int             `json:"gold"`
 	Items    []*Item         `json:"items"`
 	ItemsMap map[string]Item `json:"-"`
+
+	mutex *sync.RWMutex
 }
 
 // NewInventory creates a new empty inventory.
 func NewInventory() *Inventory {
 	return &Inventory{
 		ItemsMap: make(map[string]Item),
+		mutex:    &sync.RWMutex{},
 	}
 }
 
 // Initialize the inventory items
 func (inv *Inventory) Initialize() {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
 	inv.Items = []*Item{}
 	for _, item := range inv.ItemsMap {
 		inv.Items = append(inv.Items, &item)
@@ -33,6 +42,9 @@ func (inv *Inventory) Sell(itemId string, merchant *Merchant) (*Item, bool) {
 
 	fmt.Printf("Attempting to sell item id: %s\n", id)
 
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
 	item, exists := inv.ItemsMap[id]
 	if !exists {
 		return nil, false
@@ -48,14 +60,34 @@ func (inv *Inventory) Sell(itemId string, merchant *Merchant) (*Item, bool) {
 	return &item, true
 }
 
-// Add adds an item to the inventory.
-func (inv *Inventory) Add(item Item) {
-	item.ID = fmt.Sprintf("%d", len(inv.ItemsMap)+101)
+// Add adds an item to the inventory, returning it with its inventory id.
+func (inv *Inventory) Add(item Item) Item {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	return inv.add(item)
+}
+
+// add adds an item to the inventory. The caller must hold the lock.
+func (inv *Inventory) add(item Item) Item {
+	// Ids can be freed by selling, so skip any that are still taken
+	for next := len(inv.ItemsMap) + 101; ; next++ {
+		item.ID = fmt.Sprintf("%d", next)
+		if _, taken := inv.ItemsMap[item.ID]; !taken {
+			break
+		}
+	}
+
 	inv.ItemsMap[item.ID] = item
+
+	return item
 }
 
 // List returns a string representation of the inventory contents.
-func (inv Inventory) List() string {
+func (inv *Inventory) List() string {
+	inv.mutex.RLock()
+	defer inv.mutex.RUnlock()
+
 	builder := strings.Builder{}
 
 	builder.WriteString("You have ")
@@ -73,3 +105,118 @@ func (inv Inventory) List() string {
 
 	return builder.String()
 }
+
+// GetGold returns how much gold is in the inventory.
+func (inv *Inventory) GetGold() int {
+	inv.mutex.RLock()
+	defer inv.mutex.RUnlock()
+
+	return inv.Gold
+}
+
+// Remove takes an item out of the inventory.
+func (inv *Inventory) Remove(itemId string) (Item, bool) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	id := strings.ToLower(itemId)
+
+	item, exists := inv.ItemsMap[id]
+	if exists {
+		delete(inv.ItemsMap, id)
+	}
+
+	return item, exists
+}
+
+// AddGold adds gold to the inventory.
+func (inv *Inventory) AddGold(amount int) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	inv.Gold += amount
+}
+
+// TakeGold removes gold from the inventory, unless there isn't enough.
+func (inv *Inventory) TakeGold(amount int) bool {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	if amount < 0 || inv.Gold < amount {
+		return false
+	}
+
+	inv.Gold -= amount
+	return true
+}
+
+// Exchange swaps the offers between two inventories in one step. Nothing
+// changes unless both inventories still hold what they offered. The items each
+// side gave are returned with their ids in the other inventory. Players only
+// trade with one other player at a time, so the locks are never taken in the
+// opposite order.
+func Exchange(a *Inventory, aOffer TradeOffer, b *Inventory, bOffer TradeOffer) ([2][]TradedItem, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !a.holds(aOffer) || !b.holds(bOffer) {
+		return [2][]TradedItem{}, ErrorTradeFailed
+	}
+
+	given := [2][]TradedItem{a.give(aOffer, b), b.give(bOffer, a)}
+	return given, nil
+}
+
+// holds checks the inventory has the gold and items offered. Ids are reused
+// once an item leaves the inventory, so each item must still be the one
+// offered. The caller must hold the lock.
+func (inv *Inventory) holds(offer TradeOffer) bool {
+	if inv.Gold < offer.Gold {
+		return false
+	}
+
+	for _, item := range offer.Items {
+		if held, ok := inv.ItemsMap[item.ID]; !ok || held != item {
+			return false
+		}
+	}
+
+	return true
+}
+
+// give moves the gold and items offered to another inventory. The caller must
+// hold both locks.
+func (inv *Inventory) give(offer TradeOffer, to *Inventory) []TradedItem {
+	inv.Gold -= offer.Gold
+	to.Gold += offer.Gold
+
+	given := []TradedItem{}
+	for _, offered := range offer.Items {
+		item := inv.ItemsMap[offered.ID]
+		delete(inv.ItemsMap, offered.ID)
+
+		given = append(given, TradedItem{Item: to.add(item), FromID: offered.ID})
+	}
+
+	return given
+}
+
+// GetItems returns a copy of the items in the inventory, ordered by id.
+func (inv *Inventory) GetItems() []Item {
+	inv.mutex.RLock()
+	defer inv.mutex.RUnlock()
+
+	items := make([]Item, 0, len(inv.ItemsMap))
+	for _, item := range inv.ItemsMap {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	return items
+}
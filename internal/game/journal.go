@@ -0,0 +1,524 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/journal"
+)
+
+const (
+	// JournalQueueSize is how many events the journal can fall behind by before
+	// publishers wait for it to catch up.
+	JournalQueueSize = 1024
+
+	// EntryWorldLoaded marks the server starting with a freshly loaded world.
+	EntryWorldLoaded = "WorldLoaded"
+
+	// EntryGap marks events which couldn't be written to the journal.
+	EntryGap = "Gap"
+)
+
+// Journal records. Players are identified by username since their uuid
+// changes every login.
+type (
+	// WorldLoadedRecord is journaled on startup, once the world is loaded.
+	WorldLoadedRecord struct {
+		Rooms map[int]*RoomState `json:"rooms"`
+	}
+
+	// GapRecord is journaled once the journal can be written again after
+	// failing to write events.
+	GapRecord struct {
+		Lost int `json:"lost"`
+	}
+
+	// PlayerEnteredRecord is journaled for PlayerEntered events.
+	PlayerEnteredRecord struct {
+		Player     string `json:"player"`
+		RoomId     int    `json:"roomId"`
+		FromRoomId int    `json:"fromRoomId"`
+	}
+
+	// PlayerLeftRecord is journaled for PlayerLeft events.
+	PlayerLeftRecord struct {
+		Player   string `json:"player"`
+		RoomId   int    `json:"roomId"`
+		ToRoomId int    `json:"toRoomId"`
+	}
+
+	// ItemPickedUpRecord is journaled for ItemPickedUp events.
+	ItemPickedUpRecord struct {
+		Player string `json:"player"`
+		RoomId int    `json:"roomId"`
+		Item   Item   `json:"item"`
+	}
+
+	// ItemSoldRecord is journaled for ItemSold events.
+	ItemSoldRecord struct {
+		Player   string `json:"player"`
+		Merchant string `json:"merchant"`
+		RoomId   int    `json:"roomId"`
+		Item     Item   `json:"item"`
+	}
+
+	// DoorUnlockedRecord is journaled for DoorUnlocked events.
+	DoorUnlockedRecord struct {
+		Player string `json:"player"`
+		RoomId int    `json:"roomId"`
+		Door   string `json:"door"`
+	}
+
+	// GoldGivenRecord is journaled for GoldGiven events.
+	GoldGivenRecord struct {
+		Player string `json:"player"`
+		To     string `json:"to"`
+		Amount int    `json:"amount"`
+	}
+
+	// BankedRecord is journaled for Banked events.
+	BankedRecord struct {
+		Player   string `json:"player"`
+		Bank     string `json:"bank"`
+		Gold     int    `json:"gold,omitempty"`
+		Item     *Item  `json:"item,omitempty"`
+		Withdraw bool   `json:"withdraw,omitempty"`
+	}
+
+	// TradedRecord is journaled for Traded events.
+	TradedRecord struct {
+		Player    string       `json:"player"`
+		With      string       `json:"with"`
+		Gold      int          `json:"gold"`
+		Items     []TradedItem `json:"items"`
+		WithGold  int          `json:"withGold"`
+		WithItems []TradedItem `json:"withItems"`
+	}
+)
+
+// RecordTo appends every state changing event published on the game's event
+// bus to the journal. Events are written on the recorder's own goroutine, so
+// publishers only wait for the disk when the journal falls JournalQueueSize
+// events behind, and none are ever dropped. The returned function stops
+// recording once the events already published are written.
+func (g Game) RecordTo(j *journal.Journal) func() {
+	lost := 0
+
+	return g.Events.SubscribeAll(func(e DomainEvent) {
+		record, ok := journalRecord(e)
+		if !ok {
+			return
+		}
+
+		// Events which failed to be written leave a gap replaying won't skip
+		if lost > 0 {
+			if _, err := j.Append(EntryGap, GapRecord{Lost: lost}); err == nil {
+				lost = 0
+			}
+		}
+
+		if _, err := j.Append(e.EventName(), record); err != nil {
+			lost++
+			slog.Error("Failed to journal event", "event", e.EventName(), "error", err)
+		}
+	}, WithAsync(), WithBlocking(), WithQueueSize(JournalQueueSize))
+}
+
+// RecordWorldLoaded marks the journal with the state of the world as it was
+// just loaded, so replaying starts over from it whenever the server restarted.
+// It must be written before anything happens in the world.
+func (g Game) RecordWorldLoaded(j *journal.Journal) error {
+	_, err := j.Append(EntryWorldLoaded, WorldLoadedRecord{Rooms: NewWorldState(g.World).Rooms})
+	return err
+}
+
+// journalRecord converts an event into its journal record. False is returned
+// for events which don't change the game's state, such as chat.
+func journalRecord(e DomainEvent) (any, bool) {
+	switch e := e.(type) {
+	case PlayerEntered:
+		return PlayerEnteredRecord{Player: e.Player.Username, RoomId: e.RoomId, FromRoomId: e.FromRoomId}, true
+	case PlayerLeft:
+		return PlayerLeftRecord{Player: e.Player.Username, RoomId: e.RoomId, ToRoomId: e.ToRoomId}, true
+	case ItemPickedUp:
+		return ItemPickedUpRecord{Player: e.Player.Username, RoomId: e.RoomId, Item: e.Item}, true
+	case ItemSold:
+		return ItemSoldRecord{Player: e.Player.Username, Merchant: e.Merchant.Name, RoomId: e.RoomId, Item: e.Item}, true
+	case DoorUnlocked:
+		return DoorUnlockedRecord{Player: e.Player.Username, RoomId: e.RoomId, Door: e.Door.Name}, true
+	case GoldGiven:
+		return GoldGivenRecord{Player: e.Player.Username, To: e.To.Username, Amount: e.Amount}, true
+	case Banked:
+		return BankedRecord{Player: e.Player.Username, Bank: e.Bank, Gold: e.Gold, Item: e.Item, Withdraw: e.Withdraw}, true
+	case Traded:
+		return TradedRecord{
+			Player:    e.Player.Username,
+			With:      e.With.Username,
+			Gold:      e.Gold,
+			Items:     e.Items,
+			WithGold:  e.WithGold,
+			WithItems: e.WithItems,
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// RoomState is the journaled state of a room.
+type RoomState struct {
+	Items     []Item            `json:"items"`
+	Doors     map[string]bool   `json:"doors"`     // Door name -> locked
+	Merchants map[string][]Item `json:"merchants"` // Merchant name -> items bought from players
+}
+
+// PlayerState is the journaled state of a player.
+type PlayerState struct {
+	RoomId int             `json:"roomId"`
+	Online bool            `json:"online"`
+	Gold   int             `json:"gold"`
+	Items  map[string]Item `json:"items"` // Inventory id -> item
+}
+
+// WorldState is a snapshot of the state the journal changes. Replaying the
+// journal entries after Seq onto it rebuilds the state of the game.
+type WorldState struct {
+	Seq     uint64                  `json:"seq"`
+	Rooms   map[int]*RoomState      `json:"rooms"`
+	Players map[string]*PlayerState `json:"players"`
+}
+
+// NewWorldState takes a snapshot of the world's rooms.
+func NewWorldState(world *World) *WorldState {
+	ws := &WorldState{
+		Rooms:   make(map[int]*RoomState),
+		Players: make(map[string]*PlayerState),
+	}
+
+	for _, room := range world.rooms {
+		state := &RoomState{
+			Items:     room.GetItems(),
+			Doors:     make(map[string]bool),
+			Merchants: make(map[string][]Item),
+		}
+
+		for _, door := range room.Doors {
+			state.Doors[door.Name] = door.IsLocked
+		}
+
+		for _, npc := range room.Npcs {
+			if merchant, ok := npc.(*Merchant); ok {
+				state.Merchants[merchant.Name] = merchant.Inventory.GetItems()
+			}
+		}
+
+		ws.Rooms[room.ID] = state
+	}
+
+	return ws
+}
+
+// LoadWorldState loads a snapshot saved with Save.
+func LoadWorldState(file string) (*WorldState, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", file, err)
+	}
+
+	ws := &WorldState{}
+	if err := json.Unmarshal(data, ws); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", file, err)
+	}
+
+	if ws.Rooms == nil {
+		ws.Rooms = make(map[int]*RoomState)
+	}
+
+	if ws.Players == nil {
+		ws.Players = make(map[string]*PlayerState)
+	}
+
+	initRooms(ws.Rooms)
+
+	for _, player := range ws.Players {
+		if player.Items == nil {
+			player.Items = make(map[string]Item)
+		}
+	}
+
+	return ws, nil
+}
+
+// Save writes the snapshot to a file.
+func (ws *WorldState) Save(file string) error {
+	data, err := json.MarshalIndent(ws, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save snapshot %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// Apply replays a journal entry onto the state, returning a description of
+// what happened and which player it concerned.
+func (ws *WorldState) Apply(entry journal.Entry) (string, string, error) {
+	ws.Seq = entry.Seq
+
+	switch entry.Type {
+	case EntryWorldLoaded:
+		record := WorldLoadedRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		// The server restarted, so the world starts over and nobody is online
+		ws.Rooms = record.Rooms
+		if ws.Rooms == nil {
+			ws.Rooms = make(map[int]*RoomState)
+		}
+
+		initRooms(ws.Rooms)
+
+		for _, player := range ws.Players {
+			player.Online = false
+		}
+
+		return "the server started, resetting the world", "", nil
+	case EntryGap:
+		record := GapRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		return "", "", fmt.Errorf("the journal is missing %d events before #%d", record.Lost, entry.Seq)
+	case EventPlayerEntered:
+		record := PlayerEnteredRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		// Players aren't saved between logins, so joining starts them over
+		if record.FromRoomId == 0 {
+			delete(ws.Players, record.Player)
+		}
+
+		player := ws.player(record.Player)
+		player.RoomId = record.RoomId
+		player.Online = true
+
+		if record.FromRoomId == 0 {
+			return fmt.Sprintf("%s joined the game in room %d", record.Player, record.RoomId), record.Player, nil
+		}
+
+		return fmt.Sprintf("%s entered room %d from room %d", record.Player, record.RoomId, record.FromRoomId), record.Player, nil
+	case EventPlayerLeft:
+		record := PlayerLeftRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		player := ws.player(record.Player)
+
+		if record.ToRoomId == 0 {
+			player.Online = false
+			return fmt.Sprintf("%s left the game from room %d", record.Player, record.RoomId), record.Player, nil
+		}
+
+		player.RoomId = record.ToRoomId
+		return fmt.Sprintf("%s left room %d for room %d", record.Player, record.RoomId, record.ToRoomId), record.Player, nil
+	case EventItemPickedUp:
+		record := ItemPickedUpRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		room := ws.room(record.RoomId)
+		found := false
+
+		for i, item := range room.Items {
+			if strings.EqualFold(item.Name, record.Item.Name) {
+				room.Items = append(room.Items[:i:i], room.Items[i+1:]...)
+				found = true
+				break
+			}
+		}
+
+		ws.player(record.Player).Items[record.Item.ID] = record.Item
+
+		description := fmt.Sprintf("%s picked up %s (ID: %s) in room %d", record.Player, record.Item.Name, record.Item.ID, record.RoomId)
+		if !found {
+			description += ", but it wasn't in the room"
+		}
+
+		return description, record.Player, nil
+	case EventItemSold:
+		record := ItemSoldRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		player := ws.player(record.Player)
+		_, found := player.Items[record.Item.ID]
+
+		delete(player.Items, record.Item.ID)
+		player.Gold += record.Item.SellingPrice
+
+		room := ws.room(record.RoomId)
+		room.Merchants[record.Merchant] = append(room.Merchants[record.Merchant], record.Item)
+
+		description := fmt.Sprintf("%s sold %s (ID: %s) to %s for %d gold", record.Player, record.Item.Name, record.Item.ID, record.Merchant, record.Item.SellingPrice)
+		if !found {
+			description += ", but didn't have it"
+		}
+
+		return description, record.Player, nil
+	case EventDoorUnlocked:
+		record := DoorUnlockedRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		ws.room(record.RoomId).Doors[record.Door] = false
+
+		return fmt.Sprintf("%s unlocked the %s door in room %d", record.Player, record.Door, record.RoomId), record.Player, nil
+	case EventGoldGiven:
+		record := GoldGivenRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		ws.player(record.Player).Gold -= record.Amount
+		ws.player(record.To).Gold += record.Amount
+
+		return fmt.Sprintf("%s gave %s %d gold", record.Player, record.To, record.Amount), record.Player, nil
+	case EventBanked:
+		record := BankedRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		player := ws.player(record.Player)
+
+		what := fmt.Sprintf("%d gold", record.Gold)
+		if record.Item != nil {
+			what = fmt.Sprintf("%s (ID: %s)", record.Item.Name, record.Item.ID)
+		}
+
+		if record.Withdraw {
+			player.Gold += record.Gold
+			if record.Item != nil {
+				player.Items[record.Item.ID] = *record.Item
+			}
+
+			return fmt.Sprintf("%s withdrew %s from %s", record.Player, what, record.Bank), record.Player, nil
+		}
+
+		player.Gold -= record.Gold
+		if record.Item != nil {
+			delete(player.Items, record.Item.ID)
+		}
+
+		return fmt.Sprintf("%s deposited %s in %s", record.Player, what, record.Bank), record.Player, nil
+	case EventTraded:
+		record := TradedRecord{}
+		if err := entry.Decode(&record); err != nil {
+			return "", "", err
+		}
+
+		player, with := ws.player(record.Player), ws.player(record.With)
+
+		player.Gold += record.WithGold - record.Gold
+		with.Gold += record.Gold - record.WithGold
+
+		for _, traded := range record.Items {
+			delete(player.Items, traded.FromID)
+			with.Items[traded.Item.ID] = traded.Item
+		}
+
+		for _, traded := range record.WithItems {
+			delete(with.Items, traded.FromID)
+			player.Items[traded.Item.ID] = traded.Item
+		}
+
+		description := fmt.Sprintf("%s traded %s to %s for %s", record.Player, describeTrade(record.Gold, record.Items),
+			record.With, describeTrade(record.WithGold, record.WithItems))
+
+		return description, record.Player, nil
+	default:
+		return fmt.Sprintf("unknown entry type %s", entry.Type), "", nil
+	}
+}
+
+// initRooms makes the maps of decoded rooms, since empty maps are dropped when
+// they're encoded.
+func initRooms(rooms map[int]*RoomState) {
+	for _, room := range rooms {
+		if room.Doors == nil {
+			room.Doors = make(map[string]bool)
+		}
+
+		if room.Merchants == nil {
+			room.Merchants = make(map[string][]Item)
+		}
+	}
+}
+
+// describeTrade lists one side of a trade.
+func describeTrade(gold int, items []TradedItem) string {
+	goods := []string{}
+	for _, traded := range items {
+		goods = append(goods, traded.Item.Name)
+	}
+
+	if gold > 0 || len(goods) == 0 {
+		goods = append(goods, fmt.Sprintf("%d gold", gold))
+	}
+
+	return strings.Join(goods, ", ")
+}
+
+// Describe returns a summary of a player's state.
+func (ps PlayerState) Describe() string {
+	ids := []string{}
+	for id := range ps.Items {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	items := []string{}
+	for _, id := range ids {
+		items = append(items, fmt.Sprintf("%s (ID: %s)", ps.Items[id].Name, id))
+	}
+
+	return fmt.Sprintf("room %d, online %t, %d gold, items [%s]", ps.RoomId, ps.Online, ps.Gold, strings.Join(items, ", "))
+}
+
+// player returns the state of a player, adding them if they're new.
+func (ws *WorldState) player(username string) *PlayerState {
+	player, exists := ws.Players[username]
+	if !exists {
+		player = &PlayerState{Items: make(map[string]Item)}
+		ws.Players[username] = player
+	}
+
+	return player
+}
+
+// room returns the state of a room, adding it if it's unknown.
+func (ws *WorldState) room(roomId int) *RoomState {
+	room, exists := ws.Rooms[roomId]
+	if !exists {
+		room = &RoomState{Items: []Item{}, Doors: make(map[string]bool), Merchants: make(map[string][]Item)}
+		ws.Rooms[roomId] = room
+	}
+
+	return room
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/internal/journal"
+)
+
+func TestJournalReplay(t *testing.T) {
+	src := NewRoom(1, "Hall", "A long hall.")
+	src.Doors = []Door{{Name: "east", MoveCommand: "east", RoomId: 2, IsLocked: true}}
+	src.Items = []Item{{Name: "Coin", Type: Trinket, SellingPrice: 5}}
+	src.RawNpcs = []any{map[string]any{"name": "Henry", "type": NpcMerchant}}
+
+	hall := NewRoom(1, "Hall", "A long hall.")
+	hall.Copy(src)
+
+	world := NewWorld()
+	world.rooms = append(world.rooms, hall)
+	world.roomMap[hall.ID] = hall
+
+	dir := t.TempDir()
+	j, err := journal.Open(dir)
+	assert.Nil(t, err)
+
+	g := NewGame(world)
+	stop := g.RecordTo(j)
+
+	alice := NewPlayer("alice", "Alice")
+	npc, _ := hall.GetNpcByName("Henry")
+	henry := npc.(*Merchant)
+
+	coin, _ := hall.RemoveItem("coin")
+	coin = alice.Inventory.Add(coin)
+
+	g.Events.Publish(PlayerEntered{Player: alice, RoomId: 1})
+	g.Events.Publish(ChatSaid{Player: alice, RoomId: 1, Text: "not journaled"})
+	g.Events.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: coin})
+	g.Events.Publish(ItemSold{Player: alice, Merchant: henry, RoomId: 1, Item: coin})
+	g.Events.Publish(DoorUnlocked{Player: alice, RoomId: 1, Door: hall.Doors[0]})
+	g.Events.Publish(GoldGiven{Player: alice, To: NewPlayer("bob", "Bob"), Amount: 2})
+	g.Events.Publish(Banked{Player: alice, Bank: "guild Owls", Gold: 3})
+	g.Events.Publish(Banked{Player: alice, Bank: "guild Owls", Gold: 1, Withdraw: true})
+
+	quill := Item{ID: "101", Name: "Quill", Type: Trinket, SellingPrice: 4}
+	g.Events.Publish(Traded{Player: alice, With: NewPlayer("bob", "Bob"), Gold: 1, WithItems: []TradedItem{{Item: quill, FromID: "102"}}})
+
+	stop()
+	g.Events.Publish(PlayerLeft{Player: alice, RoomId: 1})
+	assert.Nil(t, j.Close())
+
+	// Replaying from a snapshot of the world before anything happened
+	ws := NewWorldState(world)
+	ws.Rooms[1].Items = []Item{{Name: "Coin", Type: Trinket, SellingPrice: 5}}
+	ws.Rooms[1].Doors["east"] = true
+
+	descriptions := []string{}
+	err = journal.Read(dir, 0, func(entry journal.Entry) error {
+		description, username, err := ws.Apply(entry)
+		assert.Equal(t, "alice", username)
+		descriptions = append(descriptions, description)
+		return err
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"alice joined the game in room 1",
+		"alice picked up Coin (ID: 101) in room 1",
+		"alice sold Coin (ID: 101) to Henry for 5 gold",
+		"alice unlocked the east door in room 1",
+		"alice gave bob 2 gold",
+		"alice deposited 3 gold in guild Owls",
+		"alice withdrew 1 gold from guild Owls",
+		"alice traded 1 gold to bob for Quill",
+	}, descriptions)
+
+	assert.Equal(t, uint64(8), ws.Seq)
+	assert.Empty(t, ws.Rooms[1].Items)
+	assert.False(t, ws.Rooms[1].Doors["east"])
+	assert.Equal(t, []Item{coin}, ws.Rooms[1].Merchants["Henry"])
+	assert.Equal(t, "room 1, online true, 0 gold, items [Quill (ID: 101)]", ws.Players["alice"].Describe())
+	assert.Equal(t, 3, ws.Players["bob"].Gold)
+
+	// Snapshots round trip
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	assert.Nil(t, ws.Save(file))
+
+	loaded, err := LoadWorldState(file)
+	assert.Nil(t, err)
+	assert.Equal(t, ws, loaded)
+}
+
+func TestJournalReplayWorldLoaded(t *testing.T) {
+	hall := NewRoom(1, "Hall", "A long hall.")
+	hall.Items = []Item{{Name: "Coin", Type: Trinket, SellingPrice: 5}}
+
+	world := NewWorld()
+	world.rooms = append(world.rooms, hall)
+	world.roomMap[hall.ID] = hall
+
+	dir := t.TempDir()
+	j, err := journal.Open(dir)
+	assert.Nil(t, err)
+
+	g := NewGame(world)
+	alice := NewPlayer("alice", "Alice")
+	coin := alice.Inventory.Add(Item{Name: "Coin", Type: Trinket, SellingPrice: 5})
+
+	// The server runs twice, and the coin is back in the hall after restarting
+	for range 2 {
+		assert.Nil(t, g.RecordWorldLoaded(j))
+
+		stop := g.RecordTo(j)
+		g.Events.Publish(PlayerEntered{Player: alice, RoomId: 1})
+		g.Events.Publish(ItemPickedUp{Player: alice, RoomId: 1, Item: coin})
+		stop()
+	}
+
+	assert.Nil(t, j.Close())
+
+	ws := NewWorldState(NewWorld())
+
+	descriptions := []string{}
+	err = journal.Read(dir, 0, func(entry journal.Entry) error {
+		description, _, err := ws.Apply(entry)
+		descriptions = append(descriptions, description)
+		return err
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"the server started, resetting the world",
+		"alice joined the game in room 1",
+		"alice picked up Coin (ID: 101) in room 1",
+		"the server started, resetting the world",
+		"alice joined the game in room 1",
+		"alice picked up Coin (ID: 101) in room 1",
+	}, descriptions)
+
+	assert.Empty(t, ws.Rooms[1].Items)
+	assert.Equal(t, "room 1, online true, 0 gold, items [Coin (ID: 101)]", ws.Players["alice"].Describe())
+}
+
+func TestJournalReplayGap(t *testing.T) {
+	ws := NewWorldState(NewWorld())
+
+	_, _, err := ws.Apply(journal.Entry{Seq: 7, Type: EntryGap, Data: []byte(`{"lost":3}`)})
+	assert.EqualError(t, err, "the journal is missing 3 events before #7")
+}
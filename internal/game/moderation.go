@@ -0,0 +1,240 @@
+package game
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultChatRateLimit  = 5                // Chat messages a player can send per window
+	DefaultChatRateWindow = 10 * time.Second // Window chat messages are counted over
+	DefaultReportContext  = 20               // Recent messages kept per room for reports
+	MaxReports            = 100              // Reports kept, the oldest are forgotten first
+	StrikeExpiry          = time.Hour        // How long after their last mute a player's strikes are forgotten
+)
+
+// MuteEscalation is how long players are muted for going over the chat rate
+// limit, by how many times they've gone over it. The last duration repeats.
+var MuteEscalation = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute, time.Hour}
+
+// Report is a player's complaint about another player, along with what was
+// recently said in the reporter's room.
+type Report struct {
+	Reporter string
+	Target   string
+	RoomId   int
+	Reason   string
+	Time     time.Time
+	Context  []ChatMessage
+}
+
+// strikes counts how often a player went over the chat rate limit.
+type strikes struct {
+	count int
+	last  time.Time
+}
+
+// Moderation enforces chat rate limits and mutes, filters words out of chat
+// and keeps player reports. Players are identified by lowercase username so
+// mutes carry over between logins.
+type Moderation struct {
+	rateLimit  int
+	rateWindow time.Duration
+	filter     *regexp.Regexp // Nil when no words are filtered
+
+	sent     map[string][]time.Time // Username -> when they sent their recent messages
+	strikes  map[string]strikes
+	mutes    map[string]time.Time  // Username -> muted until
+	roomChat map[int][]ChatMessage // Room id -> recent messages
+	reports  []Report
+	now      func() time.Time
+	mutex    *sync.Mutex
+}
+
+// NewModeration creates a new Moderation instance with the default rate limit.
+func NewModeration() *Moderation {
+	return &Moderation{
+		rateLimit:  DefaultChatRateLimit,
+		rateWindow: DefaultChatRateWindow,
+		sent:       make(map[string][]time.Time),
+		strikes:    make(map[string]strikes),
+		mutes:      make(map[string]time.Time),
+		roomChat:   make(map[int][]ChatMessage),
+		now:        time.Now,
+		mutex:      &sync.Mutex{},
+	}
+}
+
+// SetRateLimit sets how many chat messages a player can send per window. A
+// limit of 0 disables rate limiting.
+func (m *Moderation) SetRateLimit(limit int, window time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.rateLimit = limit
+	m.rateWindow = window
+}
+
+// SetFilter sets the words replaced with asterisks in chat. Words only match
+// whole, ignoring case.
+func (m *Moderation) SetFilter(words []string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	quoted := []string{}
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+
+	if len(quoted) == 0 {
+		m.filter = nil
+		return
+	}
+
+	m.filter = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// Filter replaces filtered words in the text with asterisks.
+func (m *Moderation) Filter(text string) string {
+	m.mutex.Lock()
+	filter := m.filter
+	m.mutex.Unlock()
+
+	if filter == nil {
+		return text
+	}
+
+	return filter.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", len(word))
+	})
+}
+
+// Allow records a chat message from the player and checks they may send it.
+// Players who go over the rate limit are muted, for longer each time. When
+// the message isn't allowed, how long the player remains muted is returned.
+func (m *Moderation) Allow(username string) (time.Duration, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	username = strings.ToLower(username)
+	now := m.now()
+
+	if until, ok := m.mutes[username]; ok {
+		if now.Before(until) {
+			return until.Sub(now), false
+		}
+
+		delete(m.mutes, username)
+	}
+
+	if m.rateLimit <= 0 {
+		return 0, true
+	}
+
+	sent := m.sent[username]
+	for len(sent) > 0 && now.Sub(sent[0]) >= m.rateWindow {
+		sent = sent[1:]
+	}
+
+	if len(sent) < m.rateLimit {
+		m.sent[username] = append(sent, now)
+		return 0, true
+	}
+
+	delete(m.sent, username)
+
+	strike := m.strikes[username]
+	if now.Sub(strike.last) > StrikeExpiry {
+		strike.count = 0
+	}
+
+	duration := MuteEscalation[min(strike.count, len(MuteEscalation)-1)]
+	m.strikes[username] = strikes{count: strike.count + 1, last: now}
+	m.mutes[username] = now.Add(duration)
+
+	slog.Info("Player muted for flooding chat", "player", username, "duration", duration)
+
+	return duration, false
+}
+
+// Mute stops a player from chatting for a duration. A duration of 0 unmutes
+// them.
+func (m *Moderation) Mute(username string, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	username = strings.ToLower(username)
+
+	if duration <= 0 {
+		delete(m.mutes, username)
+		return
+	}
+
+	m.mutes[username] = m.now().Add(duration)
+}
+
+// MutedFor returns how much longer a player is muted for, 0 if they aren't.
+func (m *Moderation) MutedFor(username string) time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	until, ok := m.mutes[strings.ToLower(username)]
+	if !ok {
+		return 0
+	}
+
+	return max(until.Sub(m.now()), 0)
+}
+
+// RecordRoomChat keeps a message said in a room, so it can be attached to
+// reports.
+func (m *Moderation) RecordRoomChat(roomId int, message ChatMessage) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	chat := append(m.roomChat[roomId], message)
+	if len(chat) > DefaultReportContext {
+		chat = chat[len(chat)-DefaultReportContext:]
+	}
+
+	m.roomChat[roomId] = chat
+}
+
+// Report files a report, attaching what was recently said in the room. Only
+// the last MaxReports are kept.
+func (m *Moderation) Report(reporter string, target string, roomId int, reason string) Report {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	report := Report{
+		Reporter: reporter,
+		Target:   target,
+		RoomId:   roomId,
+		Reason:   reason,
+		Time:     m.now(),
+		Context:  append([]ChatMessage{}, m.roomChat[roomId]...),
+	}
+
+	m.reports = append(m.reports, report)
+	if len(m.reports) > MaxReports {
+		clear(m.reports[:len(m.reports)-MaxReports])
+		m.reports = m.reports[len(m.reports)-MaxReports:]
+	}
+
+	slog.Warn("Player reported", "reporter", reporter, "target", target, "roomId", roomId, "reason", reason)
+
+	return report
+}
+
+// Reports returns the reports filed, oldest first.
+func (m *Moderation) Reports() []Report {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]Report{}, m.reports...)
+}
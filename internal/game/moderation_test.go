@@ -0,0 +1,102 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerationRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	m := NewModeration()
+	m.SetRateLimit(2, 10*time.Second)
+	m.now = func() time.Time { return now }
+
+	type AllowTest struct {
+		advance time.Duration
+		allowed bool
+		muted   time.Duration
+	}
+
+	tests := []AllowTest{
+		{allowed: true},
+		{advance: time.Second, allowed: true},
+		{advance: time.Second, allowed: false, muted: MuteEscalation[0]},
+		{advance: 10 * time.Second, allowed: false, muted: MuteEscalation[0] - 10*time.Second},
+		{advance: MuteEscalation[0], allowed: true},
+		{allowed: true},
+		{allowed: false, muted: MuteEscalation[1]},
+		{advance: MuteEscalation[1], allowed: true},
+		{allowed: true},
+		{allowed: false, muted: MuteEscalation[2]},
+		// Strikes are forgotten after a while
+		{advance: StrikeExpiry + time.Second, allowed: true},
+		{allowed: true},
+		{allowed: false, muted: MuteEscalation[0]},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.advance)
+
+		muted, allowed := m.Allow("Alice")
+		assert.Equal(t, test.allowed, allowed, i)
+		assert.Equal(t, test.muted, muted, i)
+	}
+
+	assert.Equal(t, MuteEscalation[0], m.MutedFor("alice"))
+
+	m.Mute("alice", 0)
+	assert.Equal(t, time.Duration(0), m.MutedFor("alice"))
+
+	m.Mute("bob", time.Minute)
+	_, allowed := m.Allow("Bob")
+	assert.False(t, allowed)
+
+	// Without a limit players are only stopped by mutes
+	m.SetRateLimit(0, 0)
+	for range 10 {
+		_, allowed := m.Allow("carol")
+		assert.True(t, allowed)
+	}
+}
+
+func TestModerationFilter(t *testing.T) {
+	m := NewModeration()
+	assert.Equal(t, "darn it", m.Filter("darn it"))
+
+	m.SetFilter([]string{"darn", " heck ", ""})
+	assert.Equal(t, "**** it, what the ****? Darnation!", m.Filter("DARN it, what the heck? Darnation!"))
+
+	m.SetFilter(nil)
+	assert.Equal(t, "darn it", m.Filter("darn it"))
+}
+
+func TestModerationReport(t *testing.T) {
+	m := NewModeration()
+
+	for i := range DefaultReportContext + 5 {
+		m.RecordRoomChat(1, ChatMessage{Talker: "Bob", Text: string(rune('a' + i))})
+	}
+	m.RecordRoomChat(2, ChatMessage{Talker: "Carol", Text: "elsewhere"})
+
+	report := m.Report("alice", "bob", 1, "spamming")
+	assert.Equal(t, "bob", report.Target)
+	assert.Len(t, report.Context, DefaultReportContext)
+	assert.Equal(t, "f", report.Context[0].Text)
+
+	// Later chat isn't added to the report
+	m.RecordRoomChat(1, ChatMessage{Talker: "Bob", Text: "later"})
+	assert.Equal(t, []Report{report}, m.Reports())
+
+	// Only the latest reports are kept
+	for i := range MaxReports {
+		m.Report("alice", "bob", 1, fmt.Sprintf("again %d", i))
+	}
+
+	reports := m.Reports()
+	assert.Len(t, reports, MaxReports)
+	assert.Equal(t, "again 0", reports[0].Reason)
+}
@@ -2,6 +2,7 @@ package game
 
 const (
 	NpcMerchant string = "merchant"
+	NpcBanker   string = "banker"
 )
 
 // Npc interface represents a non-player character in the game.
@@ -0,0 +1,213 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// Out-of-band (GMCP) packages pushed to subscribed clients.
+const (
+	OOBCharVitals   = "Char.Vitals"
+	OOBCharItemsInv = "Char.Items.Inv"
+	OOBRoomInfo     = "Room.Info"
+	OOBCommChannel  = "Comm.Channel"
+)
+
+// OutOfBandConnection is implemented by connections able to carry structured
+// out-of-band data alongside regular text output.
+type OutOfBandConnection interface {
+	Connection
+
+	// WriteOutOfBand writes a package and its JSON data to the client.
+	WriteOutOfBand(pkg string, data []byte) error
+	// IsSubscribed checks if the client asked to receive the package.
+	IsSubscribed(pkg string) bool
+}
+
+// VitalsData is the Char.Vitals payload.
+type VitalsData struct {
+	Health    int `json:"hp"`
+	MaxHealth int `json:"maxhp"`
+	Gold      int `json:"gold"`
+}
+
+// InventoryItemData describes an item in the Char.Items.Inv payload.
+type InventoryItemData struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// InventoryData is the Char.Items.Inv payload.
+type InventoryData struct {
+	Items []InventoryItemData `json:"items"`
+}
+
+// RoomInfoData is the Room.Info payload. Exits map door names to room ids.
+// Players lists everyone else in the room.
+type RoomInfoData struct {
+	ID          int                 `json:"num"`
+	Name        string              `json:"name"`
+	Description string              `json:"desc"`
+	Exits       map[string]int      `json:"exits"`
+	Items       []InventoryItemData `json:"items"`
+	Players     []string            `json:"players"`
+	Npcs        []string            `json:"npcs"`
+}
+
+// ChannelData is the Comm.Channel payload.
+type ChannelData struct {
+	Channel string `json:"channel"`
+	Talker  string `json:"talker"`
+	Text    string `json:"text"`
+}
+
+// SendOutOfBand sends a package to the player if their connection supports
+// out-of-band data and they subscribed to it.
+func (p *Player) SendOutOfBand(pkg string, data any) error {
+	conn, ob, ok := p.outOfBandConnection(pkg)
+	if !ok {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s: %w", pkg, err)
+	}
+
+	return writeOutOfBand(conn, ob, pkg, payload)
+}
+
+// syncOutOfBand sends a package only if its data changed since it was last sent.
+func (p *Player) syncOutOfBand(pkg string, data any) error {
+	conn, ob, ok := p.outOfBandConnection(pkg)
+	if !ok {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s: %w", pkg, err)
+	}
+
+	p.oobMutex.Lock()
+	if p.oobSent[pkg] == string(payload) {
+		p.oobMutex.Unlock()
+		return nil
+	}
+	p.oobSent[pkg] = string(payload)
+	p.oobMutex.Unlock()
+
+	if err := writeOutOfBand(conn, ob, pkg, payload); err != nil {
+		// The package wasn't sent, so the next sync tries again
+		p.oobMutex.Lock()
+		delete(p.oobSent, pkg)
+		p.oobMutex.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// outOfBandConnection returns the player's connection and output queue if the
+// connection supports out-of-band data and they subscribed to the package.
+func (p *Player) outOfBandConnection(pkg string) (OutOfBandConnection, *outbox, bool) {
+	current, ob := p.connection()
+
+	conn, ok := current.(OutOfBandConnection)
+	if !ok || !conn.IsSubscribed(pkg) {
+		return nil, nil, false
+	}
+
+	return conn, ob, true
+}
+
+// writeOutOfBand queues a package behind the player's other output, or writes
+// it straight away when the connection has no output queue.
+func writeOutOfBand(conn OutOfBandConnection, ob *outbox, pkg string, payload []byte) error {
+	if ob != nil {
+		return ob.enqueue(outboxItem{kind: outboxOutOfBand, pkg: pkg, data: payload})
+	}
+
+	return conn.WriteOutOfBand(pkg, payload)
+}
+
+// ResetOutOfBand forgets what has been sent so the next sync pushes every package,
+// e.g. after the client changed its subscriptions.
+func (p *Player) ResetOutOfBand() {
+	p.oobMutex.Lock()
+	defer p.oobMutex.Unlock()
+
+	p.oobSent = make(map[string]string)
+}
+
+// SyncOutOfBand pushes the player's vitals, inventory and room info when they've changed.
+func (g Game) SyncOutOfBand(ps *Player) {
+	if _, ok := ps.GetConnection().(OutOfBandConnection); !ok {
+		return
+	}
+
+	vitals := VitalsData{
+		Health:    ps.Health,
+		MaxHealth: ps.MaxHealth,
+		Gold:      ps.Inventory.GetGold(),
+	}
+
+	if err := ps.syncOutOfBand(OOBCharVitals, vitals); err != nil {
+		slog.Error("failed to send out-of-band data", "package", OOBCharVitals, "error", err)
+	}
+
+	inventory := InventoryData{Items: []InventoryItemData{}}
+	for _, item := range ps.Inventory.GetItems() {
+		inventory.Items = append(inventory.Items, InventoryItemData{ID: item.ID, Name: item.Name})
+	}
+
+	sort.Slice(inventory.Items, func(i, j int) bool {
+		return inventory.Items[i].ID < inventory.Items[j].ID
+	})
+
+	if err := ps.syncOutOfBand(OOBCharItemsInv, inventory); err != nil {
+		slog.Error("failed to send out-of-band data", "package", OOBCharItemsInv, "error", err)
+	}
+
+	room, ok := g.World.GetRoomById(ps.RoomId())
+	if !ok {
+		return
+	}
+
+	info := RoomInfoData{
+		ID:          room.ID,
+		Name:        room.Name,
+		Description: room.Description,
+		Exits:       make(map[string]int),
+		Items:       []InventoryItemData{},
+		Players:     []string{},
+		Npcs:        []string{},
+	}
+
+	for _, door := range room.Doors {
+		info.Exits[door.Name] = door.RoomId
+	}
+
+	for _, item := range room.GetItems() {
+		info.Items = append(info.Items, InventoryItemData{ID: item.ID, Name: item.Name})
+	}
+
+	if g.Sm != nil {
+		for _, player := range g.Sm.GetPlayersInRoom(room.ID, ps.GetUUID()) {
+			info.Players = append(info.Players, player.DisplayName)
+		}
+
+		sort.Strings(info.Players)
+	}
+
+	for _, npc := range room.Npcs {
+		info.Npcs = append(info.Npcs, npc.GetData().Name)
+	}
+
+	if err := ps.syncOutOfBand(OOBRoomInfo, info); err != nil {
+		slog.Error("failed to send out-of-band data", "package", OOBRoomInfo, "error", err)
+	}
+}
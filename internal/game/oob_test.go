@@ -0,0 +1,96 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// oobConnection is an in-memory OutOfBandConnection subscribed to every package.
+type oobConnection struct {
+	*memoryConnection
+	packages []string
+}
+
+func (c *oobConnection) WriteOutOfBand(pkg string, data []byte) error {
+	c.packages = append(c.packages, pkg+" "+string(data))
+	return nil
+}
+
+func (c *oobConnection) IsSubscribed(pkg string) bool {
+	return true
+}
+
+func TestSyncOutOfBand(t *testing.T) {
+	world := NewWorld()
+	room := NewRoom(1, "Central Hub", "The bustling center of activity.")
+	room.Copy(&Room{Doors: []Door{{Name: "north", MoveCommand: "north", RoomId: 2}}})
+	world.roomMap[room.ID] = room
+
+	g := NewGame(world)
+	player := NewPlayer("alice", "Alice")
+	conn := &oobConnection{memoryConnection: newMemoryConnection()}
+	player.SetConnection(conn)
+
+	g.SyncOutOfBand(player)
+	assert.Equal(t, []string{
+		`Char.Vitals {"hp":100,"maxhp":100,"gold":0}`,
+		`Char.Items.Inv {"items":[]}`,
+		`Room.Info {"num":1,"name":"Central Hub","desc":"The bustling center of activity.","exits":{"north":2},"items":[],"players":[],"npcs":[]}`,
+	}, conn.packages)
+
+	// Nothing changed, so nothing is sent
+	g.SyncOutOfBand(player)
+	assert.Len(t, conn.packages, 3)
+
+	player.Inventory.Gold = 5
+	g.SyncOutOfBand(player)
+	assert.Len(t, conn.packages, 4)
+	assert.Equal(t, `Char.Vitals {"hp":100,"maxhp":100,"gold":5}`, conn.packages[3])
+
+	player.ResetOutOfBand()
+	g.SyncOutOfBand(player)
+	assert.Len(t, conn.packages, 7)
+}
+
+// slowOOBConnection is a slowConnection subscribed to every package, which
+// records packages alongside messages.
+type slowOOBConnection struct {
+	*slowConnection
+}
+
+func (c *slowOOBConnection) WriteOutOfBand(pkg string, data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.messages = append(c.messages, pkg+" "+string(data))
+	return nil
+}
+
+func (c *slowOOBConnection) IsSubscribed(pkg string) bool {
+	return true
+}
+
+func TestSyncOutOfBandQueued(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetOutputQueue(8, OverflowDrop)
+	player := NewPlayer("alice", "Alice")
+	conn := &slowOOBConnection{slowConnection: newSlowConnection()}
+
+	assert.Nil(t, sm.Register(player))
+	_, err := sm.Connect(player.GetUUID(), conn)
+	assert.Nil(t, err)
+
+	assert.Nil(t, player.WriteString("one"))
+	<-conn.writing
+
+	// Syncing waits behind the text already queued rather than overtaking it
+	assert.Nil(t, player.WriteString("two"))
+	assert.Nil(t, player.syncOutOfBand(OOBCharVitals, VitalsData{Health: 1}))
+	assert.Empty(t, conn.written())
+
+	close(conn.release)
+	player.Flush()
+
+	assert.Equal(t, []string{"one", "two", `Char.Vitals {"hp":1,"maxhp":0,"gold":0}`}, conn.written())
+}
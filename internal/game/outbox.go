@@ -0,0 +1,228 @@
+package game
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what happens when a player's output queue is full.
+type OverflowPolicy string
+
+const (
+	OverflowDrop       OverflowPolicy = "drop"       // Drop the message, the player misses it
+	OverflowDisconnect OverflowPolicy = "disconnect" // Close the connection, the player can resume their session
+
+	DefaultOutputQueueSize = 256         // Messages queued per player before the overflow policy applies
+	FlushTimeout           = time.Second // How long Flush waits for queued output to be written
+)
+
+// outboxKind is the kind of write an outboxItem makes.
+type outboxKind int
+
+const (
+	outboxMessage outboxKind = iota
+	outboxOutOfBand
+	outboxEphemeral
+	outboxFlush
+)
+
+// outboxItem is a write waiting in a player's output queue.
+type outboxItem struct {
+	kind    outboxKind
+	typ     MessageType
+	pkg     string
+	data    []byte
+	flushed chan struct{}
+}
+
+// OutputQueueStats describes the output queues of every connected player.
+type OutputQueueStats struct {
+	Queued      int   // Messages waiting to be written across every player
+	MaxDepth    int   // Messages waiting for the most backed up player
+	Dropped     int64 // Messages dropped because a queue was full
+	Disconnects int64 // Players disconnected because their queue was full
+}
+
+// outboxCounters holds the running OutputQueueStats totals.
+type outboxCounters struct {
+	dropped     atomic.Int64
+	disconnects atomic.Int64
+}
+
+// outbox queues output for a connection and writes it from its own goroutine,
+// so a slow client can't hold up whoever is broadcasting to them.
+type outbox struct {
+	conn     Connection
+	items    chan outboxItem
+	policy   OverflowPolicy
+	counters *outboxCounters
+	stop     chan struct{}
+	stopOnce *sync.Once
+}
+
+// newOutbox creates an outbox for the connection and starts its writer.
+func newOutbox(conn Connection, size int, policy OverflowPolicy, counters *outboxCounters) *outbox {
+	o := &outbox{
+		conn:     conn,
+		items:    make(chan outboxItem, size),
+		policy:   policy,
+		counters: counters,
+		stop:     make(chan struct{}),
+		stopOnce: &sync.Once{},
+	}
+
+	go o.run()
+
+	return o
+}
+
+// run writes queued items until the outbox is closed or the connection goes away.
+// Anything still queued when the outbox is closed is written first.
+func (o *outbox) run() {
+	for {
+		select {
+		case item := <-o.items:
+			o.write(item)
+		case <-o.conn.Context().Done():
+			return
+		case <-o.stop:
+			for {
+				select {
+				case item := <-o.items:
+					o.write(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write hands an item to the connection.
+func (o *outbox) write(item outboxItem) {
+	var err error
+
+	switch item.kind {
+	case outboxMessage:
+		err = o.conn.WriteMessage(item.typ, item.data)
+	case outboxOutOfBand:
+		if conn, ok := o.conn.(OutOfBandConnection); ok {
+			err = conn.WriteOutOfBand(item.pkg, item.data)
+		}
+	case outboxEphemeral:
+		if conn, ok := o.conn.(EphemeralConnection); ok {
+			err = conn.WriteEphemeral(item.pkg, item.data)
+		} else if conn, ok := o.conn.(OutOfBandConnection); ok {
+			err = conn.WriteOutOfBand(item.pkg, item.data)
+		}
+	case outboxFlush:
+		close(item.flushed)
+	}
+
+	if err != nil {
+		slog.Debug("failed to write queued output", "remote", o.conn.RemoteAddr(), "error", err)
+	}
+}
+
+// enqueue queues an item, applying the overflow policy if the queue is full.
+// Ephemeral items are always dropped rather than disconnecting the player.
+func (o *outbox) enqueue(item outboxItem) error {
+	select {
+	case <-o.stop:
+		// The player left, nothing more will be written
+		return nil
+	case o.items <- item:
+		return nil
+	default:
+	}
+
+	if o.policy == OverflowDisconnect && item.kind != outboxEphemeral {
+		if o.conn.Context().Err() == nil {
+			o.counters.disconnects.Add(1)
+			slog.Warn("Output queue full, disconnecting player", "remote", o.conn.RemoteAddr())
+			o.conn.Close("output queue overflow")
+		}
+
+		return nil
+	}
+
+	o.counters.dropped.Add(1)
+	return nil
+}
+
+// flush waits until everything queued so far has been written, or FlushTimeout passes.
+func (o *outbox) flush() {
+	flushed := make(chan struct{})
+
+	select {
+	case o.items <- outboxItem{kind: outboxFlush, flushed: flushed}:
+	case <-time.After(FlushTimeout):
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-o.conn.Context().Done():
+	case <-time.After(FlushTimeout):
+	}
+}
+
+// close stops the writer once it has written what's already queued.
+func (o *outbox) close() {
+	o.stopOnce.Do(func() {
+		close(o.stop)
+	})
+}
+
+// depth returns the number of queued items.
+func (o *outbox) depth() int {
+	return len(o.items)
+}
+
+// SetOutputQueue gives every player connected from now on an output queue of
+// the given size. A size of zero writes output directly to the connection.
+func (sm *SessionManager) SetOutputQueue(size int, policy OverflowPolicy) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.outputQueueSize = size
+	sm.overflowPolicy = policy
+}
+
+// OutputQueueStats returns the current depth of the output queues, and how often
+// they overflowed.
+func (sm *SessionManager) OutputQueueStats() OutputQueueStats {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	stats := OutputQueueStats{
+		Dropped:     sm.outboxCounters.dropped.Load(),
+		Disconnects: sm.outboxCounters.disconnects.Load(),
+	}
+
+	for _, ps := range sm.active {
+		_, ob := ps.connection()
+		if ob == nil {
+			continue
+		}
+
+		depth := ob.depth()
+		stats.Queued += depth
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+	}
+
+	return stats
+}
+
+// attach connects the player through conn, with an output queue if they're
+// enabled. The caller must hold the lock.
+func (sm *SessionManager) attach(ps *Player, conn Connection) {
+	var ob *outbox
+	if sm.outputQueueSize > 0 {
+		ob = newOutbox(conn, sm.outputQueueSize, sm.overflowPolicy, sm.outboxCounters)
+	}
+
+	ps.setConnection(conn, ob)
+}
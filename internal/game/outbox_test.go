@@ -0,0 +1,125 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowConnection is a Connection whose writes block until released.
+type slowConnection struct {
+	messages []string
+	mutex    sync.Mutex
+	writing  chan struct{} // Receives when a write starts
+	release  chan struct{} // Unblocks writes once closed
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newSlowConnection() *slowConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &slowConnection{
+		writing: make(chan struct{}, 1),
+		release: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (c *slowConnection) WriteMessage(typ MessageType, message []byte) error {
+	select {
+	case c.writing <- struct{}{}:
+	default:
+	}
+
+	<-c.release
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.messages = append(c.messages, string(message))
+	return nil
+}
+
+func (c *slowConnection) Close(reason string) error {
+	c.cancel()
+	return nil
+}
+
+func (c *slowConnection) RemoteAddr() string {
+	return "slow"
+}
+
+func (c *slowConnection) Context() context.Context {
+	return c.ctx
+}
+
+func (c *slowConnection) written() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string(nil), c.messages...)
+}
+
+// connectSlow connects a player through a slow connection, returning once the
+// writer is blocked on the first message.
+func connectSlow(t *testing.T, sm *SessionManager) (*Player, *slowConnection) {
+	player := NewPlayer("alice", "Alice")
+	conn := newSlowConnection()
+
+	assert.Nil(t, sm.Register(player))
+	_, err := sm.Connect(player.GetUUID(), conn)
+	assert.Nil(t, err)
+
+	assert.Nil(t, player.WriteString("one"))
+	<-conn.writing
+
+	return player, conn
+}
+
+func TestOutputQueueDrop(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetOutputQueue(2, OverflowDrop)
+
+	player, conn := connectSlow(t, sm)
+
+	// Writing doesn't wait for the slow client
+	assert.Nil(t, player.WriteString("two"))
+	assert.Nil(t, player.WriteString("three"))
+	assert.Nil(t, player.WriteString("four"))
+
+	stats := sm.OutputQueueStats()
+	assert.Equal(t, 2, stats.Queued)
+	assert.Equal(t, 2, stats.MaxDepth)
+	assert.Equal(t, int64(1), stats.Dropped)
+
+	close(conn.release)
+	player.Flush()
+
+	assert.Equal(t, []string{"one", "two", "three"}, conn.written())
+	assert.Equal(t, 0, sm.OutputQueueStats().Queued)
+	assert.Nil(t, conn.Context().Err())
+}
+
+func TestOutputQueueDisconnect(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetOutputQueue(1, OverflowDisconnect)
+
+	player, conn := connectSlow(t, sm)
+
+	// Ephemeral updates are dropped rather than disconnecting the player
+	assert.Nil(t, player.WriteString("two"))
+	assert.Nil(t, player.outbox.enqueue(outboxItem{kind: outboxEphemeral}))
+	assert.Nil(t, conn.Context().Err())
+
+	assert.Nil(t, player.WriteString("three"))
+	assert.NotNil(t, conn.Context().Err())
+
+	stats := sm.OutputQueueStats()
+	assert.Equal(t, int64(1), stats.Dropped)
+	assert.Equal(t, int64(1), stats.Disconnects)
+
+	close(conn.release)
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+const (
+	MaxPartySize = 6 // Players a party can hold, including its leader
+)
+
+var (
+	ErrorNotInParty     = errors.New("not in a party")
+	ErrorNotPartyLeader = errors.New("not the party leader")
+	ErrorAlreadyInParty = errors.New("already in a party")
+	ErrorPartyFull      = errors.New("party is full")
+	ErrorNoInvite       = errors.New("no party invite")
+)
+
+// party is a group of players following a leader. The leader is always the
+// first member.
+type party struct {
+	members   []*Player
+	splitGold bool
+}
+
+// Parties keeps track of which players are grouped together. Parties only
+// last while their members are online.
+type Parties struct {
+	parties map[string]*party  // Player uuid -> their party
+	invites map[string]*Player // Player uuid -> who last invited them
+	mutex   *sync.Mutex
+}
+
+// NewParties creates a new Parties instance.
+func NewParties() *Parties {
+	return &Parties{
+		parties: make(map[string]*party),
+		invites: make(map[string]*Player),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Invite invites a player to the leader's party. A leader who isn't in a
+// party yet forms one once the invite is accepted.
+func (p *Parties) Invite(leader *Player, target *Player) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.parties[target.GetUUID()]; ok {
+		return ErrorAlreadyInParty
+	}
+
+	if pt, ok := p.parties[leader.GetUUID()]; ok {
+		if pt.members[0] != leader {
+			return ErrorNotPartyLeader
+		}
+
+		if len(pt.members) >= MaxPartySize {
+			return ErrorPartyFull
+		}
+	}
+
+	p.invites[target.GetUUID()] = leader
+	return nil
+}
+
+// Accept joins the party of the player who last invited them, forming it if
+// needed, and returns its members before they joined.
+func (p *Parties) Accept(ps *Player) ([]*Player, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.parties[ps.GetUUID()]; ok {
+		return nil, ErrorAlreadyInParty
+	}
+
+	leader, ok := p.invites[ps.GetUUID()]
+	delete(p.invites, ps.GetUUID())
+
+	if !ok {
+		return nil, ErrorNoInvite
+	}
+
+	pt, ok := p.parties[leader.GetUUID()]
+	if !ok {
+		pt = &party{members: []*Player{leader}}
+		p.parties[leader.GetUUID()] = pt
+	}
+
+	// The leader may have joined someone else's party since the invite
+	if pt.members[0] != leader {
+		return nil, ErrorNoInvite
+	}
+
+	if len(pt.members) >= MaxPartySize {
+		return nil, ErrorPartyFull
+	}
+
+	members := append([]*Player{}, pt.members...)
+
+	pt.members = append(pt.members, ps)
+	p.parties[ps.GetUUID()] = pt
+
+	return members, nil
+}
+
+// Leave removes the player from their party, returning the members left
+// behind. The next member to have joined takes over as leader, and a party
+// left with a single member breaks up. Invites to and from the player are
+// dropped.
+func (p *Parties) Leave(ps *Player) ([]*Player, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.invites, ps.GetUUID())
+
+	for uuid, leader := range p.invites {
+		if leader == ps {
+			delete(p.invites, uuid)
+		}
+	}
+
+	return p.remove(ps)
+}
+
+// Kick removes a member from the leader's party, returning the members left
+// behind.
+func (p *Parties) Kick(leader *Player, target *Player) ([]*Player, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pt, ok := p.parties[leader.GetUUID()]
+	if !ok {
+		return nil, ErrorNotInParty
+	}
+
+	if pt.members[0] != leader {
+		return nil, ErrorNotPartyLeader
+	}
+
+	if p.parties[target.GetUUID()] != pt || target == leader {
+		return nil, ErrorNotInParty
+	}
+
+	return p.remove(target)
+}
+
+// SetSplitGold turns gold splitting on or off for the leader's party.
+func (p *Parties) SetSplitGold(leader *Player, on bool) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pt, ok := p.parties[leader.GetUUID()]
+	if !ok {
+		return ErrorNotInParty
+	}
+
+	if pt.members[0] != leader {
+		return ErrorNotPartyLeader
+	}
+
+	pt.splitGold = on
+	return nil
+}
+
+// Members returns the members of the player's party, leader first, and
+// whether the party splits gold. No members are returned if the player isn't
+// in a party.
+func (p *Parties) Members(ps *Player) ([]*Player, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pt, ok := p.parties[ps.GetUUID()]
+	if !ok {
+		return nil, false
+	}
+
+	return append([]*Player{}, pt.members...), pt.splitGold
+}
+
+// Followers returns the members who follow the player, none unless the
+// player leads a party.
+func (p *Parties) Followers(ps *Player) []*Player {
+	members, _ := p.Members(ps)
+	if len(members) == 0 || members[0] != ps {
+		return nil
+	}
+
+	return members[1:]
+}
+
+// remove takes the player out of their party. The caller must hold the lock.
+func (p *Parties) remove(ps *Player) ([]*Player, error) {
+	pt, ok := p.parties[ps.GetUUID()]
+	if !ok {
+		return nil, ErrorNotInParty
+	}
+
+	delete(p.parties, ps.GetUUID())
+
+	for i, member := range pt.members {
+		if member == ps {
+			pt.members = append(pt.members[:i:i], pt.members[i+1:]...)
+			break
+		}
+	}
+
+	remaining := append([]*Player{}, pt.members...)
+
+	if len(pt.members) == 1 {
+		delete(p.parties, pt.members[0].GetUUID())
+		pt.members = nil
+	}
+
+	return remaining, nil
+}
+
+// LeaveParty takes the player out of their party, telling the members left
+// behind. It's called when players leave the game, so parties never hold
+// players who are offline.
+func (g Game) LeaveParty(ps *Player) {
+	wasLeader := len(g.Parties.Followers(ps)) > 0
+
+	members, err := g.Parties.Leave(ps)
+	if err != nil {
+		return
+	}
+
+	g.TellParty(members, fmt.Sprintf("%s left the party.", ps.DisplayName))
+
+	switch {
+	case len(members) == 1:
+		g.TellParty(members, "The party broke up.")
+	case len(members) > 1 && wasLeader:
+		g.TellParty(members, fmt.Sprintf("%s now leads the party.", members[0].DisplayName))
+	}
+}
+
+// TellParty sends a notice to each of the party members.
+func (g Game) TellParty(members []*Player, text string) {
+	for _, member := range members {
+		if err := member.WriteString("[party] " + text + "\n"); err != nil {
+			slog.Error("failed to send to player", "player", member.DisplayName, "error", err)
+		}
+	}
+}
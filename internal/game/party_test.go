@@ -0,0 +1,101 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParties(t *testing.T) {
+	parties := NewParties()
+	alice, bob, carol := NewPlayer("alice", "Alice"), NewPlayer("bob", "Bob"), NewPlayer("carol", "Carol")
+
+	_, err := parties.Accept(bob)
+	assert.ErrorIs(t, err, ErrorNoInvite)
+
+	assert.Nil(t, parties.Invite(alice, bob))
+	assert.Nil(t, parties.Invite(alice, carol))
+
+	members, err := parties.Accept(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Player{alice}, members)
+
+	members, err = parties.Accept(carol)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Player{alice, bob}, members)
+
+	// Only the leader runs the party
+	assert.ErrorIs(t, parties.Invite(bob, NewPlayer("dave", "Dave")), ErrorNotPartyLeader)
+	assert.ErrorIs(t, parties.SetSplitGold(bob, true), ErrorNotPartyLeader)
+	assert.ErrorIs(t, parties.Invite(alice, bob), ErrorAlreadyInParty)
+
+	assert.Nil(t, parties.SetSplitGold(alice, true))
+	members, split := parties.Members(carol)
+	assert.Equal(t, []*Player{alice, bob, carol}, members)
+	assert.True(t, split)
+
+	assert.Equal(t, []*Player{bob, carol}, parties.Followers(alice))
+	assert.Empty(t, parties.Followers(bob))
+
+	// The next member to have joined leads when the leader leaves
+	members, err = parties.Leave(alice)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Player{bob, carol}, members)
+	assert.Equal(t, []*Player{carol}, parties.Followers(bob))
+
+	_, err = parties.Kick(carol, bob)
+	assert.ErrorIs(t, err, ErrorNotPartyLeader)
+
+	// A party left with one member breaks up
+	members, err = parties.Kick(bob, carol)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Player{bob}, members)
+
+	members, _ = parties.Members(bob)
+	assert.Empty(t, members)
+
+	_, err = parties.Leave(bob)
+	assert.ErrorIs(t, err, ErrorNotInParty)
+}
+
+func TestPartiesFull(t *testing.T) {
+	parties := NewParties()
+	leader := NewPlayer("leader", "Leader")
+
+	for range MaxPartySize - 1 {
+		member := NewPlayer("member", "Member")
+		assert.Nil(t, parties.Invite(leader, member))
+
+		_, err := parties.Accept(member)
+		assert.Nil(t, err)
+	}
+
+	assert.ErrorIs(t, parties.Invite(leader, NewPlayer("late", "Late")), ErrorPartyFull)
+}
+
+func TestPartiesInvite(t *testing.T) {
+	parties := NewParties()
+	alice, bob, carol := NewPlayer("alice", "Alice"), NewPlayer("bob", "Bob"), NewPlayer("carol", "Carol")
+
+	// Inviting doesn't form a party until the invite is accepted
+	assert.Nil(t, parties.Invite(alice, bob))
+
+	members, _ := parties.Members(alice)
+	assert.Empty(t, members)
+
+	// So the inviter can still join someone else's party, which voids the invite
+	assert.Nil(t, parties.Invite(carol, alice))
+	_, err := parties.Accept(alice)
+	assert.Nil(t, err)
+
+	_, err = parties.Accept(bob)
+	assert.ErrorIs(t, err, ErrorNoInvite)
+
+	// Invites from a player who leaves are dropped
+	assert.Nil(t, parties.Invite(carol, bob))
+	_, err = parties.Leave(carol)
+	assert.Nil(t, err)
+
+	_, err = parties.Accept(bob)
+	assert.ErrorIs(t, err, ErrorNoInvite)
+}
@@ -2,69 +2,189 @@ package game
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/quic-go/webtransport-go"
 )
 
+const (
+	DefaultMaxHealth = 100 // Health a new player starts with
+)
+
+// Role is what a player is allowed to do beyond playing the game.
+type Role string
+
+const (
+	RolePlayer    Role = ""
+	RoleBuilder   Role = "builder"
+	RoleAdmin     Role = "admin"
+	RoleModerator Role = "moderator"
+)
+
+// IsPrivileged checks if the role can use reserved player slots.
+func (r Role) IsPrivileged() bool {
+	return r == RoleAdmin || r == RoleBuilder
+}
+
+// CanModerate checks if the role can mute players and read reports.
+func (r Role) CanModerate() bool {
+	return r == RoleAdmin || r == RoleModerator
+}
+
 // Player represents a player in the game.
 type Player struct {
-	uuid          string
-	Username      string
-	DisplayName   string
-	CurrentRoomId int
-	Inventory     *Inventory
+	uuid        string
+	Username    string
+	DisplayName string
+	Role        Role
+	Health      int
+	MaxHealth   int
+	Inventory   *Inventory
 
-	session *webtransport.Session
-	stream  *webtransport.Stream
+	conn        Connection
+	outbox      *outbox           // Queues output for conn when output queues are enabled
+	resumeToken string            // Lets the player reattach after their connection drops
+	connMutex   *sync.RWMutex     // Guards conn, outbox and resumeToken, which change as the player reconnects
+	oobSent     map[string]string // Out-of-band package -> last payload sent
+	oobMutex    *sync.Mutex
+	lastActive  *atomic.Int64 // Unix nanoseconds of the last command
+	roomId      *atomic.Int64 // Room the player is in, read from any goroutine
+	hideJoins   *atomic.Bool  // Set when the player doesn't want game wide join and leave notices
 }
 
 // NewPlayer creates a new player with a unique UUID.
 func NewPlayer(username string, displayName string) *Player {
 	p := &Player{
-		uuid:          uuid.NewString(),
-		Username:      username,
-		DisplayName:   displayName,
-		CurrentRoomId: 1,
-		Inventory:     NewInventory(),
+		uuid:        uuid.NewString(),
+		Username:    username,
+		DisplayName: displayName,
+		Health:      DefaultMaxHealth,
+		MaxHealth:   DefaultMaxHealth,
+		Inventory:   NewInventory(),
+		connMutex:   &sync.RWMutex{},
+		oobSent:     make(map[string]string),
+		oobMutex:    &sync.Mutex{},
+		lastActive:  &atomic.Int64{},
+		roomId:      &atomic.Int64{},
+		hideJoins:   &atomic.Bool{},
 	}
 
+	p.roomId.Store(1)
 	p.Inventory.Initialize()
 
 	return p
 }
 
 // GetUUID returns the UUID of the player.
-func (p Player) GetUUID() string {
+func (p *Player) GetUUID() string {
 	return p.uuid
 }
 
-// SetSession sets the webtransport session for the player.
-func (p *Player) SetSession(session *webtransport.Session) {
-	p.session = session
+// SetConnection sets the connection the player is communicating through.
+// Output is written directly to the connection, any output queue is closed.
+func (p *Player) SetConnection(conn Connection) {
+	p.setConnection(conn, nil)
+}
+
+// setConnection sets the player's connection and the queue output to it goes
+// through, if any, closing the previous queue.
+func (p *Player) setConnection(conn Connection, ob *outbox) {
+	p.connMutex.Lock()
+	defer p.connMutex.Unlock()
+
+	if p.outbox != nil {
+		p.outbox.close()
+	}
+
+	p.conn = conn
+	p.outbox = ob
+}
+
+// connection returns the player's connection and its output queue, if any.
+func (p *Player) connection() (Connection, *outbox) {
+	p.connMutex.RLock()
+	defer p.connMutex.RUnlock()
+
+	return p.conn, p.outbox
+}
+
+// Flush waits for queued output to be written to the connection.
+func (p *Player) Flush() {
+	if _, ob := p.connection(); ob != nil {
+		ob.flush()
+	}
+}
+
+// Touch records that the player just did something.
+func (p *Player) Touch() {
+	p.lastActive.Store(time.Now().UnixNano())
+}
+
+// LastActive returns when the player last did something.
+func (p *Player) LastActive() time.Time {
+	return time.Unix(0, p.lastActive.Load())
 }
 
-// SetStream sets the webtransport stream for the player.
-func (p *Player) SetStream(stream *webtransport.Stream) {
-	p.stream = stream
+// RoomId returns the id of the room the player is in.
+func (p *Player) RoomId() int {
+	return int(p.roomId.Load())
 }
 
-// GetSession returns the webtransport session.
-func (p Player) GetSession() *webtransport.Session {
-	return p.session
+// SetRoomId places the player in a room. Players in the game should be moved
+// with Game.MovePlayer so the session manager's room index stays in sync.
+func (p *Player) SetRoomId(roomId int) {
+	p.roomId.Store(int64(roomId))
 }
 
-// GetStream returns the webtransport stream.
-func (p Player) GetStream() *webtransport.Stream {
-	return p.stream
+// WantsJoinNotices checks if the player wants to hear about players joining and
+// leaving the game outside their room.
+func (p *Player) WantsJoinNotices() bool {
+	return !p.hideJoins.Load()
 }
 
-// WriteString writes a string message to the player's stream.
-func (p Player) WriteString(message string) error {
-	if p.stream == nil {
-		return fmt.Errorf("player session (%s) stream is nil", p.uuid)
+// SetJoinNotices turns game wide join and leave notices on or off.
+func (p *Player) SetJoinNotices(on bool) {
+	p.hideJoins.Store(!on)
+}
+
+// GetConnection returns the player's connection.
+func (p *Player) GetConnection() Connection {
+	conn, _ := p.connection()
+	return conn
+}
+
+// WriteString writes a string message to the player's connection.
+func (p *Player) WriteString(message string) error {
+	return p.write(MessageOutput, []byte(message))
+}
+
+// WriteEvent writes a JSON encoded event to the player's connection.
+func (p *Player) WriteEvent(data []byte) error {
+	return p.write(MessageEvent, data)
+}
+
+// WritePrompt writes an input prompt to the player's connection.
+func (p *Player) WritePrompt(prompt string) error {
+	return p.write(MessagePrompt, []byte(prompt))
+}
+
+// WriteError writes an error message to the player's connection.
+func (p *Player) WriteError(message string) error {
+	return p.write(MessageError, []byte(message))
+}
+
+// write writes a typed message to the player's connection.
+func (p *Player) write(typ MessageType, message []byte) error {
+	conn, ob := p.connection()
+	if conn == nil {
+		return fmt.Errorf("player session (%s) connection is nil", p.uuid)
+	}
+
+	if ob != nil {
+		return ob.enqueue(outboxItem{kind: outboxMessage, typ: typ, data: message})
 	}
 
-	_, err := p.stream.Write([]byte(message))
-	return err
+	return conn.WriteMessage(typ, message)
 }
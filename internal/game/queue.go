@@ -0,0 +1,231 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLoginQueueSize = 100 // Players who can wait for a free slot
+
+	// Errors
+	ErrorQueueFull     SessionManagerErrorType = "LOGIN_QUEUE_FULL"
+	ErrorUsernameTaken SessionManagerErrorType = "USERNAME_TAKEN"
+)
+
+// queuedLogin is a player waiting for a free slot.
+type queuedLogin struct {
+	player   *Player
+	lastSeen time.Time // Last time the player asked for their position
+}
+
+// SetSlots sets the maximum number of players and how many of those slots are
+// reserved for privileged players. Waiting players are admitted if the limit grew.
+func (sm *SessionManager) SetSlots(maxSessions int, reserved int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.maxSessions = maxSessions
+	sm.reservedSlots = reserved
+	sm.admitQueued()
+}
+
+// SetLoginQueueSize sets how many players can wait for a free slot. Zero disables the queue.
+func (sm *SessionManager) SetLoginQueueSize(size int) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.queueSize = size
+}
+
+// Join registers the player if there's a free slot, otherwise they're put in the
+// login queue. The returned position is 0 once the player can connect, or their
+// place in the queue. Privileged players wait ahead of everyone else. Only one
+// player can use a name at a time: a name already playing is refused, while a
+// session waiting to resume and an earlier login which hasn't connected yet are
+// replaced. The caller must have checked the player's password.
+func (sm *SessionManager) Join(player *Player) (int, error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for {
+		uuid, taken := sm.usernames[strings.ToLower(player.Username)]
+		if !taken {
+			break
+		}
+
+		session, detached := sm.detached[uuid]
+		if !detached {
+			return 0, &SessionManagerError{Type: ErrorUsernameTaken, Message: fmt.Sprintf("%s is already playing", player.Username), Wrapped: nil}
+		}
+
+		// The player dropped without resuming, so their old session ends
+		// rather than keeping them out for the rest of the grace period
+		sm.mutex.Unlock()
+		sm.expire(uuid, session.conn)
+		sm.mutex.Lock()
+	}
+
+	sm.dropLogins(player.Username)
+
+	if sm.hasFreeSlot(player) {
+		sm.addPending(player)
+		return 0, nil
+	}
+
+	if len(sm.queue) >= sm.queueSize {
+		if sm.queueSize == 0 {
+			return 0, &SessionManagerError{Type: ErrorMaxPlayers, Message: "Max player limit reached, please try again", Wrapped: nil}
+		}
+
+		return 0, &SessionManagerError{Type: ErrorQueueFull, Message: "The login queue is full, please try again later", Wrapped: nil}
+	}
+
+	position := len(sm.queue)
+	if player.Role.IsPrivileged() {
+		for i, queued := range sm.queue {
+			if !queued.player.Role.IsPrivileged() {
+				position = i
+				break
+			}
+		}
+	}
+
+	sm.queue = append(sm.queue, nil)
+	copy(sm.queue[position+1:], sm.queue[position:])
+	sm.queue[position] = &queuedLogin{player: player, lastSeen: time.Now()}
+
+	sm.notifyQueue()
+	return position + 1, nil
+}
+
+// QueuePosition returns the player's place in the login queue, or 0 once they've
+// been admitted and can connect. False is returned if the player is unknown, or
+// waited too long to connect.
+func (sm *SessionManager) QueuePosition(uuid string) (int, bool) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if _, exists := sm.Pending[uuid]; exists {
+		return 0, true
+	}
+
+	for i, queued := range sm.queue {
+		if queued.player.GetUUID() == uuid {
+			queued.lastSeen = time.Now()
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// QueueLength returns the number of players waiting for a free slot.
+func (sm *SessionManager) QueueLength() int {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return len(sm.queue)
+}
+
+// QueueChanged returns a channel which is closed the next time the login queue changes.
+func (sm *SessionManager) QueueChanged() <-chan struct{} {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return sm.queueChanged
+}
+
+// LeaveQueue removes a player from the login queue.
+func (sm *SessionManager) LeaveQueue(uuid string) bool {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for i, queued := range sm.queue {
+		if queued.player.GetUUID() == uuid {
+			sm.queue = append(sm.queue[:i], sm.queue[i+1:]...)
+			sm.notifyQueue()
+			return true
+		}
+	}
+
+	return false
+}
+
+// dropLogins removes pending and queued logins for the name, which haven't
+// connected yet. The caller must hold the lock.
+func (sm *SessionManager) dropLogins(username string) {
+	for uuid, ps := range sm.Pending {
+		if strings.EqualFold(ps.Username, username) {
+			delete(sm.Pending, uuid)
+			delete(sm.pendingSince, uuid)
+		}
+	}
+
+	for i, queued := range sm.queue {
+		if strings.EqualFold(queued.player.Username, username) {
+			sm.queue = append(sm.queue[:i], sm.queue[i+1:]...)
+			sm.notifyQueue()
+			return
+		}
+	}
+}
+
+// hasFreeSlot checks if the player can take a slot, pending logins included.
+// Reserved slots are only available to privileged players. The caller must hold the lock.
+func (sm *SessionManager) hasFreeSlot(player *Player) bool {
+	limit := sm.maxSessions
+	if !player.Role.IsPrivileged() {
+		limit -= sm.reservedSlots
+	}
+
+	return len(sm.active)+len(sm.Pending) < limit
+}
+
+// addPending adds the player to the pending list. The caller must hold the lock.
+func (sm *SessionManager) addPending(player *Player) {
+	sm.Pending[player.GetUUID()] = player
+	sm.pendingSince[player.GetUUID()] = time.Now()
+}
+
+// admitQueued moves waiting players into the pending list while there are free
+// slots. Privileged players wait at the front, so once the head of the queue
+// can't be admitted nobody behind them can be either. The caller must hold the lock.
+func (sm *SessionManager) admitQueued() {
+	admitted := false
+
+	for len(sm.queue) > 0 && sm.hasFreeSlot(sm.queue[0].player) {
+		sm.addPending(sm.queue[0].player)
+		sm.queue = sm.queue[1:]
+		admitted = true
+	}
+
+	if admitted {
+		sm.notifyQueue()
+	}
+}
+
+// expireQueued drops waiting players who stopped asking for their position.
+// The caller must hold the lock.
+func (sm *SessionManager) expireQueued(now time.Time) {
+	remaining := sm.queue[:0]
+
+	for _, queued := range sm.queue {
+		if now.Sub(queued.lastSeen) < sm.pendingTTL {
+			remaining = append(remaining, queued)
+		}
+	}
+
+	if len(remaining) != len(sm.queue) {
+		clear(sm.queue[len(remaining):])
+		sm.queue = remaining
+		sm.notifyQueue()
+	}
+}
+
+// notifyQueue wakes anyone waiting on QueueChanged. The caller must hold the lock.
+func (sm *SessionManager) notifyQueue() {
+	close(sm.queueChanged)
+	sm.queueChanged = make(chan struct{})
+}
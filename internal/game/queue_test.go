@@ -0,0 +1,173 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginQueue(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetSlots(2, 1)
+
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+	carol := NewPlayer("carol", "Carol")
+	admin := NewPlayer("admin", "Admin")
+	admin.Role = RoleAdmin
+
+	// One slot is reserved, so regular players start queueing after the first
+	position, err := sm.Join(alice)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, position)
+
+	position, err = sm.Join(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, position)
+
+	position, err = sm.Join(carol)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, position)
+
+	// The reserved slot is still free for privileged players
+	position, err = sm.Join(admin)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, position)
+
+	_, err = sm.Connect(alice.GetUUID(), newMemoryConnection())
+	assert.Nil(t, err)
+	assert.True(t, sm.RemovePlayer(alice.GetUUID()))
+
+	// Privileged players count against every player
+	position, ok := sm.QueuePosition(bob.GetUUID())
+	assert.True(t, ok)
+	assert.Equal(t, 1, position)
+
+	changed := sm.QueueChanged()
+
+	// Freeing a slot admits the head of the queue
+	assert.True(t, sm.RemovePending(admin.GetUUID()))
+	<-changed
+
+	position, ok = sm.QueuePosition(bob.GetUUID())
+	assert.True(t, ok)
+	assert.Equal(t, 0, position)
+
+	position, ok = sm.QueuePosition(carol.GetUUID())
+	assert.True(t, ok)
+	assert.Equal(t, 1, position)
+	assert.Equal(t, 1, sm.QueueLength())
+
+	_, err = sm.Connect(bob.GetUUID(), newMemoryConnection())
+	assert.Nil(t, err)
+
+	assert.True(t, sm.LeaveQueue(carol.GetUUID()))
+	assert.False(t, sm.LeaveQueue(carol.GetUUID()))
+
+	_, ok = sm.QueuePosition(carol.GetUUID())
+	assert.False(t, ok)
+}
+
+func TestLoginQueuePriority(t *testing.T) {
+	sm := NewSessionManager(1)
+	sm.SetLoginQueueSize(3)
+
+	players := []*Player{NewPlayer("alice", "Alice"), NewPlayer("bob", "Bob"), NewPlayer("carol", "Carol")}
+	players[2].Role = RoleBuilder
+
+	for _, player := range players {
+		_, err := sm.Join(player)
+		assert.Nil(t, err)
+	}
+
+	// Builders wait ahead of regular players
+	position, _ := sm.QueuePosition(players[2].GetUUID())
+	assert.Equal(t, 1, position)
+
+	position, _ = sm.QueuePosition(players[1].GetUUID())
+	assert.Equal(t, 2, position)
+
+	_, err := sm.Join(NewPlayer("dave", "Dave"))
+	assert.Nil(t, err)
+
+	_, err = sm.Join(NewPlayer("erin", "Erin"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), string(ErrorQueueFull))
+
+	// Waiting players who stop asking for their position lose it
+	sm.Reap(time.Now().Add(time.Hour))
+	assert.Equal(t, 0, sm.QueueLength())
+}
+
+func TestLoginUsernameTaken(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetLoginQueueSize(1)
+
+	alice := NewPlayer("alice", "Alice")
+
+	_, err := sm.Join(alice)
+	assert.Nil(t, err)
+
+	// A login which hasn't connected yet is replaced by the next one
+	again := NewPlayer("Alice", "Alice")
+
+	_, err = sm.Join(again)
+	assert.Nil(t, err)
+
+	_, ok := sm.QueuePosition(alice.GetUUID())
+	assert.False(t, ok)
+
+	conn := newMemoryConnection()
+	_, err = sm.Connect(again.GetUUID(), conn)
+	assert.Nil(t, err)
+
+	found, ok := sm.FindPlayer("ALICE")
+	assert.True(t, ok)
+	assert.Equal(t, again, found)
+
+	// Once connected, nobody else can use the name
+	_, err = sm.Join(NewPlayer("ALICE", "Alice"))
+
+	smErr := &SessionManagerError{}
+	assert.ErrorAs(t, err, &smErr)
+	assert.Equal(t, ErrorUsernameTaken, smErr.Type)
+
+	// Logging in again after dropping ends the session waiting to resume
+	sm.SetResumeGracePeriod(time.Minute)
+	assert.True(t, sm.Detach(again.GetUUID(), conn))
+
+	back := NewPlayer("alice", "Alice")
+	position, err := sm.Join(back)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, position)
+
+	_, ok = sm.GetSession(again.GetUUID())
+	assert.False(t, ok)
+
+	_, ok = sm.FindPlayer("alice")
+	assert.False(t, ok)
+
+	_, err = sm.Connect(back.GetUUID(), newMemoryConnection())
+	assert.Nil(t, err)
+
+	// Queued logins are replaced too
+	bob := NewPlayer("bob", "Bob")
+	_, err = sm.Join(bob)
+	assert.Nil(t, err)
+
+	_, err = sm.Connect(bob.GetUUID(), newMemoryConnection())
+	assert.Nil(t, err)
+
+	carol := NewPlayer("carol", "Carol")
+	position, err = sm.Join(carol)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, position)
+
+	position, err = sm.Join(NewPlayer("carol", "Carol"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, position)
+
+	_, ok = sm.QueuePosition(carol.GetUUID())
+	assert.False(t, ok)
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPendingTTL     = 2 * time.Minute  // How long a login can wait before connecting
+	DefaultIdleTimeout    = 30 * time.Minute // How long a connected player can go without sending a command
+	DefaultIdleWarning    = time.Minute      // How long before an idle disconnect the player is warned
+	DefaultReaperInterval = 5 * time.Second  // How often stale sessions are looked for
+
+	MessageIdleWarning    = "You have been idle for a while and will be disconnected in %d seconds.\n"
+	MessageIdleDisconnect = "You have been disconnected for being idle.\n"
+)
+
+// ReaperStats counts the sessions cleaned up by the reaper.
+type ReaperStats struct {
+	PendingExpired   int64 // Logins that never connected
+	IdleWarnings     int64 // Warnings sent to idle players
+	IdleDisconnected int64 // Players disconnected for being idle
+}
+
+// reaperCounters holds the running ReaperStats totals.
+type reaperCounters struct {
+	pendingExpired   atomic.Int64
+	idleWarnings     atomic.Int64
+	idleDisconnected atomic.Int64
+}
+
+// SetPendingTTL sets how long a login can wait before connecting. A zero or
+// negative TTL keeps pending sessions forever.
+func (sm *SessionManager) SetPendingTTL(ttl time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.pendingTTL = ttl
+}
+
+// SetIdleTimeout sets how long a connected player can go without sending a command,
+// and how long before the disconnect they're warned. A zero or negative timeout
+// disables idle disconnects.
+func (sm *SessionManager) SetIdleTimeout(timeout time.Duration, warning time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.idleTimeout = timeout
+	sm.idleWarning = warning
+}
+
+// GetPendingCount returns the number of logins waiting to connect.
+func (sm *SessionManager) GetPendingCount() int {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	return len(sm.Pending)
+}
+
+// ReaperStats returns the number of sessions cleaned up so far.
+func (sm *SessionManager) ReaperStats() ReaperStats {
+	return ReaperStats{
+		PendingExpired:   sm.counters.pendingExpired.Load(),
+		IdleWarnings:     sm.counters.idleWarnings.Load(),
+		IdleDisconnected: sm.counters.idleDisconnected.Load(),
+	}
+}
+
+// Reap removes pending sessions older than the pending TTL, along with queued
+// players who stopped asking for their position. It warns players who are about
+// to time out and disconnects warned players who have been idle too long.
+// Players waiting to resume are left to their grace period.
+func (sm *SessionManager) Reap(now time.Time) {
+	idle := []*Player{}
+	warn := []*Player{}
+
+	sm.mutex.Lock()
+
+	if sm.pendingTTL > 0 {
+		for uuid, registered := range sm.pendingSince {
+			if now.Sub(registered) < sm.pendingTTL {
+				continue
+			}
+
+			delete(sm.Pending, uuid)
+			delete(sm.pendingSince, uuid)
+			sm.counters.pendingExpired.Add(1)
+		}
+
+		sm.expireQueued(now)
+		sm.admitQueued()
+	}
+
+	if sm.idleTimeout > 0 {
+		for _, ps := range sm.active {
+			if _, detached := sm.detached[ps.GetUUID()]; detached {
+				continue
+			}
+
+			lastActive := ps.LastActive()
+			idleFor := now.Sub(lastActive)
+
+			// The warning is remembered against the activity it was sent for, so
+			// it's sent again if the player becomes active and idles again.
+			warned := sm.idleWarned[ps.GetUUID()].Equal(lastActive)
+
+			if idleFor >= sm.idleTimeout && warned {
+				idle = append(idle, ps)
+			} else if idleFor >= sm.idleTimeout-sm.idleWarning && !warned {
+				// Players are always warned first, even if a sweep was missed
+				warn = append(warn, ps)
+				sm.idleWarned[ps.GetUUID()] = lastActive
+			}
+		}
+	}
+
+	remaining := sm.idleWarning
+	sm.mutex.Unlock()
+
+	for _, ps := range warn {
+		sm.counters.idleWarnings.Add(1)
+
+		if err := ps.WriteString(fmt.Sprintf(MessageIdleWarning, int(remaining.Seconds()))); err != nil {
+			slog.Error("failed to send to player", "player", ps.DisplayName, "error", err)
+		}
+	}
+
+	for _, ps := range idle {
+		conn := ps.GetConnection()
+
+		if err := ps.WriteString(MessageIdleDisconnect); err != nil {
+			slog.Error("failed to send to player", "player", ps.DisplayName, "error", err)
+		}
+
+		ps.Flush()
+
+		// Leave first so closing the connection doesn't keep the player around to resume
+		sm.Leave(ps.GetUUID())
+		sm.counters.idleDisconnected.Add(1)
+
+		if conn != nil {
+			conn.Close("idle timeout")
+		}
+
+		slog.Info("Disconnected idle player", "player", ps.DisplayName)
+	}
+}
+
+// StartReaper calls Reap every DefaultReaperInterval until the context is cancelled.
+func (sm *SessionManager) StartReaper(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(DefaultReaperInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sm.Reap(now)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReapPending(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetPendingTTL(time.Minute)
+
+	player := NewPlayer("alice", "Alice")
+	assert.Nil(t, sm.Register(player))
+	assert.Equal(t, 1, sm.GetPendingCount())
+
+	sm.Reap(time.Now())
+	assert.Equal(t, 1, sm.GetPendingCount())
+
+	sm.Reap(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, 0, sm.GetPendingCount())
+	assert.Equal(t, int64(1), sm.ReaperStats().PendingExpired)
+
+	_, err := sm.Connect(player.GetUUID(), newMemoryConnection())
+	assert.NotNil(t, err)
+}
+
+func TestReapIdle(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetIdleTimeout(10*time.Minute, time.Minute)
+
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+
+	aliceConn := newMemoryConnection()
+	bobConn := newMemoryConnection()
+
+	for player, conn := range map[*Player]Connection{alice: aliceConn, bob: bobConn} {
+		assert.Nil(t, sm.Register(player))
+		_, err := sm.Connect(player.GetUUID(), conn)
+		assert.Nil(t, err)
+	}
+
+	now := time.Now()
+	warning := fmt.Sprintf(MessageIdleWarning, 60)
+
+	// Warned once when the timeout is near
+	sm.Reap(now.Add(9*time.Minute + 30*time.Second))
+	sm.Reap(now.Add(9*time.Minute + 40*time.Second))
+	assert.Equal(t, []string{warning}, aliceConn.messages)
+	assert.Equal(t, int64(2), sm.ReaperStats().IdleWarnings)
+
+	// Activity resets the clock
+	bob.lastActive.Store(now.Add(9 * time.Minute).UnixNano())
+
+	sm.Reap(now.Add(10 * time.Minute))
+	assert.Equal(t, []string{warning, MessageIdleDisconnect}, aliceConn.messages)
+	assert.Equal(t, []string{warning, "Alice has left the game.\n"}, bobConn.messages)
+	assert.True(t, aliceConn.closed)
+	assert.False(t, bobConn.closed)
+	assert.Equal(t, 1, sm.GetActiveSessionCount())
+	assert.Equal(t, int64(1), sm.ReaperStats().IdleDisconnected)
+
+	// Players waiting to resume are left to their grace period
+	assert.True(t, sm.Detach(bob.GetUUID(), bobConn))
+
+	// Players are warned before being disconnected, even when the warning was missed
+	carol := NewPlayer("carol", "Carol")
+	carolConn := newMemoryConnection()
+	assert.Nil(t, sm.Register(carol))
+	_, err := sm.Connect(carol.GetUUID(), carolConn)
+	assert.Nil(t, err)
+
+	sm.Reap(now.Add(time.Hour))
+	assert.Equal(t, []string{warning}, carolConn.messages)
+	assert.False(t, carolConn.closed)
+
+	sm.Reap(now.Add(time.Hour))
+	assert.True(t, carolConn.closed)
+	assert.Equal(t, 1, sm.GetActiveSessionCount())
+}
@@ -0,0 +1,262 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultResumeGracePeriod = 60 * time.Second // How long a dropped player can resume their session
+	MaxReplayMessages        = 200              // Messages kept for replay while a player is disconnected
+)
+
+// detachedMessage is a message written while the player was disconnected.
+type detachedMessage struct {
+	typ     MessageType
+	message []byte
+}
+
+// detachedConnection stands in for a dropped connection until the player
+// resumes or the grace period runs out, recording output for replay.
+type detachedConnection struct {
+	remoteAddr string
+	messages   []detachedMessage
+	mutex      *sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// newDetachedConnection creates a new detachedConnection instance.
+func newDetachedConnection(remoteAddr string) *detachedConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &detachedConnection{
+		remoteAddr: remoteAddr,
+		mutex:      &sync.Mutex{},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// WriteMessage records the message, dropping the oldest once MaxReplayMessages
+// is reached. Prompts aren't worth replaying.
+func (c *detachedConnection) WriteMessage(typ MessageType, message []byte) error {
+	if typ == MessagePrompt {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.messages = append(c.messages, detachedMessage{typ: typ, message: append([]byte(nil), message...)})
+	if len(c.messages) > MaxReplayMessages {
+		c.messages = c.messages[len(c.messages)-MaxReplayMessages:]
+	}
+
+	return nil
+}
+
+// Close releases the connection.
+func (c *detachedConnection) Close(reason string) error {
+	c.cancel()
+	return nil
+}
+
+// RemoteAddr returns the address the player was last connected from.
+func (c *detachedConnection) RemoteAddr() string {
+	return c.remoteAddr
+}
+
+// Context returns a context which is cancelled once the player resumes or leaves.
+func (c *detachedConnection) Context() context.Context {
+	return c.ctx
+}
+
+// replay writes the recorded messages to the player's new connection.
+func (c *detachedConnection) replay(ps *Player) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, m := range c.messages {
+		if err := ps.write(m.typ, m.message); err != nil {
+			return err
+		}
+	}
+
+	c.messages = nil
+	return nil
+}
+
+// detachedSession tracks a disconnected player during the grace period.
+type detachedSession struct {
+	conn  *detachedConnection
+	timer *time.Timer
+}
+
+// SetResumeGracePeriod sets how long a dropped player can resume their session.
+// A zero or negative period disables resuming.
+func (sm *SessionManager) SetResumeGracePeriod(period time.Duration) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.gracePeriod = period
+}
+
+// issueResumeToken gives the player a new resume token, invalidating the previous one.
+// The caller must hold the lock.
+func (sm *SessionManager) issueResumeToken(ps *Player) {
+	token := uuid.NewString()
+
+	delete(sm.resumeTokens, ps.swapResumeToken(token))
+	sm.resumeTokens[token] = ps.GetUUID()
+}
+
+// Detach keeps the player in the game after their connection dropped, so they
+// can resume within the grace period. The player leaves the game once it runs out.
+// Nothing happens if the player already moved to another connection.
+func (sm *SessionManager) Detach(uuid string, conn Connection) bool {
+	sm.mutex.Lock()
+
+	ps := sm.findActive(uuid)
+	if ps == nil || ps.GetConnection() != conn {
+		sm.mutex.Unlock()
+		return false
+	}
+
+	if sm.gracePeriod <= 0 {
+		sm.mutex.Unlock()
+		sm.Leave(uuid)
+		return true
+	}
+
+	detached := newDetachedConnection(conn.RemoteAddr())
+
+	delete(sm.sessionMap, conn)
+	sm.sessionMap[detached] = uuid
+	ps.SetConnection(detached)
+
+	session := &detachedSession{conn: detached}
+	session.timer = time.AfterFunc(sm.gracePeriod, func() {
+		sm.expire(uuid, detached)
+	})
+
+	sm.detached[uuid] = session
+	hooks := sm.detachHooks
+	sm.mutex.Unlock()
+
+	slog.Info("Player disconnected, waiting for them to resume", "player", ps.DisplayName, "grace", sm.gracePeriod)
+
+	for _, hook := range hooks {
+		hook(ps)
+	}
+
+	return true
+}
+
+// expire removes a detached player whose grace period ran out.
+func (sm *SessionManager) expire(uuid string, conn *detachedConnection) {
+	sm.mutex.RLock()
+	session, exists := sm.detached[uuid]
+	sm.mutex.RUnlock()
+
+	if !exists || session.conn != conn {
+		return
+	}
+
+	sm.Leave(uuid)
+}
+
+// Resume moves a player to a new connection using their resume token, replaying
+// anything they missed. If the old connection is still open it's closed.
+func (sm *SessionManager) Resume(token string, conn Connection) (*Player, error) {
+	sm.mutex.Lock()
+
+	uuid, exists := sm.resumeTokens[token]
+	if !exists {
+		sm.mutex.Unlock()
+		return nil, fmt.Errorf("invalid or expired resume token")
+	}
+
+	ps := sm.findActive(uuid)
+	if ps == nil {
+		sm.mutex.Unlock()
+		return nil, fmt.Errorf("invalid or expired resume token")
+	}
+
+	previous := ps.GetConnection()
+	session := sm.detached[uuid]
+
+	if previous != nil {
+		delete(sm.sessionMap, previous)
+	}
+
+	if session != nil {
+		session.timer.Stop()
+		delete(sm.detached, uuid)
+	}
+
+	sm.attach(ps, conn)
+	sm.sessionMap[conn] = uuid
+	sm.issueResumeToken(ps)
+	sm.mutex.Unlock()
+
+	if session != nil {
+		defer session.conn.Close("session resumed")
+
+		if err := session.conn.replay(ps); err != nil {
+			return ps, fmt.Errorf("unable to replay missed output: %w", err)
+		}
+	} else if previous != nil {
+		previous.Close("session resumed on another connection")
+	}
+
+	slog.Info("Player resumed their session", "player", ps.DisplayName)
+	return ps, nil
+}
+
+// Leave removes the player from the game and publishes PlayerLeft. Without an
+// event bus the player's room is told directly.
+func (sm *SessionManager) Leave(uuid string) {
+	ps, ok := sm.getPlayer(uuid)
+	if !ok || !sm.RemovePlayer(uuid) {
+		return
+	}
+
+	slog.Info("Player left the game", "player", ps.DisplayName)
+
+	if sm.events != nil {
+		sm.events.Publish(PlayerLeft{Player: ps, RoomId: ps.RoomId()})
+		return
+	}
+
+	sm.SendToRoom(ps.RoomId(), uuid, fmt.Sprintf("%s has left the game.\n", ps.DisplayName))
+}
+
+// WriteResumeToken sends the player's resume token to their connection.
+func (p *Player) WriteResumeToken() error {
+	return p.write(MessageSession, []byte(p.getResumeToken()))
+}
+
+// getResumeToken returns the player's current resume token.
+func (p *Player) getResumeToken() string {
+	p.connMutex.RLock()
+	defer p.connMutex.RUnlock()
+
+	return p.resumeToken
+}
+
+// swapResumeToken sets the player's resume token, returning the previous one.
+func (p *Player) swapResumeToken(token string) string {
+	p.connMutex.Lock()
+	defer p.connMutex.Unlock()
+
+	previous := p.resumeToken
+	p.resumeToken = token
+
+	return previous
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionManagerResume(t *testing.T) {
+	sm := NewSessionManager(2)
+	player := NewPlayer("alice", "Alice")
+
+	assert.Nil(t, sm.Register(player))
+
+	conn := newMemoryConnection()
+	_, err := sm.Connect(player.GetUUID(), conn)
+	assert.Nil(t, err)
+
+	token := player.resumeToken
+	assert.NotEmpty(t, token)
+
+	_, err = sm.Resume("unknown", newMemoryConnection())
+	assert.NotNil(t, err)
+
+	// Output written while detached is kept for replay, except prompts
+	assert.True(t, sm.Detach(player.GetUUID(), conn))
+	assert.False(t, sm.Detach(player.GetUUID(), conn))
+	assert.Nil(t, player.WriteString("Bob says hello\n"))
+	assert.Nil(t, player.WritePrompt("> "))
+	assert.Equal(t, 1, sm.GetActiveSessionCount())
+
+	resumed := newMemoryConnection()
+	ps, err := sm.Resume(token, resumed)
+	assert.Nil(t, err)
+	assert.Equal(t, player, ps)
+	assert.Equal(t, []string{"Bob says hello\n"}, resumed.messages)
+
+	session, ok := sm.GetSession(player.GetUUID())
+	assert.True(t, ok)
+	assert.Equal(t, resumed, session.GetConnection())
+
+	// Tokens are single use
+	assert.NotEqual(t, token, player.resumeToken)
+	_, err = sm.Resume(token, newMemoryConnection())
+	assert.NotNil(t, err)
+
+	// Resuming while still connected takes over the connection
+	other := newMemoryConnection()
+	_, err = sm.Resume(player.resumeToken, other)
+	assert.Nil(t, err)
+	assert.True(t, resumed.closed)
+	assert.False(t, sm.Detach(player.GetUUID(), resumed))
+}
+
+func TestSessionManagerResumeExpires(t *testing.T) {
+	sm := NewSessionManager(2)
+	sm.SetResumeGracePeriod(10 * time.Millisecond)
+
+	player := NewPlayer("alice", "Alice")
+	assert.Nil(t, sm.Register(player))
+
+	conn := newMemoryConnection()
+	_, err := sm.Connect(player.GetUUID(), conn)
+	assert.Nil(t, err)
+
+	token := player.resumeToken
+	assert.True(t, sm.Detach(player.GetUUID(), conn))
+
+	assert.Eventually(t, func() bool {
+		return sm.GetActiveSessionCount() == 0
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = sm.Resume(token, newMemoryConnection())
+	assert.NotNil(t, err)
+}
+
+func TestSessionManagerLeave(t *testing.T) {
+	sm := NewSessionManager(2)
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+
+	aliceConn := newMemoryConnection()
+	bobConn := newMemoryConnection()
+
+	for player, conn := range map[*Player]Connection{alice: aliceConn, bob: bobConn} {
+		assert.Nil(t, sm.Register(player))
+		_, err := sm.Connect(player.GetUUID(), conn)
+		assert.Nil(t, err)
+	}
+
+	token := alice.resumeToken
+	sm.Leave(alice.GetUUID())
+
+	assert.Equal(t, 1, sm.GetActiveSessionCount())
+	assert.Equal(t, []string{"Alice has left the game.\n"}, bobConn.messages)
+
+	_, err := sm.Resume(token, newMemoryConnection())
+	assert.NotNil(t, err)
+}
+
+// TestSessionManagerResumeWhileBroadcasting checks, under -race, that output
+// sent to a player is safe while they detach and resume.
+func TestSessionManagerResumeWhileBroadcasting(t *testing.T) {
+	for _, size := range []int{0, 16} {
+		sm := NewSessionManager(2)
+		sm.SetOutputQueue(size, OverflowDrop)
+
+		alice := NewPlayer("alice", "Alice")
+		bob := NewPlayer("bob", "Bob")
+
+		for i, ps := range []*Player{alice, bob} {
+			assert.Nil(t, sm.Register(ps))
+
+			_, err := sm.Connect(ps.GetUUID(), &discardConnection{id: i + 1})
+			assert.Nil(t, err)
+		}
+
+		conn := alice.GetConnection()
+
+		started, done := make(chan struct{}), make(chan struct{})
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			close(started)
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				sm.SendToRoom(1, bob.GetUUID(), "Bob says hello\n")
+				sm.SendOutOfBandToRoom(1, OOBRoomInfo, RoomInfoData{ID: 1})
+				sm.OutputQueueStats()
+			}
+		}()
+
+		<-started
+
+		for i := 0; i < 200; i++ {
+			assert.True(t, sm.Detach(alice.GetUUID(), conn))
+
+			conn = &discardConnection{id: 3 + i}
+			_, err := sm.Resume(alice.getResumeToken(), conn)
+			assert.Nil(t, err)
+		}
+
+		close(done)
+		wg.Wait()
+
+		session, ok := sm.GetSession(alice.GetUUID())
+		assert.True(t, ok)
+		assert.Equal(t, conn, session.GetConnection())
+	}
+}
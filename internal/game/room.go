@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Room represents a room in the game world
@@ -21,6 +22,11 @@ type Room struct {
 	itemMap map[string]*Item
 	npcMap  map[string]Npc
 	mutex   *sync.RWMutex
+
+	mailbox chan roomTask // Tasks waiting to run on the room's goroutine
+	serial  *sync.Mutex   // Held while a task runs, so tasks never overlap
+	running *atomic.Bool
+	stopped chan struct{}
 }
 
 // NewRoom creates a new Room instance
@@ -37,6 +43,10 @@ func NewRoom(id int, name string, desc string) *Room {
 		itemMap:     make(map[string]*Item),
 		npcMap:      make(map[string]Npc),
 		mutex:       &sync.RWMutex{},
+		mailbox:     make(chan roomTask),
+		serial:      &sync.Mutex{},
+		running:     &atomic.Bool{},
+		stopped:     make(chan struct{}),
 	}
 
 	return room
@@ -76,6 +86,15 @@ func (room *Room) Copy(src *Room) {
 			}
 
 			room.Npcs = append(room.Npcs, merchant)
+		case NpcBanker:
+			banker := NewBanker(fmt.Sprintf("%d-%d", room.ID, index))
+
+			if err := banker.Convert(m); err != nil {
+				slog.Warn("Failed to convert banker NPC", "roomId", room.ID, "error", err)
+				continue
+			}
+
+			room.Npcs = append(room.Npcs, banker)
 		default:
 			slog.Warn("Unknown NPC type found in room", "roomId", room.ID)
 		}
@@ -128,7 +147,7 @@ func (room Room) GetBasicInfo() string {
 }
 
 // GetDetails returns detailed information about the room, including exits, items, etc.
-func (room Room) GetDetails(ps *Player, sm *SessionManager) string {
+func (room *Room) GetDetails(ps *Player, sm *SessionManager) string {
 	builder := strings.Builder{}
 
 	doorStr, count := room.GetDoors()
@@ -143,7 +162,7 @@ func (room Room) GetDetails(ps *Player, sm *SessionManager) string {
 		builder.WriteString(doorStr)
 	}
 
-	items := room.Items
+	items := room.GetItems()
 	if len(items) > 0 {
 		builder.WriteString("You see the following items in the room:\n")
 		for _, item := range items {
@@ -159,7 +178,7 @@ func (room Room) GetDetails(ps *Player, sm *SessionManager) string {
 		psb := strings.Builder{}
 
 		for _, player := range players {
-			if player.CurrentRoomId == room.ID {
+			if player.RoomId() == room.ID {
 				playerCount++
 				psb.WriteString(fmt.Sprintf("- %s\n", player.DisplayName))
 			}
@@ -209,6 +228,29 @@ func (room Room) GetDoors() (string, int) {
 	return builder.String(), count
 }
 
+// UnlockDoor unlocks the door with the name, reporting false when the room has
+// no such door.
+func (room *Room) UnlockDoor(name string) bool {
+	room.mutex.Lock()
+	defer room.mutex.Unlock()
+
+	for index := range room.Doors {
+		door := &room.Doors[index]
+		if door.Name != name {
+			continue
+		}
+
+		door.IsLocked = false
+		if mapped, ok := room.doorMap[door.MoveCommand]; ok {
+			mapped.IsLocked = false
+		}
+
+		return true
+	}
+
+	return false
+}
+
 // GetNpcByName retrieves an NPC by its name
 func (Room Room) GetNpcByName(name string) (Npc, bool) {
 	npc, exists := Room.npcMap[name]
@@ -227,7 +269,7 @@ func (room *Room) RemoveItem(itemName string) (Item, bool) {
 
 	copy := *item
 
-	delete(room.itemMap, itemName)
+	delete(room.itemMap, strings.ToLower(itemName))
 
 	newItems := []Item{}
 	for _, item := range room.Items {
@@ -255,3 +297,14 @@ func (room *Room) AddItem(item Item) bool {
 
 	return true
 }
+
+// GetItems returns a copy of the items lying in the room.
+func (room *Room) GetItems() []Item {
+	room.mutex.RLock()
+	defer room.mutex.RUnlock()
+
+	items := make([]Item, len(room.Items))
+	copy(items, room.Items)
+
+	return items
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"context"
+	"sync"
+)
+
+// roomTask is a piece of work sent to a room's mailbox.
+type roomTask struct {
+	fn   func(room *Room)
+	done chan struct{}
+}
+
+// Start processes the room's mailbox on its own goroutine until the context is
+// cancelled. Everything that changes the room, such as the commands of the
+// players in it, runs on this goroutine one task at a time.
+func (room *Room) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	room.running.Store(true)
+
+	defer func() {
+		room.running.Store(false)
+		close(room.stopped)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-room.mailbox:
+			room.run(task.fn)
+			close(task.done)
+		}
+	}
+}
+
+// Do runs fn on the room's goroutine and waits for it to finish. When the room
+// isn't running, fn runs on the caller's goroutine, still one task at a time.
+//
+// fn must not call Do itself, a room waiting on another room can deadlock.
+// Work for another room is handed off once fn returns.
+func (room *Room) Do(fn func(room *Room)) {
+	if room.running.Load() {
+		task := roomTask{fn: fn, done: make(chan struct{})}
+
+		select {
+		case room.mailbox <- task:
+			<-task.done
+			return
+		case <-room.stopped:
+		}
+	}
+
+	room.run(fn)
+}
+
+// run runs a task, making sure no other task for the room runs at the same time.
+func (room *Room) run(fn func(room *Room)) {
+	room.serial.Lock()
+	defer room.serial.Unlock()
+
+	fn(room)
+}
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"context"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,3 +19,34 @@ func TestRoomBasicInfo(t *testing.T) {
 
 	assert.Equal(t, expectedInfo, info)
 }
+
+func TestRoomDo(t *testing.T) {
+	room := NewRoom(1, "Test Room", "A room for testing.")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	roomGroup := sync.WaitGroup{}
+	roomGroup.Add(1)
+	go room.Start(ctx, &roomGroup)
+
+	// Tasks are serialized by the room, so count needs no lock
+	count := 0
+
+	wg := sync.WaitGroup{}
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			room.Do(func(room *Room) { count++ })
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 50, count)
+
+	cancel()
+	roomGroup.Wait()
+
+	// Once stopped, tasks run on the caller's goroutine
+	room.Do(func(room *Room) { count++ })
+	assert.Equal(t, 51, count)
+}
@@ -5,9 +5,7 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
-	"unsafe"
-
-	"github.com/quic-go/webtransport-go"
+	"time"
 )
 
 type SessionManagerErrorType string
@@ -36,105 +34,260 @@ func (e SessionManagerError) Unwrap() error {
 	return e.Wrapped
 }
 
-// SessionManager manages player sessions in the game.
+// SessionManager manages player sessions in the game. Active players are indexed
+// by UUID, username and room so lookups and room broadcasts don't scan every
+// session.
 type SessionManager struct {
-	Active  []*Player
 	Pending map[string]*Player
 
-	maxSessions int
-	mutex       *sync.RWMutex
-	sessionMap  map[uintptr]string // Session pointer -> player UUID
+	maxSessions   int
+	reservedSlots int            // Slots only privileged players can take
+	queue         []*queuedLogin // Players waiting for a free slot
+	queueSize     int
+	queueChanged  chan struct{} // Closed whenever the queue changes
+	mutex         *sync.RWMutex
+	active        map[string]*Player          // Player UUID -> active player, including players waiting to resume
+	usernames     map[string]string           // Lowercase username -> active player UUID
+	rooms         map[int]map[string]*Player  // Room id -> player UUID -> active player
+	sessionMap    map[Connection]string       // Connection -> player UUID
+	resumeTokens  map[string]string           // Resume token -> player UUID
+	detached      map[string]*detachedSession // Player UUID -> session waiting to be resumed
+	gracePeriod   time.Duration
+	pendingSince  map[string]time.Time // Player UUID -> when the login was registered
+	idleWarned    map[string]time.Time // Player UUID -> last activity an idle warning was sent for
+	pendingTTL    time.Duration
+	idleTimeout   time.Duration
+	idleWarning   time.Duration
+	counters      *reaperCounters
+
+	outputQueueSize int
+	overflowPolicy  OverflowPolicy
+	outboxCounters  *outboxCounters
+
+	events      *EventBus       // Told when players leave the game
+	removeHooks []func(*Player) // Called after a player is removed
+	detachHooks []func(*Player) // Called after a player's connection drops
 }
 
 // NewSessionManager creates a new SessionManager with a specified maximum number of sessions.
 func NewSessionManager(maxSessions int) *SessionManager {
 	return &SessionManager{
-		Active:      make([]*Player, maxSessions),
-		Pending:     make(map[string]*Player),
-		maxSessions: maxSessions,
-		mutex:       &sync.RWMutex{},
-		sessionMap:  make(map[uintptr]string),
+		Pending:      make(map[string]*Player),
+		maxSessions:  maxSessions,
+		queueSize:    DefaultLoginQueueSize,
+		queueChanged: make(chan struct{}),
+		mutex:        &sync.RWMutex{},
+		active:       make(map[string]*Player, maxSessions),
+		usernames:    make(map[string]string, maxSessions),
+		rooms:        make(map[int]map[string]*Player),
+		sessionMap:   make(map[Connection]string, maxSessions),
+		resumeTokens: make(map[string]string),
+		detached:     make(map[string]*detachedSession),
+		gracePeriod:  DefaultResumeGracePeriod,
+		pendingSince: make(map[string]time.Time),
+		idleWarned:   make(map[string]time.Time),
+		pendingTTL:   DefaultPendingTTL,
+		idleTimeout:  DefaultIdleTimeout,
+		idleWarning:  DefaultIdleWarning,
+		counters:     &reaperCounters{},
+
+		overflowPolicy: OverflowDrop,
+		outboxCounters: &outboxCounters{},
 	}
 }
 
-// Register adds a new player to the pending list.
+// SetEvents sets the event bus PlayerLeft is published on when a player leaves
+// the game. It must be set before players connect.
+func (sm *SessionManager) SetEvents(bus *EventBus) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.events = bus
+}
+
+// Register adds a new player to the pending list, unless every slot is taken.
+// Use Join to wait for a slot instead.
 func (sm *SessionManager) Register(player *Player) error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	count := len(sm.sessionMap)
-	if count >= sm.maxSessions {
+	if !sm.hasFreeSlot(player) {
 		return &SessionManagerError{Type: ErrorMaxPlayers, Message: "Max player limit reached, please try again", Wrapped: nil}
 	}
 
-	sm.Pending[player.GetUUID()] = player
+	sm.addPending(player)
 	return nil
 }
 
-// Connect adds a new PlayerSession to the manager.
-func (sm *SessionManager) Connect(uuid string, session *webtransport.Session, stream *webtransport.Stream) (*Player, error) {
+// Connect attaches a connection to a pending player and moves it to the active list.
+func (sm *SessionManager) Connect(uuid string, conn Connection) (*Player, error) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	player, exists := sm.Pending[uuid]
+	ps, exists := sm.Pending[uuid]
 	if !exists {
 		return nil, fmt.Errorf("no pending session found")
 	}
 
-	for i := 0; i < sm.maxSessions; i++ {
-		if sm.Active[i] == nil {
-			ps := player
-			ps.SetSession(session)
-			ps.SetStream(stream)
-			sm.Active[i] = ps
-			sessionPtr := uintptr(unsafe.Pointer(ps.GetSession()))
+	if len(sm.active) >= sm.maxSessions {
+		return nil, fmt.Errorf("unable to create player session")
+	}
 
-			sm.sessionMap[sessionPtr] = ps.GetUUID()
+	sm.attach(ps, conn)
+	ps.Touch()
 
-			delete(sm.Pending, uuid)
+	sm.active[uuid] = ps
+	sm.usernames[strings.ToLower(ps.Username)] = uuid
+	sm.addToRoom(ps)
+	sm.sessionMap[conn] = uuid
+	sm.issueResumeToken(ps)
 
-			return ps, nil
-		}
-	}
+	delete(sm.Pending, uuid)
+	delete(sm.pendingSince, uuid)
 
-	return nil, fmt.Errorf("unable to create player session")
+	return ps, nil
 }
 
-// RemovePlayer removes a PlayerSession from the manager.
-func (sm *SessionManager) RemovePlayerBySession(session *webtransport.Session) bool {
+// OnRemove registers a function called with each player removed from the
+// manager, after the lock is released. It must be set before players connect.
+func (sm *SessionManager) OnRemove(fn func(*Player)) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	sessionPtr := uintptr(unsafe.Pointer(session))
-	uuid, exists := sm.sessionMap[sessionPtr]
+	sm.removeHooks = append(sm.removeHooks, fn)
+}
+
+// OnDetach registers a function called with each player whose connection
+// dropped, after the lock is released, while they still have the grace period
+// to resume. It must be set before players connect.
+func (sm *SessionManager) OnDetach(fn func(*Player)) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.detachHooks = append(sm.detachHooks, fn)
+}
+
+// RemovePlayerByConnection removes the PlayerSession using the given connection.
+func (sm *SessionManager) RemovePlayerByConnection(conn Connection) bool {
+	sm.mutex.Lock()
+
+	uuid, exists := sm.sessionMap[conn]
 	if !exists {
+		sm.mutex.Unlock()
 		return false
 	}
 
-	for i := 0; i < sm.maxSessions; i++ {
-		if sm.Active[i] != nil && sm.Active[i].session != nil && sm.Active[i].GetUUID() == uuid {
-			sm.Active[i] = nil
-			delete(sm.sessionMap, sessionPtr)
-			return true
-		}
+	ps := sm.active[uuid]
+	removed := sm.remove(uuid)
+	sm.mutex.Unlock()
+
+	if removed {
+		sm.removed(ps)
 	}
 
-	return false
+	return removed
 }
 
 // RemovePlayer removes a PlayerSession from the manager.
 func (sm *SessionManager) RemovePlayer(uuid string) bool {
+	sm.mutex.Lock()
+	ps := sm.active[uuid]
+	removed := sm.remove(uuid)
+	sm.mutex.Unlock()
+
+	if removed {
+		sm.removed(ps)
+	}
+
+	return removed
+}
+
+// removed calls the remove hooks for a player who was just removed.
+func (sm *SessionManager) removed(ps *Player) {
+	sm.mutex.RLock()
+	hooks := sm.removeHooks
+	sm.mutex.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ps)
+	}
+}
+
+// remove removes an active player and everything tracked for them. The caller
+// must hold the lock.
+func (sm *SessionManager) remove(uuid string) bool {
+	ps, exists := sm.active[uuid]
+	if !exists {
+		return false
+	}
+
+	conn, ob := ps.connection()
+	if conn != nil {
+		delete(sm.sessionMap, conn)
+	}
+
+	if session, exists := sm.detached[uuid]; exists {
+		session.timer.Stop()
+		session.conn.Close("player removed")
+		delete(sm.detached, uuid)
+	}
+
+	delete(sm.resumeTokens, ps.getResumeToken())
+	delete(sm.idleWarned, uuid)
+	if ob != nil {
+		ob.close()
+	}
+
+	delete(sm.active, uuid)
+	if sm.usernames[strings.ToLower(ps.Username)] == uuid {
+		delete(sm.usernames, strings.ToLower(ps.Username))
+	}
+
+	sm.removeFromRoom(ps)
+	sm.admitQueued()
+
+	return true
+}
+
+// MovePlayer moves the player to another room, keeping the room index in sync.
+func (sm *SessionManager) MovePlayer(ps *Player, roomId int) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	for i := 0; i < sm.maxSessions; i++ {
-		if sm.Active[i] != nil && sm.Active[i].GetUUID() == uuid {
-			sm.Active[i] = nil
-			return true
-		}
+	if _, exists := sm.active[ps.GetUUID()]; !exists {
+		ps.SetRoomId(roomId)
+		return
 	}
 
-	return false
+	sm.removeFromRoom(ps)
+	ps.SetRoomId(roomId)
+	sm.addToRoom(ps)
+}
+
+// addToRoom indexes the player by their current room. The caller must hold the lock.
+func (sm *SessionManager) addToRoom(ps *Player) {
+	roomId := ps.RoomId()
+
+	room, exists := sm.rooms[roomId]
+	if !exists {
+		room = make(map[string]*Player)
+		sm.rooms[roomId] = room
+	}
+
+	room[ps.GetUUID()] = ps
+}
+
+// removeFromRoom drops the player from their current room's index. The caller
+// must hold the lock.
+func (sm *SessionManager) removeFromRoom(ps *Player) {
+	roomId := ps.RoomId()
+
+	room := sm.rooms[roomId]
+	delete(room, ps.GetUUID())
+
+	if len(room) == 0 {
+		delete(sm.rooms, roomId)
+	}
 }
 
 // RemovePending removes a player from the pending list.
@@ -144,6 +297,8 @@ func (sm *SessionManager) RemovePending(uuid string) bool {
 
 	if _, exists := sm.Pending[uuid]; exists {
 		delete(sm.Pending, uuid)
+		delete(sm.pendingSince, uuid)
+		sm.admitQueued()
 		return true
 	}
 
@@ -155,13 +310,26 @@ func (sm *SessionManager) GetSession(uuid string) (*Player, bool) {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	for i := 0; i < sm.maxSessions; i++ {
-		if sm.Active[i] != nil && sm.Active[i].session != nil && sm.Active[i].GetUUID() == uuid {
-			return sm.Active[i], true
-		}
+	ps, exists := sm.active[uuid]
+	if !exists || ps.GetConnection() == nil {
+		return nil, false
 	}
 
-	return nil, false
+	return ps, true
+}
+
+// getPlayer returns an active player, including players waiting to resume.
+func (sm *SessionManager) getPlayer(uuid string) (*Player, bool) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	ps := sm.findActive(uuid)
+	return ps, ps != nil
+}
+
+// findActive returns an active player by UUID. The caller must hold the lock.
+func (sm *SessionManager) findActive(uuid string) *Player {
+	return sm.active[uuid]
 }
 
 // GetActiveSessionCount returns the number of active player sessions.
@@ -177,51 +345,68 @@ func (sm *SessionManager) GetActivePlayers() []*Player {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	active := []*Player{}
-	for _, ps := range sm.Active {
-		if ps != nil {
-			active = append(active, ps)
-		}
+	active := make([]*Player, 0, len(sm.active))
+	for _, ps := range sm.active {
+		active = append(active, ps)
 	}
 
 	return active
 }
 
 // GetPlayersInRoom returns a slice of players currently in the specified room.
-func (sm *SessionManager) GetPlayersInRoom(roomId int, skipPlayerUUID string) []Player {
+func (sm *SessionManager) GetPlayersInRoom(roomId int, skipPlayerUUID string) []*Player {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	players := []Player{}
+	room := sm.rooms[roomId]
+	players := make([]*Player, 0, len(room))
 
-	for _, ps := range sm.Active {
-		if ps != nil && ps.CurrentRoomId == roomId && ps.GetUUID() != skipPlayerUUID {
-			players = append(players, *ps)
+	for uuid, ps := range room {
+		if uuid != skipPlayerUUID {
+			players = append(players, ps)
 		}
 	}
 
 	return players
 }
 
+// FindPlayer returns an active player by username, ignoring case.
+func (sm *SessionManager) FindPlayer(username string) (*Player, bool) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	ps, exists := sm.active[sm.usernames[strings.ToLower(username)]]
+	return ps, exists
+}
+
 // SendToPlayer sends a message to a specific player by UUID.
-func (sm SessionManager) SendToPlayer(playerUuid string, message string) {
-	active := sm.GetActivePlayers()
+func (sm *SessionManager) SendToPlayer(playerUuid string, message string) {
+	player, ok := sm.getPlayer(playerUuid)
+	if !ok {
+		return
+	}
+
 	trimmed := strings.TrimRight(message, "\n") + "\n"
 
-	var player *Player
+	if err := player.WriteString(trimmed); err != nil {
+		slog.Error("failed to send to player", "player", player.DisplayName, "error", err)
+	}
+}
 
-	// Eventually we'll want to track players in a map for fast lookup..
-	for _, ps := range active {
-		if ps.GetUUID() == playerUuid {
-			player = ps
-			break
+// SendToRoom sends a message to every player in a room except skipPlayerUUID.
+func (sm *SessionManager) SendToRoom(roomId int, skipPlayerUUID string, message string) {
+	for _, ps := range sm.GetPlayersInRoom(roomId, skipPlayerUUID) {
+		if err := ps.WriteString(message); err != nil {
+			slog.Error("failed to send to player", "player", ps.DisplayName, "error", err)
 		}
 	}
+}
 
-	if player != nil {
-		err := player.WriteString(trimmed)
-		if err != nil {
-			slog.Error("failed to send to player %s: %w", player.DisplayName, err)
+// SendOutOfBandToRoom sends an out-of-band package to every subscribed player in a room.
+func (sm *SessionManager) SendOutOfBandToRoom(roomId int, pkg string, data any) {
+	for _, ps := range sm.GetPlayersInRoom(roomId, "") {
+		if err := ps.SendOutOfBand(pkg, data); err != nil {
+			slog.Error("failed to send out-of-band data", "player", ps.DisplayName, "package", pkg, "error", err)
 		}
 	}
 }
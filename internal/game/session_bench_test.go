@@ -0,0 +1,103 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// benchmarkRooms is how many rooms the benchmark players are spread across.
+const benchmarkRooms = 20
+
+// discardConnection is a Connection which drops everything written to it.
+type discardConnection struct {
+	id int
+}
+
+func (c *discardConnection) WriteMessage(typ MessageType, message []byte) error { return nil }
+func (c *discardConnection) Close(reason string) error                          { return nil }
+func (c *discardConnection) RemoteAddr() string                                 { return "discard" }
+func (c *discardConnection) Context() context.Context                           { return context.Background() }
+
+// newBenchmarkSessions connects count players spread evenly across benchmarkRooms.
+func newBenchmarkSessions(b *testing.B, count int) (*SessionManager, []*Player) {
+	sm := NewSessionManager(count)
+	players := make([]*Player, count)
+
+	for i := range players {
+		ps := NewPlayer(fmt.Sprintf("player%d", i), fmt.Sprintf("Player%d", i))
+		ps.SetRoomId(i%benchmarkRooms + 1)
+
+		if err := sm.Register(ps); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := sm.Connect(ps.GetUUID(), &discardConnection{id: i}); err != nil {
+			b.Fatal(err)
+		}
+
+		players[i] = ps
+	}
+
+	if sm.GetActiveSessionCount() != count {
+		b.Fatalf("expected %d sessions, got %d", count, sm.GetActiveSessionCount())
+	}
+
+	return sm, players
+}
+
+var benchmarkSizes = []int{1000, 10000}
+
+func BenchmarkGetSession(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			sm, players := newBenchmarkSessions(b, size)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, ok := sm.GetSession(players[i%size].GetUUID()); !ok {
+					b.Fatal("missing session")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetPlayersInRoom(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			sm, _ := newBenchmarkSessions(b, size)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				sm.GetPlayersInRoom(i%benchmarkRooms+1, "")
+			}
+		})
+	}
+}
+
+func BenchmarkSendToRoom(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			sm, _ := newBenchmarkSessions(b, size)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				sm.SendToRoom(i%benchmarkRooms+1, "", "Bob says hello\n")
+			}
+		})
+	}
+}
+
+func BenchmarkMovePlayer(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			sm, players := newBenchmarkSessions(b, size)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				sm.MovePlayer(players[i%size], i%benchmarkRooms+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryConnection is an in-memory Connection used for testing.
+type memoryConnection struct {
+	messages []string
+	closed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newMemoryConnection() *memoryConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &memoryConnection{ctx: ctx, cancel: cancel}
+}
+
+func (c *memoryConnection) WriteMessage(typ MessageType, message []byte) error {
+	c.messages = append(c.messages, string(message))
+	return nil
+}
+
+func (c *memoryConnection) Close(reason string) error {
+	c.closed = true
+	c.cancel()
+	return nil
+}
+
+func (c *memoryConnection) RemoteAddr() string {
+	return "memory"
+}
+
+func (c *memoryConnection) Context() context.Context {
+	return c.ctx
+}
+
+func TestSessionManagerConnect(t *testing.T) {
+	sm := NewSessionManager(2)
+	player := NewPlayer("alice", "Alice")
+
+	assert.Nil(t, sm.Register(player))
+
+	_, err := sm.Connect("unknown", newMemoryConnection())
+	assert.NotNil(t, err)
+
+	conn := newMemoryConnection()
+	connected, err := sm.Connect(player.GetUUID(), conn)
+	assert.Nil(t, err)
+	assert.Equal(t, player, connected)
+	assert.Equal(t, 1, sm.GetActiveSessionCount())
+
+	session, ok := sm.GetSession(player.GetUUID())
+	assert.True(t, ok)
+	assert.Equal(t, conn, session.GetConnection())
+
+	sm.SendToPlayer(player.GetUUID(), "hello")
+	assert.Equal(t, []string{"hello\n"}, conn.messages)
+
+	assert.True(t, sm.RemovePlayerByConnection(conn))
+	assert.False(t, sm.RemovePlayerByConnection(conn))
+	assert.Equal(t, 0, sm.GetActiveSessionCount())
+}
+
+func TestSessionManagerMaxPlayers(t *testing.T) {
+	sm := NewSessionManager(1)
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+
+	assert.Nil(t, sm.Register(alice))
+	_, err := sm.Connect(alice.GetUUID(), newMemoryConnection())
+	assert.Nil(t, err)
+
+	err = sm.Register(bob)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), string(ErrorMaxPlayers)))
+
+	assert.True(t, sm.RemovePlayer(alice.GetUUID()))
+	assert.Equal(t, 0, sm.GetActiveSessionCount())
+	assert.Nil(t, sm.Register(bob))
+}
+
+func TestSessionManagerRooms(t *testing.T) {
+	sm := NewSessionManager(3)
+	alice := NewPlayer("alice", "Alice")
+	bob := NewPlayer("bob", "Bob")
+	carol := NewPlayer("carol", "Carol")
+	carol.SetRoomId(2)
+
+	for _, player := range []*Player{alice, bob, carol} {
+		assert.Nil(t, sm.Register(player))
+		_, err := sm.Connect(player.GetUUID(), newMemoryConnection())
+		assert.Nil(t, err)
+	}
+
+	assert.ElementsMatch(t, []*Player{alice, bob}, sm.GetPlayersInRoom(1, ""))
+	assert.Equal(t, []*Player{bob}, sm.GetPlayersInRoom(1, alice.GetUUID()))
+	assert.Equal(t, []*Player{carol}, sm.GetPlayersInRoom(2, ""))
+
+	sm.MovePlayer(bob, 2)
+	assert.Equal(t, 2, bob.RoomId())
+	assert.Equal(t, []*Player{alice}, sm.GetPlayersInRoom(1, ""))
+	assert.ElementsMatch(t, []*Player{bob, carol}, sm.GetPlayersInRoom(2, ""))
+
+	assert.True(t, sm.RemovePlayer(carol.GetUUID()))
+	assert.Equal(t, []*Player{bob}, sm.GetPlayersInRoom(2, ""))
+
+	sm.SendToRoom(2, "", "hello")
+	assert.Equal(t, []string{"hello"}, bob.GetConnection().(*memoryConnection).messages)
+
+	// Players who aren't connected yet aren't indexed
+	dave := NewPlayer("dave", "Dave")
+	sm.MovePlayer(dave, 3)
+	assert.Equal(t, 3, dave.RoomId())
+	assert.Empty(t, sm.GetPlayersInRoom(3, ""))
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Social is a canned emote such as smile or hug. Messages can use {actor} and
+// {target} for the names of the player and who they're acting on. A social has
+// messages for use without a target, with one, or both.
+type Social struct {
+	Name         string `yaml:"name"`
+	Actor        string `yaml:"actor"`        // Seen by the player without a target
+	Others       string `yaml:"others"`       // Seen by the room without a target
+	TargetActor  string `yaml:"targetActor"`  // Seen by the player
+	Target       string `yaml:"target"`       // Seen by the target
+	TargetOthers string `yaml:"targetOthers"` // Seen by the rest of the room
+}
+
+// Validate checks that the social has a single word name and a complete set
+// of messages for each way it can be used.
+func (s Social) Validate() bool {
+	if s.Name == "" || strings.ContainsAny(s.Name, " \t\n") {
+		return false
+	}
+
+	partial := (s.Actor != "" || s.Others != "") && !s.Untargeted()
+	partialTarget := (s.TargetActor != "" || s.Target != "" || s.TargetOthers != "") && !s.Targeted()
+
+	return (s.Untargeted() || s.Targeted()) && !partial && !partialTarget
+}
+
+// Untargeted checks if the social can be used without a target.
+func (s Social) Untargeted() bool {
+	return s.Actor != "" && s.Others != ""
+}
+
+// Targeted checks if the social can be used on a target.
+func (s Social) Targeted() bool {
+	return s.TargetActor != "" && s.Target != "" && s.TargetOthers != ""
+}
+
+// Render fills in the names of the actor and target.
+func (s Social) Render(message string, actor string, target string) string {
+	return strings.NewReplacer("{actor}", actor, "{target}", target).Replace(message)
+}
+
+// LoadSocialsFromYaml loads socials from a YAML file.
+func LoadSocialsFromYaml(file string) ([]Social, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file %s: %w", file, err)
+	}
+
+	socials := []Social{}
+
+	err = yaml.Unmarshal(data, &socials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse socials from file %s: %w", file, err)
+	}
+
+	for i, social := range socials {
+		if !social.Validate() {
+			return nil, fmt.Errorf("invalid social %q found in file %s", social.Name, file)
+		}
+
+		socials[i].Name = strings.ToLower(social.Name)
+	}
+
+	return socials, nil
+}
@@ -0,0 +1,39 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocialValidate(t *testing.T) {
+	type SocialTest struct {
+		social   Social
+		expected bool
+	}
+
+	tests := []SocialTest{
+		{social: Social{Name: "shrug", Actor: "You shrug.", Others: "{actor} shrugs."}, expected: true},
+		{social: Social{Name: "hug", TargetActor: "You hug {target}.", Target: "{actor} hugs you.", TargetOthers: "{actor} hugs {target}."}, expected: true},
+		{social: Social{Name: "high five", Actor: "You high five.", Others: "{actor} high fives."}, expected: false},
+		{social: Social{Name: "shrug", Actor: "You shrug."}, expected: false},
+		{social: Social{Name: "shrug", Actor: "You shrug.", Others: "{actor} shrugs.", TargetActor: "You shrug at {target}."}, expected: false},
+		{social: Social{Name: "nothing"}, expected: false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.social.Validate(), test.social.Name)
+	}
+
+	hug := tests[1].social
+	assert.Equal(t, "Alice hugs Bob.", hug.Render(hug.TargetOthers, "Alice", "Bob"))
+}
+
+func TestLoadSocialsFromYaml(t *testing.T) {
+	socials, err := LoadSocialsFromYaml("../../data/socials.yml")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, socials)
+
+	_, err = LoadSocialsFromYaml("missing.yml")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,311 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+var (
+	ErrorTrading        = errors.New("already trading")
+	ErrorNotTrading     = errors.New("not trading")
+	ErrorAlreadyOffered = errors.New("item already offered")
+	ErrorNotOffered     = errors.New("not offered")
+	ErrorTradeFailed    = errors.New("trade failed")
+)
+
+// TradeOffer is what one side of a trade puts up. Items carry their ids in the
+// owner's inventory.
+type TradeOffer struct {
+	Gold  int
+	Items []Item
+}
+
+// TradedItem is an item which changed hands in a trade. Item carries its id in
+// the receiver's inventory, FromID its id in the giver's.
+type TradedItem struct {
+	Item   Item   `json:"item"`
+	FromID string `json:"fromId"`
+}
+
+// trade is a negotiation between two players. Each side's offer and
+// confirmation is stored at the same index as the player.
+type trade struct {
+	players   [2]*Player
+	offers    [2]TradeOffer
+	confirmed [2]bool
+}
+
+// side returns the player's index in the trade.
+func (t *trade) side(ps *Player) int {
+	if t.players[0] == ps {
+		return 0
+	}
+
+	return 1
+}
+
+// Trades keeps track of trades between players. Both players must confirm the
+// offers before anything changes hands, and any change to an offer takes back
+// both confirmations.
+type Trades struct {
+	trades   map[string]*trade  // Player uuid -> their trade
+	requests map[string]*Player // Player uuid -> who they asked to trade with
+	mutex    *sync.Mutex
+}
+
+// NewTrades creates a new Trades instance.
+func NewTrades() *Trades {
+	return &Trades{
+		trades:   make(map[string]*trade),
+		requests: make(map[string]*Player),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Request asks the target to trade with the player. If the target already
+// asked the player, the trade starts and true is returned.
+func (t *Trades) Request(ps *Player, target *Player) (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.trades[ps.GetUUID()]; ok {
+		return false, ErrorTrading
+	}
+
+	if _, ok := t.trades[target.GetUUID()]; ok {
+		return false, ErrorTrading
+	}
+
+	if t.requests[target.GetUUID()] != ps {
+		t.requests[ps.GetUUID()] = target
+		return false, nil
+	}
+
+	delete(t.requests, ps.GetUUID())
+	delete(t.requests, target.GetUUID())
+
+	tr := &trade{players: [2]*Player{target, ps}}
+	t.trades[ps.GetUUID()] = tr
+	t.trades[target.GetUUID()] = tr
+
+	return true, nil
+}
+
+// Offer adds gold or an item to the player's side of their trade.
+func (t *Trades) Offer(ps *Player, gold int, item *Item) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return ErrorNotTrading
+	}
+
+	offer := &tr.offers[tr.side(ps)]
+
+	if item != nil {
+		if slices.ContainsFunc(offer.Items, func(offered Item) bool { return offered.ID == item.ID }) {
+			return ErrorAlreadyOffered
+		}
+
+		offer.Items = append(offer.Items, *item)
+	}
+
+	offer.Gold += gold
+	tr.confirmed = [2]bool{}
+
+	return nil
+}
+
+// Withdraw takes gold or an item, by id, back off the player's side of their
+// trade.
+func (t *Trades) Withdraw(ps *Player, gold int, itemId string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return ErrorNotTrading
+	}
+
+	offer := &tr.offers[tr.side(ps)]
+
+	if gold > offer.Gold {
+		return ErrorNotOffered
+	}
+
+	if itemId != "" {
+		i := slices.IndexFunc(offer.Items, func(offered Item) bool { return offered.ID == itemId })
+		if i < 0 {
+			return ErrorNotOffered
+		}
+
+		offer.Items = slices.Delete(offer.Items, i, i+1)
+	}
+
+	offer.Gold -= gold
+	tr.confirmed = [2]bool{}
+
+	return nil
+}
+
+// TradeResult is what changed hands in a completed trade. Gold and items
+// are stored at the same index as the player who gave them.
+type TradeResult struct {
+	Players [2]*Player
+	Gold    [2]int
+	Items   [2][]TradedItem
+}
+
+// Confirm accepts the offers in the player's trade. Once both players have
+// confirmed, the offers are exchanged in one step and the trade ends,
+// returning what changed hands. If either player no longer has what they
+// offered, nothing changes hands and the confirmations are taken back.
+func (t *Trades) Confirm(ps *Player) (*TradeResult, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return nil, ErrorNotTrading
+	}
+
+	tr.confirmed[tr.side(ps)] = true
+	if !tr.confirmed[0] || !tr.confirmed[1] {
+		return nil, nil
+	}
+
+	a, b := tr.players[0], tr.players[1]
+
+	given, err := Exchange(a.Inventory, tr.offers[0], b.Inventory, tr.offers[1])
+	if err != nil {
+		tr.confirmed = [2]bool{}
+		return nil, err
+	}
+
+	delete(t.trades, a.GetUUID())
+	delete(t.trades, b.GetUUID())
+
+	slog.Info("Players traded", "player", a.DisplayName, "with", b.DisplayName)
+
+	return &TradeResult{
+		Players: tr.players,
+		Gold:    [2]int{tr.offers[0].Gold, tr.offers[1].Gold},
+		Items:   given,
+	}, nil
+}
+
+// Check takes anything the player no longer has off their offer, such as an
+// item they sold after offering it. If the offer changed, both confirmations
+// are taken back and the other player is returned.
+func (t *Trades) Check(ps *Player) (*Player, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return nil, false
+	}
+
+	offer := &tr.offers[tr.side(ps)]
+	held := ps.Inventory.GetItems()
+
+	items := slices.DeleteFunc(slices.Clone(offer.Items), func(offered Item) bool {
+		return !slices.Contains(held, offered)
+	})
+
+	gold := min(offer.Gold, ps.Inventory.GetGold())
+
+	if len(items) == len(offer.Items) && gold == offer.Gold {
+		return nil, false
+	}
+
+	offer.Items, offer.Gold = items, gold
+	tr.confirmed = [2]bool{}
+
+	return tr.players[1-tr.side(ps)], true
+}
+
+// Cancel ends the player's trade, or takes back their request to trade. The
+// other player is returned if a trade was cancelled.
+func (t *Trades) Cancel(ps *Player) (*Player, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.requests, ps.GetUUID())
+
+	// Requests to trade with the player can't be accepted anymore either
+	for uuid, target := range t.requests {
+		if target == ps {
+			delete(t.requests, uuid)
+		}
+	}
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return nil, false
+	}
+
+	other := tr.players[1-tr.side(ps)]
+
+	delete(t.trades, ps.GetUUID())
+	delete(t.trades, other.GetUUID())
+
+	return other, true
+}
+
+// Status returns who the player is trading with, both sides' offers and
+// whether each side confirmed them. The player's side comes first.
+func (t *Trades) Status(ps *Player) (*Player, [2]TradeOffer, [2]bool, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tr, ok := t.trades[ps.GetUUID()]
+	if !ok {
+		return nil, [2]TradeOffer{}, [2]bool{}, false
+	}
+
+	me, them := tr.side(ps), 1-tr.side(ps)
+
+	offers := [2]TradeOffer{
+		{Gold: tr.offers[me].Gold, Items: slices.Clone(tr.offers[me].Items)},
+		{Gold: tr.offers[them].Gold, Items: slices.Clone(tr.offers[them].Items)},
+	}
+
+	return tr.players[them], offers, [2]bool{tr.confirmed[me], tr.confirmed[them]}, true
+}
+
+// CheckTrade updates the player's offer after their inventory changed, telling
+// both sides if anything offered is gone. It's called after every command, so
+// confirmations never stand for items or gold which left the inventory.
+func (g Game) CheckTrade(ps *Player) {
+	other, changed := g.Trades.Check(ps)
+	if !changed {
+		return
+	}
+
+	for _, player := range []*Player{ps, other} {
+		if err := player.WriteString(fmt.Sprintf("[trade] %s no longer has everything they offered, so the offer changed and any confirmations were taken back.\n", ps.DisplayName)); err != nil {
+			slog.Error("failed to send to player", "player", player.DisplayName, "error", err)
+		}
+	}
+}
+
+// CancelTrade ends the player's trade, if they're in one, telling both sides.
+// It's called when players move or leave the game, so trades only happen
+// between players who stay together.
+func (g Game) CancelTrade(ps *Player) {
+	other, ok := g.Trades.Cancel(ps)
+	if !ok {
+		return
+	}
+
+	for _, player := range []*Player{ps, other} {
+		if err := player.WriteString(fmt.Sprintf("[trade] The trade between %s and %s was cancelled.\n", ps.DisplayName, other.DisplayName)); err != nil {
+			slog.Error("failed to send to player", "player", player.DisplayName, "error", err)
+		}
+	}
+}
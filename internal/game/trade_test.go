@@ -0,0 +1,123 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrades(t *testing.T) {
+	trades := NewTrades()
+	alice, bob, carol := NewPlayer("alice", "Alice"), NewPlayer("bob", "Bob"), NewPlayer("carol", "Carol")
+
+	coin := alice.Inventory.Add(Item{Name: "Coin", Type: Trinket, SellingPrice: 1})
+	bob.Inventory.AddGold(5)
+
+	assert.ErrorIs(t, trades.Offer(alice, 1, nil), ErrorNotTrading)
+
+	started, err := trades.Request(alice, bob)
+	assert.Nil(t, err)
+	assert.False(t, started)
+
+	started, err = trades.Request(bob, alice)
+	assert.Nil(t, err)
+	assert.True(t, started)
+
+	_, err = trades.Request(carol, alice)
+	assert.ErrorIs(t, err, ErrorTrading)
+
+	assert.Nil(t, trades.Offer(alice, 0, &coin))
+	assert.ErrorIs(t, trades.Offer(alice, 0, &coin), ErrorAlreadyOffered)
+	assert.Nil(t, trades.Offer(bob, 5, nil))
+
+	result, err := trades.Confirm(alice)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	// Changing an offer takes back both confirmations
+	assert.Nil(t, trades.Withdraw(bob, 2, ""))
+	assert.ErrorIs(t, trades.Withdraw(bob, 4, ""), ErrorNotOffered)
+
+	other, offers, confirmed, ok := trades.Status(bob)
+	assert.True(t, ok)
+	assert.Equal(t, alice, other)
+	assert.Equal(t, [2]TradeOffer{{Gold: 3}, {Items: []Item{coin}}}, offers)
+	assert.Equal(t, [2]bool{false, false}, confirmed)
+
+	// Nothing changes hands if an offer can't be met
+	bob.Inventory.TakeGold(4)
+
+	_, err = trades.Confirm(alice)
+	assert.Nil(t, err)
+	_, err = trades.Confirm(bob)
+	assert.ErrorIs(t, err, ErrorTradeFailed)
+	assert.Len(t, alice.Inventory.GetItems(), 1)
+	assert.Equal(t, 1, bob.Inventory.GetGold())
+
+	assert.Nil(t, trades.Withdraw(bob, 2, ""))
+
+	_, err = trades.Confirm(bob)
+	assert.Nil(t, err)
+	result, err = trades.Confirm(alice)
+	assert.Nil(t, err)
+
+	assert.Equal(t, [2]*Player{alice, bob}, result.Players)
+	assert.Equal(t, [2]int{0, 1}, result.Gold)
+	assert.Equal(t, [2][]TradedItem{{{Item: coin, FromID: coin.ID}}, {}}, result.Items)
+	assert.Equal(t, 1, alice.Inventory.GetGold())
+	assert.Equal(t, []Item{coin}, bob.Inventory.GetItems())
+
+	// The trade is over
+	_, _, _, ok = trades.Status(alice)
+	assert.False(t, ok)
+
+	_, err = trades.Request(carol, alice)
+	assert.Nil(t, err)
+	started, err = trades.Request(alice, carol)
+	assert.Nil(t, err)
+	assert.True(t, started)
+
+	other, ok = trades.Cancel(alice)
+	assert.True(t, ok)
+	assert.Equal(t, carol, other)
+
+	_, ok = trades.Cancel(carol)
+	assert.False(t, ok)
+}
+
+func TestTradesCheck(t *testing.T) {
+	trades := NewTrades()
+	alice, bob := NewPlayer("alice", "Alice"), NewPlayer("bob", "Bob")
+
+	sword := alice.Inventory.Add(Item{Name: "Sword", Type: Trinket, SellingPrice: 9})
+	alice.Inventory.AddGold(5)
+
+	_, _ = trades.Request(alice, bob)
+	_, _ = trades.Request(bob, alice)
+
+	assert.Nil(t, trades.Offer(alice, 5, &sword))
+	_, err := trades.Confirm(bob)
+	assert.Nil(t, err)
+
+	_, changed := trades.Check(alice)
+	assert.False(t, changed)
+
+	// The sword leaves and junk takes its id
+	alice.Inventory.Remove(sword.ID)
+	junk := alice.Inventory.Add(Item{Name: "Junk", Type: Trinket})
+	assert.Equal(t, sword.ID, junk.ID)
+
+	// Exchanging checks the items themselves, not just their ids
+	_, err = Exchange(alice.Inventory, TradeOffer{Items: []Item{sword}}, bob.Inventory, TradeOffer{})
+	assert.ErrorIs(t, err, ErrorTradeFailed)
+
+	alice.Inventory.TakeGold(2)
+
+	other, changed := trades.Check(alice)
+	assert.True(t, changed)
+	assert.Equal(t, bob, other)
+
+	_, offers, confirmed, _ := trades.Status(alice)
+	assert.Equal(t, TradeOffer{Gold: 3, Items: []Item{}}, offers[0])
+	assert.Equal(t, [2]bool{false, false}, confirmed)
+}
@@ -1,8 +1,10 @@
 package game
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -28,6 +30,32 @@ func (w World) GetRoomById(roomId int) (*Room, bool) {
 	return room, exists
 }
 
+// Start runs every room on its own goroutine until the context is cancelled.
+func (w *World) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	roomGroup := sync.WaitGroup{}
+
+	for _, room := range w.rooms {
+		roomGroup.Add(1)
+		go room.Start(ctx, &roomGroup)
+	}
+
+	roomGroup.Wait()
+}
+
+// Do runs fn on the goroutine of the room with the given id and waits for it
+// to finish. False is returned if there's no such room.
+func (w World) Do(roomId int, fn func(room *Room)) bool {
+	room, exists := w.roomMap[roomId]
+	if !exists {
+		return false
+	}
+
+	room.Do(fn)
+	return true
+}
+
 // LoadRoomsFromYaml loads rooms from a YAML file.
 func (w *World) LoadRoomsFromYaml(file string) error {
 	data, err := os.ReadFile(file)
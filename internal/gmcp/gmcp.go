@@ -0,0 +1,114 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Core packages handled by the server.
+const (
+	CoreHello          = "Core.Hello"
+	CoreSupportsSet    = "Core.Supports.Set"
+	CoreSupportsAdd    = "Core.Supports.Add"
+	CoreSupportsRemove = "Core.Supports.Remove"
+)
+
+// Parse splits a GMCP message into its package name and JSON data.
+func Parse(message string) (string, []byte) {
+	message = strings.TrimSpace(message)
+
+	pkg, data, found := strings.Cut(message, " ")
+	if !found {
+		return pkg, nil
+	}
+
+	return pkg, []byte(strings.TrimSpace(data))
+}
+
+// Encode joins a package name and its JSON data into a GMCP message.
+func Encode(pkg string, data []byte) []byte {
+	if len(data) == 0 {
+		return []byte(pkg)
+	}
+
+	message := make([]byte, 0, len(pkg)+len(data)+1)
+	message = append(message, pkg...)
+	message = append(message, ' ')
+	message = append(message, data...)
+
+	return message
+}
+
+// Subscriptions tracks the GMCP modules a client has asked to receive.
+type Subscriptions struct {
+	modules map[string]bool
+	mutex   *sync.RWMutex
+}
+
+// NewSubscriptions creates a new empty Subscriptions instance.
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		modules: make(map[string]bool),
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Handle processes a Core.Supports message from the client. It returns true if
+// the message was a subscription change.
+func (s *Subscriptions) Handle(pkg string, data []byte) (bool, error) {
+	switch pkg {
+	case CoreSupportsSet, CoreSupportsAdd, CoreSupportsRemove:
+	default:
+		return false, nil
+	}
+
+	entries := []string{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return false, fmt.Errorf("invalid %s data: %w", pkg, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if pkg == CoreSupportsSet {
+		s.modules = make(map[string]bool)
+	}
+
+	for _, entry := range entries {
+		// Entries are formatted as "<module> <version>", e.g. "Char 1"
+		module, _, _ := strings.Cut(strings.TrimSpace(entry), " ")
+		if module == "" {
+			continue
+		}
+
+		if pkg == CoreSupportsRemove {
+			delete(s.modules, module)
+		} else {
+			s.modules[module] = true
+		}
+	}
+
+	return true, nil
+}
+
+// IsSubscribed checks if the package, or any module containing it, has been
+// subscribed to. For example "Char.Items.Inv" matches both "Char.Items" and "Char".
+func (s *Subscriptions) IsSubscribed(pkg string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for {
+		if s.modules[pkg] {
+			return true
+		}
+
+		index := strings.LastIndex(pkg, ".")
+		if index < 0 {
+			return false
+		}
+
+		pkg = pkg[:index]
+	}
+}
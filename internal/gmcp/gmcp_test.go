@@ -0,0 +1,49 @@
+package gmcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	pkg, data := Parse(`Core.Supports.Set ["Char 1", "Room 1"]`)
+	assert.Equal(t, CoreSupportsSet, pkg)
+	assert.Equal(t, `["Char 1", "Room 1"]`, string(data))
+
+	pkg, data = Parse("Core.Ping")
+	assert.Equal(t, "Core.Ping", pkg)
+	assert.Nil(t, data)
+
+	assert.Equal(t, `Room.Info {"num":1}`, string(Encode("Room.Info", []byte(`{"num":1}`))))
+}
+
+func TestSubscriptions(t *testing.T) {
+	subs := NewSubscriptions()
+
+	changed, err := subs.Handle(CoreSupportsSet, []byte(`["Char 1", "Room.Info 1"]`))
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	assert.True(t, subs.IsSubscribed("Char.Vitals"))
+	assert.True(t, subs.IsSubscribed("Char.Items.Inv"))
+	assert.True(t, subs.IsSubscribed("Room.Info"))
+	assert.False(t, subs.IsSubscribed("Comm.Channel"))
+
+	changed, err = subs.Handle(CoreSupportsAdd, []byte(`["Comm 1"]`))
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.True(t, subs.IsSubscribed("Comm.Channel"))
+
+	changed, err = subs.Handle(CoreSupportsRemove, []byte(`["Char"]`))
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.False(t, subs.IsSubscribed("Char.Vitals"))
+
+	changed, err = subs.Handle(CoreHello, []byte(`{"client":"Mudlet"}`))
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	_, err = subs.Handle(CoreSupportsSet, []byte(`not json`))
+	assert.NotNil(t, err)
+}
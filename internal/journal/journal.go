@@ -0,0 +1,325 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSegmentSize = 4 * 1024 * 1024 // Bytes written to a segment before starting the next one
+	SegmentExtension   = ".journal"
+)
+
+// ErrorStop can be returned by a Read callback to stop reading early.
+var ErrorStop = errors.New("stop reading journal")
+
+// Entry is a single record in the journal.
+type Entry struct {
+	Seq  uint64          `json:"seq"`
+	Time time.Time       `json:"time"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Decode decodes the entry's data into v.
+func (e Entry) Decode(v any) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("unable to decode journal entry %d (%s): %w", e.Seq, e.Type, err)
+	}
+
+	return nil
+}
+
+// Journal is a durable, append-only log split into segment files. Each segment
+// is named after the sequence number of its first entry and holds one JSON
+// encoded entry per line.
+type Journal struct {
+	dir         string
+	segmentSize int64
+
+	file    *os.File
+	written int64 // Bytes in the current segment
+	lastSeq uint64
+	mutex   *sync.Mutex
+}
+
+// Option configures a journal.
+type Option func(j *Journal)
+
+// WithSegmentSize sets how large a segment can grow before the next one is started.
+func WithSegmentSize(size int64) Option {
+	return func(j *Journal) {
+		if size > 0 {
+			j.segmentSize = size
+		}
+	}
+}
+
+// Open opens the journal in dir, creating the directory if needed. Appending
+// continues after the last complete entry, a partially written entry left by
+// a crash is discarded.
+func Open(dir string, options ...Option) (*Journal, error) {
+	j := &Journal{
+		dir:         dir,
+		segmentSize: DefaultSegmentSize,
+		mutex:       &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(j)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create journal directory %s: %w", dir, err)
+	}
+
+	segments, err := segmentFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return j, nil
+	}
+
+	last := segments[len(segments)-1]
+
+	lastSeq, size, err := scanSegment(last)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop anything after the last complete entry
+	if err := os.Truncate(last, size); err != nil {
+		return nil, fmt.Errorf("unable to repair journal segment %s: %w", last, err)
+	}
+
+	file, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open journal segment %s: %w", last, err)
+	}
+
+	j.file = file
+	j.written = size
+	j.lastSeq = lastSeq
+
+	if j.lastSeq == 0 {
+		j.lastSeq = segmentSeq(last) - 1
+	}
+
+	return j, nil
+}
+
+// Append writes an entry to the journal and syncs it to disk, returning its
+// sequence number.
+func (j *Journal) Append(typ string, data any) (uint64, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return 0, fmt.Errorf("unable to encode journal entry %s: %w", typ, err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry := Entry{Seq: j.lastSeq + 1, Time: time.Now().UTC(), Type: typ, Data: payload}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, fmt.Errorf("unable to encode journal entry %s: %w", typ, err)
+	}
+
+	line = append(line, '\n')
+
+	if j.file == nil || (j.written > 0 && j.written+int64(len(line)) > j.segmentSize) {
+		if err := j.startSegment(entry.Seq); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := j.file.Write(line); err != nil {
+		return 0, fmt.Errorf("unable to write journal entry %d: %w", entry.Seq, err)
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return 0, fmt.Errorf("unable to sync journal entry %d: %w", entry.Seq, err)
+	}
+
+	j.written += int64(len(line))
+	j.lastSeq = entry.Seq
+
+	return entry.Seq, nil
+}
+
+// LastSeq returns the sequence number of the last entry written.
+func (j *Journal) LastSeq() uint64 {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.lastSeq
+}
+
+// Close closes the current segment.
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
+
+// startSegment closes the current segment and starts a new one beginning at
+// seq. The caller must hold the lock.
+func (j *Journal) startSegment(seq uint64) error {
+	if j.file != nil {
+		if err := j.file.Close(); err != nil {
+			return fmt.Errorf("unable to close journal segment: %w", err)
+		}
+	}
+
+	path := filepath.Join(j.dir, fmt.Sprintf("%020d%s", seq, SegmentExtension))
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to create journal segment %s: %w", path, err)
+	}
+
+	j.file = file
+	j.written = 0
+
+	return nil
+}
+
+// Read calls fn for every entry in the journal in dir, oldest first, starting
+// after the entry numbered after. Reading stops at the first error fn returns,
+// ErrorStop stops without an error.
+func Read(dir string, after uint64, fn func(entry Entry) error) error {
+	segments, err := segmentFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, segment := range segments {
+		// Skip segments which end before the first entry wanted
+		if i+1 < len(segments) && segmentSeq(segments[i+1]) <= after+1 {
+			continue
+		}
+
+		err := readSegment(segment, func(entry Entry, size int64) error {
+			if entry.Seq <= after {
+				return nil
+			}
+
+			return fn(entry)
+		})
+
+		if errors.Is(err, ErrorStop) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readSegment calls fn for each complete entry in a segment, along with the
+// number of bytes it takes up.
+func readSegment(path string, fn func(entry Entry, size int64) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open journal segment %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A trailing partial line is an entry that was never completely written
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read journal segment %s: %w", path, err)
+		}
+
+		entry := Entry{}
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			return fmt.Errorf("corrupt entry in journal segment %s: %w", path, err)
+		}
+
+		if err := fn(entry, int64(len(line))); err != nil {
+			return err
+		}
+	}
+}
+
+// scanSegment returns the last sequence number in a segment, and the size of
+// the segment up to the end of its last complete entry.
+func scanSegment(path string) (uint64, int64, error) {
+	lastSeq, size := uint64(0), int64(0)
+
+	err := readSegment(path, func(entry Entry, entrySize int64) error {
+		lastSeq = entry.Seq
+		size += entrySize
+
+		return nil
+	})
+
+	return lastSeq, size, err
+}
+
+// segmentFiles returns the paths of the segments in dir, oldest first.
+func segmentFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+
+		return nil, fmt.Errorf("unable to list journal directory %s: %w", dir, err)
+	}
+
+	segments := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), SegmentExtension) {
+			continue
+		}
+
+		segments = append(segments, filepath.Join(dir, entry.Name()))
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segmentSeq(segments[i]) < segmentSeq(segments[j])
+	})
+
+	return segments, nil
+}
+
+// segmentSeq returns the sequence number a segment starts at.
+func segmentSeq(path string) uint64 {
+	name := strings.TrimSuffix(filepath.Base(path), SegmentExtension)
+
+	seq, _ := strconv.ParseUint(name, 10, 64)
+	return seq
+}
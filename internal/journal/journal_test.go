@@ -0,0 +1,103 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	Name string `json:"name"`
+}
+
+// readAll returns the sequence numbers and names of the entries after seq.
+func readAll(t *testing.T, dir string, after uint64) ([]uint64, []string) {
+	seqs, names := []uint64{}, []string{}
+
+	err := Read(dir, after, func(entry Entry) error {
+		record := testRecord{}
+		assert.Nil(t, entry.Decode(&record))
+
+		seqs = append(seqs, entry.Seq)
+		names = append(names, record.Name)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	return seqs, names
+}
+
+func TestJournalAppend(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir, WithSegmentSize(200))
+	assert.Nil(t, err)
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		_, err := j.Append("test", testRecord{Name: name})
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, uint64(5), j.LastSeq())
+	assert.Nil(t, j.Close())
+
+	segments, err := segmentFiles(dir)
+	assert.Nil(t, err)
+	assert.Greater(t, len(segments), 1)
+
+	seqs, names := readAll(t, dir, 0)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, seqs)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names)
+
+	seqs, _ = readAll(t, dir, 3)
+	assert.Equal(t, []uint64{4, 5}, seqs)
+
+	// Stopping early isn't an error
+	count := 0
+	err = Read(dir, 0, func(entry Entry) error {
+		count++
+		return ErrorStop
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestJournalReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir)
+	assert.Nil(t, err)
+
+	_, err = j.Append("test", testRecord{Name: "a"})
+	assert.Nil(t, err)
+	assert.Nil(t, j.Close())
+
+	// Simulate a crash in the middle of writing an entry
+	segments, err := segmentFiles(dir)
+	assert.Nil(t, err)
+
+	file, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.Nil(t, err)
+	_, err = file.WriteString(`{"seq":2,"type":"te`)
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	j, err = Open(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), j.LastSeq())
+
+	seq, err := j.Append("test", testRecord{Name: "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), seq)
+	assert.Nil(t, j.Close())
+
+	seqs, names := readAll(t, dir, 0)
+	assert.Equal(t, []uint64{1, 2}, seqs)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	// A missing journal has no entries
+	seqs, _ = readAll(t, filepath.Join(dir, "missing"), 0)
+	assert.Empty(t, seqs)
+}
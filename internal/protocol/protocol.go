@@ -0,0 +1,171 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	Version1 = 1 // Length-prefixed JSON envelopes
+	Version2 = 2 // Length-prefixed protobuf messages defined in api/proto/stream.proto
+
+	FrameHeaderSize = 4         // Big-endian uint32 payload length
+	MaxFrameSize    = 64 * 1024 // Largest frame that can be written
+	CommandPrompt   = "> "      // Default prompt text
+)
+
+// SupportedVersions lists the protocol versions understood by this build, in
+// order of preference. The hello exchange is always a JSON envelope, so a client
+// can negotiate before knowing which version the server will pick.
+var SupportedVersions = []int{Version2, Version1}
+
+type MessageType string
+
+// Envelope types
+const (
+	TypeHello   MessageType = "hello"   // version negotiation, sent by both sides once at connect
+	TypeCommand MessageType = "command" // client -> server command input
+	TypeOutput  MessageType = "output"  // server -> client text output
+	TypeEvent   MessageType = "event"   // server -> client game event
+	TypePrompt  MessageType = "prompt"  // server -> client input prompt
+	TypeError   MessageType = "error"   // error message
+	TypeOOB     MessageType = "oob"     // out-of-band (GMCP) package, sent in both directions
+	TypeTyping  MessageType = "typing"  // client -> server typing indicator
+	TypeSession MessageType = "session" // server -> client resume token
+)
+
+// Envelope is a single typed message exchanged over the game stream.
+type Envelope struct {
+	Type     MessageType     `json:"type"`
+	Text     string          `json:"text,omitempty"`     // command, output, prompt and error text
+	Package  string          `json:"package,omitempty"`  // out-of-band package name
+	Data     json.RawMessage `json:"data,omitempty"`     // event and out-of-band data
+	Versions []int           `json:"versions,omitempty"` // versions supported by the client
+	Version  int             `json:"version,omitempty"`  // version selected by the server
+
+	// Datagrams is set in the client hello when it can receive ephemeral updates as
+	// WebTransport datagrams, and echoed by the server when it will send them.
+	Datagrams bool `json:"datagrams,omitempty"`
+}
+
+// Marshal encodes an envelope.
+func Marshal(env Envelope) ([]byte, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode %s envelope: %w", env.Type, err)
+	}
+
+	return data, nil
+}
+
+// Unmarshal decodes an envelope.
+func Unmarshal(data []byte) (Envelope, error) {
+	env := Envelope{}
+
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, fmt.Errorf("unable to decode envelope: %w", err)
+	}
+
+	if env.Type == "" {
+		return env, fmt.Errorf("envelope type is missing")
+	}
+
+	return env, nil
+}
+
+// WriteFrame writes a length-prefixed frame.
+func WriteFrame(w io.Writer, payload []byte) error {
+	if len(payload) > MaxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds max size %d", len(payload), MaxFrameSize)
+	}
+
+	frame := make([]byte, FrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[FrameHeaderSize:], payload)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads a single length-prefixed frame, rejecting frames larger than maxSize.
+func ReadFrame(r io.Reader, maxSize int) ([]byte, error) {
+	header := make([]byte, FrameHeaderSize)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint32(header))
+	if size > maxSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds max size %d", size, maxSize)
+	}
+
+	payload := make([]byte, size)
+
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// WriteEnvelope encodes and writes an envelope as a single frame.
+func WriteEnvelope(w io.Writer, env Envelope) error {
+	data, err := Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return WriteFrame(w, data)
+}
+
+// ReadEnvelope reads and decodes a single frame.
+func ReadEnvelope(r io.Reader, maxSize int) (Envelope, error) {
+	data, err := ReadFrame(r, maxSize)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Unmarshal(data)
+}
+
+// WriteMessage encodes and writes a protobuf message as a single frame.
+func WriteMessage(w io.Writer, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	return WriteFrame(w, data)
+}
+
+// ReadMessage reads a single frame and decodes it into m.
+func ReadMessage(r io.Reader, maxSize int, m proto.Message) error {
+	data, err := ReadFrame(r, maxSize)
+	if err != nil {
+		return err
+	}
+
+	if err = proto.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("unable to decode message: %w", err)
+	}
+
+	return nil
+}
+
+// Negotiate selects the preferred version supported by both sides.
+func Negotiate(clientVersions []int) (int, error) {
+	for _, version := range SupportedVersions {
+		for _, clientVersion := range clientVersions {
+			if version == clientVersion {
+				return version, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("no supported protocol version in %v, server supports %v", clientVersions, SupportedVersions)
+}
@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/api"
+)
+
+func TestFrames(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	// Two frames written back to back must be read back individually
+	assert.Nil(t, WriteEnvelope(buffer, Envelope{Type: TypeOutput, Text: "You look around the room\n"}))
+	assert.Nil(t, WriteEnvelope(buffer, Envelope{Type: TypeEvent, Data: json.RawMessage(`{"type":"RoomChat"}`)}))
+
+	env, err := ReadEnvelope(buffer, MaxFrameSize)
+	assert.Nil(t, err)
+	assert.Equal(t, TypeOutput, env.Type)
+	assert.Equal(t, "You look around the room\n", env.Text)
+
+	env, err = ReadEnvelope(buffer, MaxFrameSize)
+	assert.Nil(t, err)
+	assert.Equal(t, TypeEvent, env.Type)
+	assert.JSONEq(t, `{"type":"RoomChat"}`, string(env.Data))
+
+	assert.Nil(t, WriteFrame(buffer, bytes.Repeat([]byte("a"), 32)))
+	_, err = ReadFrame(buffer, 16)
+	assert.NotNil(t, err)
+
+	_, err = Unmarshal([]byte(`{"text":"missing type"}`))
+	assert.NotNil(t, err)
+}
+
+func TestMessages(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	chat := &api.RoomChat{Talker: "Alice", Text: "hello", Timestamp: 1700000000000}
+	assert.Nil(t, WriteMessage(buffer, &api.ServerMessage{Payload: &api.ServerMessage_RoomChat{RoomChat: chat}}))
+	assert.Nil(t, WriteMessage(buffer, &api.ServerMessage{Payload: &api.ServerMessage_Prompt{Prompt: &api.Prompt{Text: CommandPrompt}}}))
+
+	msg := &api.ServerMessage{}
+	assert.Nil(t, ReadMessage(buffer, MaxFrameSize, msg))
+	assert.Equal(t, "Alice", msg.GetRoomChat().GetTalker())
+	assert.Equal(t, "hello", msg.GetRoomChat().GetText())
+	assert.Equal(t, int64(1700000000000), msg.GetRoomChat().GetTimestamp())
+
+	msg = &api.ServerMessage{}
+	assert.Nil(t, ReadMessage(buffer, MaxFrameSize, msg))
+	assert.Equal(t, CommandPrompt, msg.GetPrompt().GetText())
+
+	assert.Nil(t, WriteFrame(buffer, []byte{0xff, 0xff}))
+	assert.NotNil(t, ReadMessage(buffer, MaxFrameSize, msg))
+}
+
+func TestNegotiate(t *testing.T) {
+	version, err := Negotiate([]int{99, Version1})
+	assert.Nil(t, err)
+	assert.Equal(t, Version1, version)
+
+	// The server's preference wins
+	version, err = Negotiate([]int{Version1, Version2})
+	assert.Nil(t, err)
+	assert.Equal(t, Version2, version)
+
+	_, err = Negotiate([]int{99})
+	assert.NotNil(t, err)
+
+	_, err = Negotiate(nil)
+	assert.NotNil(t, err)
+}
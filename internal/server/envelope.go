@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/xealgo/muddy/internal/command"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/gmcp"
+	"github.com/xealgo/muddy/internal/protocol"
+)
+
+// negotiateVersion reads the client hello and replies with the selected protocol
+// version. Datagrams are only accepted when the transport supports them.
+func negotiateVersion(read func() (protocol.Envelope, error), write func(protocol.Envelope) error, datagrams bool) (protocol.Envelope, error) {
+	hello, err := read()
+	if err != nil {
+		return hello, fmt.Errorf("failed to read hello: %w", err)
+	}
+
+	if hello.Type != protocol.TypeHello {
+		return hello, fmt.Errorf("expected %s envelope, received %s", protocol.TypeHello, hello.Type)
+	}
+
+	version, err := protocol.Negotiate(hello.Versions)
+	if err != nil {
+		return hello, err
+	}
+
+	reply := protocol.Envelope{
+		Type:      protocol.TypeHello,
+		Version:   version,
+		Datagrams: datagrams && hello.Datagrams,
+	}
+
+	if err = write(reply); err != nil {
+		return reply, fmt.Errorf("failed to write hello: %w", err)
+	}
+
+	return reply, nil
+}
+
+// newEnvelope wraps a game message in an envelope.
+func newEnvelope(typ game.MessageType, message []byte) protocol.Envelope {
+	switch typ {
+	case game.MessageEvent:
+		return protocol.Envelope{Type: protocol.TypeEvent, Data: json.RawMessage(message)}
+	case game.MessagePrompt:
+		return protocol.Envelope{Type: protocol.TypePrompt, Text: string(message)}
+	case game.MessageError:
+		return protocol.Envelope{Type: protocol.TypeError, Text: string(message)}
+	case game.MessageSession:
+		return protocol.Envelope{Type: protocol.TypeSession, Text: string(message)}
+	default:
+		return protocol.Envelope{Type: protocol.TypeOutput, Text: string(message)}
+	}
+}
+
+// newOutOfBandEnvelope wraps a GMCP package in an envelope.
+func newOutOfBandEnvelope(pkg string, data []byte) protocol.Envelope {
+	return protocol.Envelope{Type: protocol.TypeOOB, Package: pkg, Data: json.RawMessage(data)}
+}
+
+// handleEnvelope processes an envelope received from a client speaking the
+// framed protocol. errPlayerQuit is returned when the player quits.
+func handleEnvelope(g *game.Game, runner *command.Runner, subscriptions *gmcp.Subscriptions, player *game.Player, env protocol.Envelope) error {
+	switch env.Type {
+	case protocol.TypeCommand:
+		if isQuitCommand(env.Text) {
+			player.WriteString("Until next time!\n")
+			player.Flush()
+			return errPlayerQuit
+		}
+
+		response, err := runner.Execute(player, env.Text)
+		if err != nil {
+			if err = player.WriteError(err.Error()); err != nil {
+				return err
+			}
+		} else if response != "" {
+			if err = player.WriteString(response); err != nil {
+				return err
+			}
+		}
+
+		return player.WritePrompt(protocol.CommandPrompt)
+	case protocol.TypeOOB:
+		handleOutOfBand(g, subscriptions, player, env.Package, env.Data)
+	case protocol.TypeTyping:
+		g.PlayerTyping(player)
+	default:
+		slog.Warn("Unexpected envelope from client", "uuid", player.GetUUID(), "type", env.Type)
+	}
+
+	return nil
+}
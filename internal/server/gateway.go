@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	GatewayPathPrefix = "/api/"
+	GatewayLoginPath  = "/api/login"
+	GatewayStatusPath = "/api/status"
+	GatewayQueuePath  = "/api/queue"
+)
+
+// gatewayLoginRequest is the JSON body accepted by the login endpoint.
+type gatewayLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// gatewayLoginResponse is the JSON body returned by the login endpoint. The
+// streaming port lets browser clients find the game stream after logging in.
+// A non-zero queue position means the player has to wait for a free slot.
+type gatewayLoginResponse struct {
+	SessionUUID      string `json:"sessionUuid"`
+	WebTransportPort int    `json:"webTransportPort"`
+	QueuePosition    int32  `json:"queuePosition"`
+}
+
+// GatewayError is the JSON error body returned by every gateway endpoint.
+type GatewayError struct {
+	Error GatewayErrorDetails `json:"error"`
+}
+
+// GatewayErrorDetails describes what went wrong.
+type GatewayErrorDetails struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// gateway maps JSON endpoints onto the gRPC service implementations.
+type gateway struct {
+	server *HttpServer
+	login  *services.LoginService
+	health *services.HealthService
+}
+
+// WithGateway exposes the login and health services as JSON endpoints for
+// clients that can't call the gRPC services, such as browsers and scripts.
+//
+//	POST /api/login  {"username": "...", "password": "..."}
+//	GET  /api/status
+//	GET  /api/queue?uuid=...
+func WithGateway(login *services.LoginService, health *services.HealthService) HttpRouteHandler {
+	return func(server *HttpServer) error {
+		if login == nil || health == nil {
+			return fmt.Errorf("login and health services are required for the gateway")
+		}
+
+		gw := &gateway{
+			server: server,
+			login:  login,
+			health: health,
+		}
+
+		http.HandleFunc(GatewayLoginPath, gw.handleLogin)
+		http.HandleFunc(GatewayStatusPath, gw.handleStatus)
+		http.HandleFunc(GatewayQueuePath, gw.handleQueue)
+		http.HandleFunc(GatewayPathPrefix, func(w http.ResponseWriter, r *http.Request) {
+			writeGatewayError(w, http.StatusNotFound, "not_found", "no such endpoint")
+		})
+
+		return nil
+	}
+}
+
+// handleLogin registers a new player session.
+func (gw *gateway) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeGatewayError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use POST")
+		return
+	}
+
+	req := gatewayLoginRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeGatewayError(w, http.StatusBadRequest, "invalid_request", "request body must be JSON")
+		return
+	}
+
+	resp, err := gw.login.Login(services.WithRemoteAddr(r.Context(), r.RemoteAddr), &api.LoginRequest{Username: req.Username, Password: req.Password})
+	if err != nil {
+		writeGatewayStatusError(w, err)
+		return
+	}
+
+	writeGatewayJSON(w, http.StatusOK, gatewayLoginResponse{
+		SessionUUID:      resp.SessionUuid,
+		WebTransportPort: gw.server.cfg.WTPort,
+		QueuePosition:    resp.QueuePosition,
+	})
+}
+
+// handleQueue reports a waiting player's place in the login queue.
+func (gw *gateway) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeGatewayError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET")
+		return
+	}
+
+	uuid := r.URL.Query().Get(SessionUUIDParam)
+	if uuid == "" {
+		writeGatewayError(w, http.StatusBadRequest, "invalid_request", "missing uuid")
+		return
+	}
+
+	resp, err := gw.login.GetQueuePosition(r.Context(), &api.QueueRequest{SessionUuid: uuid})
+	if err != nil {
+		writeGatewayStatusError(w, err)
+		return
+	}
+
+	writeGatewayProto(w, http.StatusOK, resp)
+}
+
+// handleStatus reports the server health.
+func (gw *gateway) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeGatewayError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET")
+		return
+	}
+
+	resp, err := gw.health.GetStatus(r.Context(), &api.StatusRequest{})
+	if err != nil {
+		writeGatewayStatusError(w, err)
+		return
+	}
+
+	writeGatewayProto(w, http.StatusOK, resp)
+}
+
+// writeGatewayJSON writes a JSON response body.
+func writeGatewayJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Failed to write gateway response", "error", err)
+	}
+}
+
+// writeGatewayProto writes a protobuf message as a JSON response body.
+func writeGatewayProto(w http.ResponseWriter, code int, msg proto.Message) {
+	data, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		writeGatewayError(w, http.StatusInternalServerError, "internal", "unable to encode response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// writeGatewayError writes a JSON error body.
+func writeGatewayError(w http.ResponseWriter, code int, errCode string, message string) {
+	writeGatewayJSON(w, code, GatewayError{
+		Error: GatewayErrorDetails{Code: errCode, Message: message},
+	})
+}
+
+// writeGatewayStatusError converts a service error into a JSON error body.
+func writeGatewayStatusError(w http.ResponseWriter, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		slog.Error("Gateway request failed", "error", err)
+		writeGatewayError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		writeGatewayError(w, http.StatusBadRequest, "invalid_argument", st.Message())
+	case codes.Unauthenticated:
+		writeGatewayError(w, http.StatusUnauthorized, "unauthenticated", st.Message())
+	case codes.PermissionDenied:
+		writeGatewayError(w, http.StatusForbidden, "permission_denied", st.Message())
+	case codes.NotFound:
+		writeGatewayError(w, http.StatusNotFound, "not_found", st.Message())
+	case codes.AlreadyExists:
+		writeGatewayError(w, http.StatusConflict, "already_exists", st.Message())
+	case codes.ResourceExhausted:
+		writeGatewayError(w, http.StatusServiceUnavailable, "resource_exhausted", st.Message())
+	case codes.Unavailable:
+		writeGatewayError(w, http.StatusServiceUnavailable, "unavailable", st.Message())
+	default:
+		slog.Error("Gateway request failed", "error", err)
+		writeGatewayError(w, http.StatusInternalServerError, "internal", "internal server error")
+	}
+}
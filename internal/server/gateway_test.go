@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/internal/config"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/services"
+)
+
+func TestGateway(t *testing.T) {
+	cfg := &config.Config{WTPort: 17002, Admins: []string{"root"}, Builders: []string{"Boss"}}
+	sm := game.NewSessionManager(1)
+	state := game.NewGameState()
+
+	// Privileged accounts are made by the operator
+	accounts := game.NewAccounts()
+	assert.Nil(t, accounts.SetPassword("boss", "secret3"))
+
+	hs, err := NewHttpServer(
+		cfg,
+		WithCORSHandler(),
+		WithGateway(services.NewLoginService(cfg, sm, accounts), services.NewHealthService(cfg, state, sm)),
+	)
+	assert.Nil(t, err)
+
+	handler := hs.handler()
+
+	type GatewayTest struct {
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedErr  string
+		expectedBody string
+	}
+
+	tests := []GatewayTest{
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"alice","password":"secret1"}`, expectedCode: http.StatusOK},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"alice","password":"secret2"}`, expectedCode: http.StatusUnauthorized, expectedErr: "unauthenticated"},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"bob","password":"secret2"}`, expectedCode: http.StatusOK, expectedBody: `"queuePosition":1`},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"Root","password":"secret1"}`, expectedCode: http.StatusForbidden, expectedErr: "permission_denied"},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"boss","password":"secret3"}`, expectedCode: http.StatusOK, expectedBody: `"queuePosition":1`},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"al","password":"secret1"}`, expectedCode: http.StatusBadRequest, expectedErr: "invalid_argument"},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `{"username":"carol","password":"short"}`, expectedCode: http.StatusBadRequest, expectedErr: "invalid_argument"},
+		{method: http.MethodPost, path: GatewayLoginPath, body: `nope`, expectedCode: http.StatusBadRequest, expectedErr: "invalid_request"},
+		{method: http.MethodGet, path: GatewayLoginPath, expectedCode: http.StatusMethodNotAllowed, expectedErr: "method_not_allowed"},
+		{method: http.MethodGet, path: GatewayStatusPath, expectedCode: http.StatusOK},
+		{method: http.MethodGet, path: GatewayQueuePath, expectedCode: http.StatusBadRequest, expectedErr: "invalid_request"},
+		{method: http.MethodGet, path: GatewayQueuePath + "?uuid=unknown", expectedCode: http.StatusNotFound, expectedErr: "not_found"},
+		{method: http.MethodGet, path: "/api/nothing", expectedCode: http.StatusNotFound, expectedErr: "not_found"},
+		{method: http.MethodOptions, path: GatewayLoginPath, expectedCode: http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, test.expectedCode, rec.Code, test.path)
+		assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+
+		if test.expectedErr != "" {
+			body := GatewayError{}
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, test.expectedErr, body.Error.Code)
+		}
+
+		if test.expectedBody != "" {
+			assert.Contains(t, rec.Body.String(), test.expectedBody)
+		}
+	}
+
+	// Failed logins and new accounts are limited per address
+	login := func(remote string, body string) int {
+		req := httptest.NewRequest(http.MethodPost, GatewayLoginPath, strings.NewReader(body))
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i := range services.LoginAttemptLimit {
+		assert.Equal(t, http.StatusUnauthorized, login(fmt.Sprintf("198.51.100.1:%d", 1000+i), `{"username":"alice","password":"guess1"}`))
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, login("198.51.100.1:2000", `{"username":"alice","password":"secret1"}`))
+	assert.Equal(t, http.StatusServiceUnavailable, login("198.51.100.1:2001", `{"username":"dave","password":"secret4"}`))
+	assert.Equal(t, http.StatusOK, login("198.51.100.2:1000", `{"username":"dave","password":"secret4"}`))
+
+	// Logging in with the right password doesn't count
+	for range services.LoginAttemptLimit + 1 {
+		assert.Equal(t, http.StatusOK, login("198.51.100.3:1000", `{"username":"boss","password":"secret3"}`))
+	}
+}
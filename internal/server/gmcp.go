@@ -0,0 +1,24 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/gmcp"
+)
+
+// handleOutOfBand processes a GMCP package sent by the client. When the client
+// changes its subscriptions, the full state is pushed again. The player may be
+// nil if the client hasn't logged in yet.
+func handleOutOfBand(g *game.Game, subscriptions *gmcp.Subscriptions, player *game.Player, pkg string, data []byte) {
+	changed, err := subscriptions.Handle(pkg, data)
+	if err != nil {
+		slog.Warn("Invalid GMCP message", "error", err)
+		return
+	}
+
+	if changed && player != nil {
+		player.ResetOutOfBand()
+		g.SyncOutOfBand(player)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 
 type HttpRouteHandler func(server *HttpServer) error
 type HttpServerListener func() error
+type HttpMiddleware func(next http.Handler) http.Handler
 
 // HttpServerStaticFileConfig represents configuration for serving a static file
 type HttpServerStaticFileConfig struct {
@@ -23,8 +26,9 @@ type HttpServerStaticFileConfig struct {
 
 // HttpServer represents an HTTP server configuration
 type HttpServer struct {
-	addr string
-	cfg  *config.Config
+	addr       string
+	cfg        *config.Config
+	middleware []HttpMiddleware
 }
 
 // NewHttpServer creates a new HttpServer instance
@@ -49,6 +53,7 @@ func (server HttpServer) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	h := &http.Server{
 		Addr:      server.addr,
 		TLSConfig: server.cfg.TLSConfig,
+		Handler:   server.handler(),
 	}
 
 	defer wg.Done()
@@ -94,6 +99,18 @@ func (server HttpServer) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	return nil
 }
 
+// handler wraps the default mux with the configured middleware. The first
+// middleware added is the outermost.
+func (server HttpServer) handler() http.Handler {
+	var handler http.Handler = http.DefaultServeMux
+
+	for i := len(server.middleware) - 1; i >= 0; i-- {
+		handler = server.middleware[i](handler)
+	}
+
+	return handler
+}
+
 // WithStaticFiles configures static file serving for the HTTP server
 func WithStaticPageHandlers(entries ...HttpServerStaticFileConfig) HttpRouteHandler {
 	return func(server *HttpServer) error {
@@ -108,8 +125,13 @@ func WithStaticPageHandlers(entries ...HttpServerStaticFileConfig) HttpRouteHand
 				continue
 			}
 
+			contentType := mime.TypeByExtension(filepath.Ext(staticFile.FilePath))
+			if contentType == "" {
+				contentType = "text/html"
+			}
+
 			http.HandleFunc(staticFile.Path, func(w http.ResponseWriter, r *http.Request) {
-				w.Header().Set("Content-Type", "text/html")
+				w.Header().Set("Content-Type", contentType)
 				http.ServeFile(w, r, staticFile.FilePath)
 			})
 		}
@@ -118,18 +140,22 @@ func WithStaticPageHandlers(entries ...HttpServerStaticFileConfig) HttpRouteHand
 	}
 }
 
-// WithCORSHandler adds CORS headers to HTTP responses
+// WithCORSHandler adds CORS headers to every HTTP response and answers preflight requests
 func WithCORSHandler() HttpRouteHandler {
 	return func(server *HttpServer) error {
-		http.HandleFunc("/*", func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+		server.middleware = append(server.middleware, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+				if r.Method == http.MethodOptions {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			})
 		})
 
 		return nil
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/event"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/protocol"
+)
+
+// movementKinds maps movement event types to their protobuf kind.
+var movementKinds = map[string]api.PlayerMovement_Kind{
+	event.PlayerArrived:  api.PlayerMovement_ARRIVED,
+	event.PlayerDeparted: api.PlayerMovement_DEPARTED,
+	event.PlayerJoined:   api.PlayerMovement_JOINED,
+	event.PlayerQuit:     api.PlayerMovement_QUIT,
+}
+
+// newServerMessage converts a game message into a protobuf message for clients
+// speaking protocol version 2.
+func newServerMessage(typ game.MessageType, message []byte) (*api.ServerMessage, error) {
+	switch typ {
+	case game.MessageEvent:
+		return newEventMessage(message)
+	case game.MessagePrompt:
+		return &api.ServerMessage{Payload: &api.ServerMessage_Prompt{Prompt: &api.Prompt{Text: string(message)}}}, nil
+	case game.MessageError:
+		return &api.ServerMessage{Payload: &api.ServerMessage_Error{Error: &api.Error{Message: string(message)}}}, nil
+	case game.MessageSession:
+		return &api.ServerMessage{Payload: &api.ServerMessage_Session{Session: &api.Session{ResumeToken: string(message)}}}, nil
+	default:
+		return &api.ServerMessage{Payload: &api.ServerMessage_Output{Output: &api.TextOutput{Text: string(message)}}}, nil
+	}
+}
+
+// newEventMessage converts a JSON encoded event into its protobuf message.
+func newEventMessage(message []byte) (*api.ServerMessage, error) {
+	e, err := event.Unmarshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	switch data := e.Data.(type) {
+	case event.RoomChatData:
+		chat := &api.RoomChat{
+			Talker:    data.Talker,
+			Text:      data.Text,
+			Timestamp: e.Timestamp.UnixMilli(),
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_RoomChat{RoomChat: chat}}, nil
+	case event.EmoteData:
+		emote := &api.RoomEmote{
+			Actor:     data.Actor,
+			Text:      data.Text,
+			Timestamp: e.Timestamp.UnixMilli(),
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Emote{Emote: emote}}, nil
+	case event.MovementData:
+		movement := &api.PlayerMovement{
+			Kind:      movementKinds[e.Type],
+			Player:    data.Player,
+			Direction: data.Direction,
+			Timestamp: e.Timestamp.UnixMilli(),
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Movement{Movement: movement}}, nil
+	default:
+		return nil, fmt.Errorf("no message defined for %s events", e.Type)
+	}
+}
+
+// newOutOfBandMessage converts a GMCP package into a protobuf message. Packages
+// without a dedicated message are passed through as raw JSON.
+func newOutOfBandMessage(pkg string, data []byte) (*api.ServerMessage, error) {
+	switch pkg {
+	case game.OOBRoomInfo:
+		info := game.RoomInfoData{}
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", pkg, err)
+		}
+
+		room := &api.RoomDescription{
+			Id:          int32(info.ID),
+			Name:        info.Name,
+			Description: info.Description,
+			Items:       newItems(info.Items),
+			Players:     info.Players,
+			Npcs:        info.Npcs,
+		}
+
+		for name, roomId := range info.Exits {
+			room.Exits = append(room.Exits, &api.Exit{Name: name, RoomId: int32(roomId)})
+		}
+
+		sort.Slice(room.Exits, func(i, j int) bool {
+			return room.Exits[i].Name < room.Exits[j].Name
+		})
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Room{Room: room}}, nil
+	case game.OOBCharItemsInv:
+		inventory := game.InventoryData{}
+		if err := json.Unmarshal(data, &inventory); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", pkg, err)
+		}
+
+		snapshot := &api.InventorySnapshot{Items: newItems(inventory.Items)}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Inventory{Inventory: snapshot}}, nil
+	case game.OOBCharVitals:
+		vitals := game.VitalsData{}
+		if err := json.Unmarshal(data, &vitals); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", pkg, err)
+		}
+
+		msg := &api.Vitals{
+			Health:    int32(vitals.Health),
+			MaxHealth: int32(vitals.MaxHealth),
+			Gold:      int32(vitals.Gold),
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Vitals{Vitals: msg}}, nil
+	case game.OOBCommTyping:
+		typing := game.TypingData{}
+		if err := json.Unmarshal(data, &typing); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", pkg, err)
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Typing{Typing: &api.Typing{Talker: typing.Talker}}}, nil
+	case game.OOBRoomPlayers:
+		presence := game.RoomPlayersData{}
+		if err := json.Unmarshal(data, &presence); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", pkg, err)
+		}
+
+		msg := &api.Presence{
+			RoomId:  int32(presence.ID),
+			Players: presence.Players,
+		}
+
+		return &api.ServerMessage{Payload: &api.ServerMessage_Presence{Presence: msg}}, nil
+	default:
+		return &api.ServerMessage{Payload: &api.ServerMessage_Oob{Oob: &api.OutOfBand{Package: pkg, Data: data}}}, nil
+	}
+}
+
+// newItems converts out-of-band item data into protobuf items.
+func newItems(items []game.InventoryItemData) []*api.Item {
+	out := make([]*api.Item, 0, len(items))
+
+	for _, item := range items {
+		out = append(out, &api.Item{Id: item.ID, Name: item.Name})
+	}
+
+	return out
+}
+
+// clientMessageEnvelope converts a protobuf client message into the equivalent
+// envelope so both protocol versions share the same handling.
+func clientMessageEnvelope(msg *api.ClientMessage) protocol.Envelope {
+	switch payload := msg.GetPayload().(type) {
+	case *api.ClientMessage_Command:
+		return protocol.Envelope{Type: protocol.TypeCommand, Text: payload.Command.GetText()}
+	case *api.ClientMessage_Oob:
+		return protocol.Envelope{Type: protocol.TypeOOB, Package: payload.Oob.GetPackage(), Data: payload.Oob.GetData()}
+	case *api.ClientMessage_Typing:
+		return protocol.Envelope{Type: protocol.TypeTyping}
+	default:
+		return protocol.Envelope{Type: "unknown"}
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/event"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/protocol"
+)
+
+func TestServerMessages(t *testing.T) {
+	msg, err := newServerMessage(game.MessageOutput, []byte("You look around the room\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "You look around the room\n", msg.GetOutput().GetText())
+
+	msg, err = newServerMessage(game.MessageError, []byte("Invalid command"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Invalid command", msg.GetError().GetMessage())
+
+	msg, err = newServerMessage(game.MessageSession, []byte("token"))
+	assert.Nil(t, err)
+	assert.Equal(t, "token", msg.GetSession().GetResumeToken())
+
+	timestamp := time.UnixMilli(1700000000000)
+	data, _ := json.Marshal(event.Event{
+		Type:      event.RoomChat,
+		Timestamp: timestamp,
+		Data:      event.RoomChatData{Talker: "Alice", Text: "hello"},
+	})
+
+	msg, err = newServerMessage(game.MessageEvent, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "Alice", msg.GetRoomChat().GetTalker())
+	assert.Equal(t, "hello", msg.GetRoomChat().GetText())
+	assert.Equal(t, timestamp.UnixMilli(), msg.GetRoomChat().GetTimestamp())
+
+	data, _ = json.Marshal(event.Event{
+		Type:      event.PlayerArrived,
+		Timestamp: timestamp,
+		Data:      event.MovementData{Player: "Bob", Direction: "south"},
+	})
+
+	msg, err = newServerMessage(game.MessageEvent, data)
+	assert.Nil(t, err)
+	assert.Equal(t, api.PlayerMovement_ARRIVED, msg.GetMovement().GetKind())
+	assert.Equal(t, "Bob", msg.GetMovement().GetPlayer())
+	assert.Equal(t, "south", msg.GetMovement().GetDirection())
+
+	data, _ = json.Marshal(event.Event{
+		Type:      event.RoomEmote,
+		Timestamp: timestamp,
+		Data:      event.EmoteData{Actor: "Alice", Text: "Alice waves."},
+	})
+
+	msg, err = newServerMessage(game.MessageEvent, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "Alice", msg.GetEmote().GetActor())
+	assert.Equal(t, "Alice waves.", msg.GetEmote().GetText())
+
+	_, err = newServerMessage(game.MessageEvent, []byte(`{"type":"Unknown","data":1}`))
+	assert.NotNil(t, err)
+}
+
+func TestOutOfBandMessages(t *testing.T) {
+	data, _ := json.Marshal(game.RoomInfoData{
+		ID:      1,
+		Name:    "Central Hub",
+		Exits:   map[string]int{"north": 2, "east": 3},
+		Items:   []game.InventoryItemData{{ID: "sword", Name: "Sword"}},
+		Players: []string{"Bob"},
+		Npcs:    []string{"Henry"},
+	})
+
+	msg, err := newOutOfBandMessage(game.OOBRoomInfo, data)
+	assert.Nil(t, err)
+
+	room := msg.GetRoom()
+	assert.Equal(t, int32(1), room.GetId())
+	assert.Equal(t, "Central Hub", room.GetName())
+	assert.Len(t, room.GetExits(), 2)
+	assert.Equal(t, "east", room.GetExits()[0].GetName())
+	assert.Equal(t, int32(3), room.GetExits()[0].GetRoomId())
+	assert.Equal(t, "sword", room.GetItems()[0].GetId())
+	assert.Equal(t, []string{"Bob"}, room.GetPlayers())
+	assert.Equal(t, []string{"Henry"}, room.GetNpcs())
+
+	msg, err = newOutOfBandMessage(game.OOBCharItemsInv, []byte(`{"items":[{"id":"sword","name":"Sword"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Sword", msg.GetInventory().GetItems()[0].GetName())
+
+	msg, err = newOutOfBandMessage(game.OOBCharVitals, []byte(`{"hp":90,"maxhp":100,"gold":5}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(90), msg.GetVitals().GetHealth())
+	assert.Equal(t, int32(5), msg.GetVitals().GetGold())
+
+	msg, err = newOutOfBandMessage(game.OOBCommTyping, []byte(`{"talker":"Bob"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "Bob", msg.GetTyping().GetTalker())
+
+	msg, err = newOutOfBandMessage(game.OOBRoomPlayers, []byte(`{"num":2,"players":["Bob"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), msg.GetPresence().GetRoomId())
+	assert.Equal(t, []string{"Bob"}, msg.GetPresence().GetPlayers())
+
+	// Packages without a dedicated message pass through untouched
+	msg, err = newOutOfBandMessage(game.OOBCommChannel, []byte(`{"channel":"say"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, game.OOBCommChannel, msg.GetOob().GetPackage())
+	assert.JSONEq(t, `{"channel":"say"}`, string(msg.GetOob().GetData()))
+}
+
+func TestClientMessageEnvelope(t *testing.T) {
+	env := clientMessageEnvelope(&api.ClientMessage{
+		Payload: &api.ClientMessage_Command{Command: &api.CommandRequest{Text: "look"}},
+	})
+	assert.Equal(t, protocol.TypeCommand, env.Type)
+	assert.Equal(t, "look", env.Text)
+
+	env = clientMessageEnvelope(&api.ClientMessage{
+		Payload: &api.ClientMessage_Oob{Oob: &api.OutOfBand{Package: "Core.Supports.Set", Data: []byte(`["Room 1"]`)}},
+	})
+	assert.Equal(t, protocol.TypeOOB, env.Type)
+	assert.Equal(t, "Core.Supports.Set", env.Package)
+	assert.JSONEq(t, `["Room 1"]`, string(env.Data))
+
+	env = clientMessageEnvelope(&api.ClientMessage{Payload: &api.ClientMessage_Typing{Typing: &api.Typing{}}})
+	assert.Equal(t, protocol.TypeTyping, env.Type)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/protocol"
+)
+
+const (
+	SessionUUIDParam = "uuid"   // Query parameter holding the pending session uuid from login
+	ResumeParam      = "resume" // Query parameter holding a resume token
+)
+
+// errPlayerQuit is returned when the player asked to leave the game.
+var errPlayerQuit = errors.New("player quit")
+
+// isQuitCommand checks if the input asks to leave the game.
+func isQuitCommand(input string) bool {
+	input = strings.TrimSpace(input)
+	return input == "quit" || input == "exit"
+}
+
+// connectPlayer attaches the connection to a pending session, or to an existing
+// session when a resume token is given. The player is sent their resume token,
+// a greeting and a prompt.
+func connectPlayer(sm *game.SessionManager, g *game.Game, sessionUUID string, resumeToken string, conn game.Connection) (*game.Player, error) {
+	var player *game.Player
+	var err error
+
+	if resumeToken != "" {
+		if player, err = sm.Resume(resumeToken, conn); err != nil {
+			return nil, err
+		}
+	} else {
+		if player, err = sm.Connect(sessionUUID, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = player.WriteResumeToken(); err != nil {
+		return player, err
+	}
+
+	if resumeToken != "" {
+		g.ResumePlayer(player)
+	} else {
+		g.GreetPlayer(player)
+	}
+
+	return player, player.WritePrompt(protocol.CommandPrompt)
+}
+
+// disconnectPlayer removes the player when they quit, otherwise their session
+// is kept so they can resume it.
+func disconnectPlayer(sm *game.SessionManager, player *game.Player, conn game.Connection, err error) {
+	if errors.Is(err, errPlayerQuit) {
+		sm.Leave(player.GetUUID())
+		return
+	}
+
+	sm.Detach(player.GetUUID(), conn)
+}
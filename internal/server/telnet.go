@@ -0,0 +1,364 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/command"
+	"github.com/xealgo/muddy/internal/config"
+	"github.com/xealgo/muddy/internal/event"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/gmcp"
+	"github.com/xealgo/muddy/internal/protocol"
+	"github.com/xealgo/muddy/internal/services"
+	"github.com/xealgo/muddy/internal/telnet"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Telnet represents a telnet server for classic MUD clients.
+type Telnet struct {
+	addr         string
+	cfg          *config.Config
+	sm           *game.SessionManager
+	game         *game.Game
+	loginService *services.LoginService
+	cmdRunner    *command.Runner
+}
+
+// NewTelnet creates a new Telnet instance
+func NewTelnet(cfg *config.Config, sm *game.SessionManager, game *game.Game, login *services.LoginService) *Telnet {
+	return &Telnet{
+		addr:         fmt.Sprintf(":%d", cfg.TelnetPort),
+		cfg:          cfg,
+		sm:           sm,
+		game:         game,
+		loginService: login,
+		cmdRunner:    command.NewRunner(game),
+	}
+}
+
+// StartServer starts the telnet server and listens for incoming connections.
+func (ts *Telnet) StartServer(ctx context.Context, wg *sync.WaitGroup) error {
+	defer wg.Done()
+
+	slog.Info("Starting telnet server", "address", ts.addr)
+
+	lis, err := net.Listen("tcp", ts.addr)
+	if err != nil {
+		return err
+	}
+
+	// Handle errors created in the go routine
+	errChan := make(chan error, 1)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			go ts.handleConn(ctx, telnet.NewConn(conn))
+		}
+	}()
+
+	select {
+	case err := <-errChan:
+		return fmt.Errorf("telnet server failed: %w", err)
+	case <-ctx.Done():
+		slog.Info("Shutting down telnet server")
+		lis.Close()
+	}
+
+	return nil
+}
+
+// handleConn logs the player in and processes their commands until they disconnect.
+func (ts *Telnet) handleConn(ctx context.Context, tc *telnet.Conn) {
+	conn := newTelnetConnection(ctx, tc)
+	defer conn.Close("connection closed")
+
+	go func() {
+		<-conn.Context().Done()
+		tc.Close()
+	}()
+
+	var player *game.Player
+
+	// GMCP messages are delivered from within ReadLine, so this never runs
+	// concurrently with the command loop below.
+	tc.GMCPHandler = func(message string) {
+		pkg, data := gmcp.Parse(message)
+		handleOutOfBand(ts.game, conn.subscriptions, player, pkg, data)
+	}
+
+	if err := tc.Negotiate(); err != nil {
+		slog.Error("Failed to negotiate telnet options", "error", err)
+		return
+	}
+
+	player, err := ts.login(conn)
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			slog.Error("Telnet login failed", "remote", conn.RemoteAddr(), "error", err)
+		}
+		return
+	}
+
+	ts.game.GreetPlayer(player)
+	player.WritePrompt(protocol.CommandPrompt)
+
+	// Telnet clients have no way to resume, so the player leaves right away
+	defer ts.sm.Leave(player.GetUUID())
+
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("Failed to read from telnet connection", "error", err)
+			}
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if isQuitCommand(line) {
+			player.WriteString("Until next time!\n")
+			player.Flush()
+			return
+		}
+
+		response, err := ts.cmdRunner.Execute(player, line)
+		if err != nil {
+			err = player.WriteError(err.Error())
+		} else if response != "" {
+			err = player.WriteString(response)
+		}
+
+		if err == nil {
+			err = player.WritePrompt(protocol.CommandPrompt)
+		}
+
+		if err != nil {
+			slog.Error("Failed to write to telnet connection", "error", err)
+			return
+		}
+	}
+}
+
+// login prompts the client for a username and password and connects the player
+// session.
+func (ts *Telnet) login(conn *telnetConnection) (*game.Player, error) {
+	tc := conn.tc
+
+	tc.Write([]byte(telnet.Colorize("Welcome to Muddy!", telnet.AnsiGreen) + "\n"))
+
+	for {
+		tc.Write([]byte("Please enter a username: "))
+
+		username, err := tc.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// The client stops echoing while the password is typed, including the
+		// newline ending it
+		tc.Write([]byte("Password: "))
+		tc.SetEchoHidden(true)
+
+		password, err := tc.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		tc.SetEchoHidden(false)
+		tc.Write([]byte("\n"))
+
+		req := &api.LoginRequest{Username: strings.TrimSpace(username), Password: password}
+
+		resp, err := ts.loginService.Login(services.WithRemoteAddr(conn.Context(), conn.RemoteAddr()), req)
+		if err != nil {
+			st := status.Convert(err)
+			tc.Write([]byte(telnet.Colorize(st.Message(), telnet.AnsiRed) + "\n"))
+
+			switch st.Code() {
+			case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.AlreadyExists:
+				continue
+			}
+
+			return nil, err
+		}
+
+		if resp.QueuePosition > 0 {
+			if err = ts.waitInQueue(conn, resp.SessionUuid); err != nil {
+				return nil, err
+			}
+		}
+
+		return ts.sm.Connect(resp.SessionUuid, conn)
+	}
+}
+
+// waitInQueue shows the player their place in the login queue until they can
+// connect. Anything typed while waiting is ignored, and the player gives up
+// their place if they disconnect.
+func (ts *Telnet) waitInQueue(conn *telnetConnection, uuid string) error {
+	tc := conn.tc
+	shown := 0
+
+	defer tc.SetReadDeadline(time.Time{})
+
+	for {
+		// Grab the channel before reading the position so no change is missed
+		changed := ts.sm.QueueChanged()
+
+		position, ok := ts.sm.QueuePosition(uuid)
+		if !ok {
+			return fmt.Errorf("lost place in the login queue")
+		}
+
+		if position == 0 {
+			return nil
+		}
+
+		if position != shown {
+			tc.Write([]byte(fmt.Sprintf("The server is full, you are number %d in the queue...\n", position)))
+			shown = position
+		}
+
+		// Reading notices the client disconnecting. The read is cut short when
+		// the queue changes, or after the heartbeat so the place isn't lost.
+		tc.SetReadDeadline(time.Now().Add(services.QueueHeartbeat))
+
+		wake, woken := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(woken)
+
+			select {
+			case <-changed:
+				tc.SetReadDeadline(time.Now())
+			case <-wake:
+			}
+		}()
+
+		_, err := tc.ReadLine()
+
+		close(wake)
+		<-woken
+
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			ts.sm.LeaveQueue(uuid)
+			return err
+		}
+	}
+}
+
+// telnetConnection adapts a telnet connection to the game.Connection interface
+// and translates events into readable colored text.
+type telnetConnection struct {
+	tc            *telnet.Conn
+	subscriptions *gmcp.Subscriptions
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+// newTelnetConnection creates a new telnetConnection instance.
+func newTelnetConnection(ctx context.Context, tc *telnet.Conn) *telnetConnection {
+	connCtx, cancel := context.WithCancel(ctx)
+
+	return &telnetConnection{
+		tc:            tc,
+		subscriptions: gmcp.NewSubscriptions(),
+		ctx:           connCtx,
+		cancel:        cancel,
+	}
+}
+
+// WriteMessage writes a message to the client, rendering events and errors as
+// colored text.
+func (c *telnetConnection) WriteMessage(typ game.MessageType, message []byte) error {
+	text := string(message)
+
+	switch typ {
+	case game.MessageSession:
+		return nil
+	case game.MessagePrompt:
+		_, err := c.tc.Write(message)
+		return err
+	case game.MessageEvent:
+		if text = renderEvent(message); text == "" {
+			return nil
+		}
+	case game.MessageError:
+		text = telnet.Colorize(strings.TrimRight(text, "\n"), telnet.AnsiRed)
+	}
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	_, err := c.tc.Write([]byte(text))
+	return err
+}
+
+// WriteOutOfBand sends a GMCP package to the client.
+func (c *telnetConnection) WriteOutOfBand(pkg string, data []byte) error {
+	return c.tc.WriteGMCP(gmcp.Encode(pkg, data))
+}
+
+// IsSubscribed checks if the client negotiated GMCP and subscribed to the package.
+func (c *telnetConnection) IsSubscribed(pkg string) bool {
+	return c.tc.GMCPEnabled() && c.subscriptions.IsSubscribed(pkg)
+}
+
+// Close closes the telnet connection.
+func (c *telnetConnection) Close(reason string) error {
+	c.cancel()
+	return c.tc.Close()
+}
+
+// RemoteAddr returns the address of the remote client.
+func (c *telnetConnection) RemoteAddr() string {
+	return c.tc.RemoteAddr().String()
+}
+
+// Context returns the connection context.
+func (c *telnetConnection) Context() context.Context {
+	return c.ctx
+}
+
+// renderEvent converts a JSON encoded event into colored text. Events without
+// any text for players, such as those of unknown types, render as nothing.
+func renderEvent(data []byte) string {
+	e, err := event.Unmarshal(data)
+	if err != nil {
+		slog.Error("failed to unmarshal event", "error", err)
+		return ""
+	}
+
+	text := e.Text()
+	if text == "" {
+		return ""
+	}
+
+	switch e.Data.(type) {
+	case event.RoomChatData:
+		return telnet.Colorize(text, telnet.AnsiYellow)
+	case event.EmoteData:
+		return telnet.Colorize(text, telnet.AnsiGreen)
+	case event.MovementData:
+		return telnet.Colorize(text, telnet.AnsiCyan)
+	default:
+		return telnet.Colorize(text, telnet.AnsiBlue)
+	}
+}
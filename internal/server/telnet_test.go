@@ -0,0 +1,24 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xealgo/muddy/internal/event"
+	"github.com/xealgo/muddy/internal/telnet"
+)
+
+func TestRenderEvent(t *testing.T) {
+	data, _ := json.Marshal(event.Event{Type: event.RoomChat, Data: event.RoomChatData{Talker: "Alice", Text: "hello"}})
+	assert.Equal(t, telnet.Colorize("Alice: hello", telnet.AnsiYellow), renderEvent(data))
+
+	data, _ = json.Marshal(event.Event{Type: event.PlayerJoined, Data: event.MovementData{Player: "Bob"}})
+	assert.Equal(t, telnet.Colorize("Bob has joined the game.", telnet.AnsiCyan), renderEvent(data))
+
+	// Events without text aren't shown as raw data
+	data, _ = json.Marshal(event.Event{Type: "Unknown", Data: map[string]any{"secret": 1}})
+	assert.Empty(t, renderEvent(data))
+
+	assert.Empty(t, renderEvent([]byte("not json")))
+}
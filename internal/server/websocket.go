@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/command"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/gmcp"
+	"github.com/xealgo/muddy/internal/protocol"
+	"golang.org/x/net/websocket"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	WebSocketPath = "/ws"
+)
+
+// webSocketHandler serves game sessions over WebSockets for browsers without
+// WebTransport support, or networks where UDP is blocked.
+type webSocketHandler struct {
+	sm        *game.SessionManager
+	game      *game.Game
+	cmdRunner *command.Runner
+}
+
+// WithWebSocketHandler adds a WebSocket fallback for the game stream. Clients use
+// the same ?uuid= handshake as the WebTransport endpoint.
+func WithWebSocketHandler(sm *game.SessionManager, g *game.Game) HttpRouteHandler {
+	return func(server *HttpServer) error {
+		if sm == nil || g == nil {
+			return fmt.Errorf("session manager and game are required for the websocket handler")
+		}
+
+		handler := &webSocketHandler{
+			sm:        sm,
+			game:      g,
+			cmdRunner: command.NewRunner(g),
+		}
+
+		http.Handle(WebSocketPath, websocket.Server{
+			Handshake: func(cfg *websocket.Config, r *http.Request) error {
+				// TODO: Proper origin validation
+				return nil
+			},
+			Handler: handler.handleConn,
+		})
+
+		return nil
+	}
+}
+
+// handleConn connects the pending player session, or resumes a dropped session,
+// and processes commands until the socket closes. Each WebSocket message carries
+// a single envelope.
+func (h *webSocketHandler) handleConn(ws *websocket.Conn) {
+	conn := newWebSocketConnection(ws)
+	defer conn.Close("connection closed")
+
+	sessionUUID := ws.Request().URL.Query().Get(SessionUUIDParam)
+	resumeToken := ws.Request().URL.Query().Get(ResumeParam)
+
+	if len(sessionUUID) == 0 && len(resumeToken) == 0 {
+		slog.Error("Player session uuid or resume token required")
+		return
+	}
+
+	if len(sessionUUID) > 0 {
+		if _, ok := h.sm.GetSession(sessionUUID); ok {
+			slog.Info("Player session already connected", "uuid", sessionUUID)
+			return
+		}
+	}
+
+	// Ephemeral updates fall back to regular messages since there are no datagrams
+	hello, err := negotiateVersion(conn.readEnvelope, conn.writeEnvelope, false)
+	if err != nil {
+		slog.Warn("Protocol negotiation failed", "uuid", sessionUUID, "error", err)
+		conn.writeEnvelope(protocol.Envelope{Type: protocol.TypeError, Text: err.Error()})
+		return
+	}
+
+	conn.version = hello.Version
+
+	player, err := connectPlayer(h.sm, h.game, sessionUUID, resumeToken, conn)
+	if err != nil {
+		slog.Error("Failed to connect player session", "uuid", sessionUUID, "error", err)
+		conn.WriteMessage(game.MessageError, []byte("Error creating player session. Disconnecting..."))
+		if player != nil {
+			h.sm.Detach(player.GetUUID(), conn)
+		}
+		return
+	}
+
+	for {
+		env, err := conn.readEnvelope()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				slog.Error("Failed to read from websocket", "error", err)
+			}
+
+			disconnectPlayer(h.sm, player, conn, err)
+			return
+		}
+
+		if err = handleEnvelope(h.game, h.cmdRunner, conn.subscriptions, player, env); err != nil {
+			if !errors.Is(err, errPlayerQuit) {
+				slog.Error("Failed to write to websocket", "error", err)
+			}
+
+			disconnectPlayer(h.sm, player, conn, err)
+			return
+		}
+	}
+}
+
+// webSocketConnection adapts a WebSocket to the game.Connection interface.
+// Each envelope is sent as a single text frame, or each protobuf message as a
+// single binary frame once version 2 has been negotiated.
+type webSocketConnection struct {
+	ws            *websocket.Conn
+	version       int
+	subscriptions *gmcp.Subscriptions
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+// newWebSocketConnection creates a new webSocketConnection instance.
+func newWebSocketConnection(ws *websocket.Conn) *webSocketConnection {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+
+	return &webSocketConnection{
+		ws:            ws,
+		subscriptions: gmcp.NewSubscriptions(),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// WriteMessage writes a message to the socket.
+func (c *webSocketConnection) WriteMessage(typ game.MessageType, message []byte) error {
+	if c.version == protocol.Version2 {
+		msg, err := newServerMessage(typ, message)
+		if err != nil {
+			slog.Warn("Unable to convert message", "type", typ, "error", err)
+			return nil
+		}
+
+		return c.writeMessage(msg)
+	}
+
+	return c.writeEnvelope(newEnvelope(typ, message))
+}
+
+// WriteOutOfBand writes a GMCP package to the socket.
+func (c *webSocketConnection) WriteOutOfBand(pkg string, data []byte) error {
+	if c.version == protocol.Version2 {
+		msg, err := newOutOfBandMessage(pkg, data)
+		if err != nil {
+			slog.Warn("Unable to convert out-of-band package", "package", pkg, "error", err)
+			return nil
+		}
+
+		return c.writeMessage(msg)
+	}
+
+	return c.writeEnvelope(newOutOfBandEnvelope(pkg, data))
+}
+
+// IsSubscribed checks if the client subscribed to the package.
+func (c *webSocketConnection) IsSubscribed(pkg string) bool {
+	return c.subscriptions.IsSubscribed(pkg)
+}
+
+// Close closes the socket.
+func (c *webSocketConnection) Close(reason string) error {
+	c.cancel()
+	return c.ws.Close()
+}
+
+// RemoteAddr returns the address of the remote client.
+func (c *webSocketConnection) RemoteAddr() string {
+	return c.ws.Request().RemoteAddr
+}
+
+// Context returns a context which is cancelled once the socket closes.
+func (c *webSocketConnection) Context() context.Context {
+	return c.ctx
+}
+
+// readEnvelope reads the next client message from the socket.
+func (c *webSocketConnection) readEnvelope() (protocol.Envelope, error) {
+	data := []byte{}
+
+	if err := websocket.Message.Receive(c.ws, &data); err != nil {
+		return protocol.Envelope{}, err
+	}
+
+	if c.version == protocol.Version2 {
+		msg := &api.ClientMessage{}
+
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return protocol.Envelope{}, fmt.Errorf("unable to decode message: %w", err)
+		}
+
+		return clientMessageEnvelope(msg), nil
+	}
+
+	return protocol.Unmarshal(data)
+}
+
+// writeMessage writes a protobuf message as a single binary frame.
+func (c *webSocketConnection) writeMessage(msg *api.ServerMessage) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("unable to encode message: %w", err)
+	}
+
+	return websocket.Message.Send(c.ws, data)
+}
+
+// writeEnvelope writes an envelope as a single text frame.
+func (c *webSocketConnection) writeEnvelope(env protocol.Envelope) error {
+	data, err := protocol.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return websocket.Message.Send(c.ws, string(data))
+}
@@ -10,6 +10,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -21,6 +22,7 @@ import (
 	"github.com/xealgo/muddy/internal/command"
 	"github.com/xealgo/muddy/internal/config"
 	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/protocol"
 )
 
 const (
@@ -129,9 +131,11 @@ func (s *Streaming) handleUpgrade(ctx context.Context, w http.ResponseWriter, r
 		return nil
 	}
 
-	uuid := r.URL.Query().Get("uuid")
-	if len(uuid) == 0 {
-		return fmt.Errorf("player session uuid required")
+	uuid := r.URL.Query().Get(SessionUUIDParam)
+	resumeToken := r.URL.Query().Get(ResumeParam)
+
+	if len(uuid) == 0 && len(resumeToken) == 0 {
+		return fmt.Errorf("player session uuid or resume token required")
 	}
 
 	session, err := s.wt.Upgrade(w, r)
@@ -140,20 +144,16 @@ func (s *Streaming) handleUpgrade(ctx context.Context, w http.ResponseWriter, r
 	}
 
 	// Handle the WebTransport session
-	go s.handleSession(ctx, session, uuid)
+	go s.handleSession(ctx, session, uuid, resumeToken)
 
 	return nil
 }
 
-// handleSession manages a WebTransport session.
-func (s *Streaming) handleSession(ctx context.Context, conn *webtransport.Session, sessionUUID string) {
+// handleSession manages a WebTransport session. The player either connects
+// with the session uuid from login, or resumes a dropped session with a resume token.
+func (s *Streaming) handleSession(ctx context.Context, conn *webtransport.Session, sessionUUID string, resumeToken string) {
 	defer conn.CloseWithError(0, "connect closed")
 
-	if len(sessionUUID) == 0 {
-		slog.Error("Player session is nil")
-		return
-	}
-
 	shutdownContext, cancel := context.WithCancel(conn.Context())
 
 	go func() {
@@ -163,27 +163,20 @@ func (s *Streaming) handleSession(ctx context.Context, conn *webtransport.Sessio
 
 	defer cancel()
 
-	if _, ok := s.sm.GetSession(sessionUUID); ok {
-		slog.Info("Player session already connected", "uuid", sessionUUID)
-		return
+	if len(sessionUUID) > 0 {
+		if _, ok := s.sm.GetSession(sessionUUID); ok {
+			slog.Info("Player session already connected", "uuid", sessionUUID)
+			return
+		}
 	}
 
-	// We'll need another go routine that periodically clears out pending sessions
-	// after a certain time has passed.
-	//
-	// if ok := s.sm.RemovePending(sessionUUID); !ok {
-	// 	slog.Error("Failed to remove pending player session", "uuid", sessionUUID)
-	// }
+	// Connecting removes the pending session, logins that never connect are
+	// expired by the session reaper.
 
 	for {
 		stream, err := conn.AcceptStream(conn.Context())
 		if err != nil {
-			player, exists := s.sm.GetSession(sessionUUID)
-			if exists {
-				fmt.Printf("%s has left the game\n", player.DisplayName)
-				s.sm.RemovePlayerBySession(conn)
-			}
-
+			// The stream processor detaches the player once its stream fails
 			if shutdownContext.Err() != nil {
 				slog.Info("Shutting down session stream handler")
 				return
@@ -193,69 +186,103 @@ func (s *Streaming) handleSession(ctx context.Context, conn *webtransport.Sessio
 			return
 		}
 
-		player, err := s.sm.Connect(sessionUUID, conn, stream)
+		hello, err := negotiateVersion(
+			func() (protocol.Envelope, error) { return protocol.ReadEnvelope(stream, int(s.maxStreamBufferSize)) },
+			func(env protocol.Envelope) error { return protocol.WriteEnvelope(stream, env) },
+			true,
+		)
 		if err != nil {
-			slog.Error("Failed to connect player session", "uuid", sessionUUID)
-			stream.Write([]byte("Error creating player session. Disconnecting..."))
+			slog.Warn("Protocol negotiation failed", "uuid", sessionUUID, "error", err)
+			protocol.WriteEnvelope(stream, protocol.Envelope{Type: protocol.TypeError, Text: err.Error()})
+			stream.Close()
 			return
 		}
 
-		// Eventually broadcast this..
-		fmt.Printf("%s has joined the game\n", player.DisplayName)
-
-		s.game.GreetPlayer(player)
-
-		go func(player *game.Player) {
-			s.processStream(ctx, player)
+		wtConn := newWebTransportConnection(conn, stream, hello)
 
+		player, err := connectPlayer(s.sm, s.game, sessionUUID, resumeToken, wtConn)
+		if err != nil {
+			slog.Error("Failed to connect player session", "uuid", sessionUUID, "error", err)
+			wtConn.WriteMessage(game.MessageError, []byte("Error creating player session. Disconnecting..."))
 			if player != nil {
-				fmt.Printf("%s has left the game\n", player.DisplayName)
-				s.sm.RemovePlayer(player.GetUUID())
+				s.sm.Detach(player.GetUUID(), wtConn)
 			}
-		}(player)
+			return
+		}
+
+		if wtConn.datagrams {
+			go s.processDatagrams(player, wtConn)
+		}
+
+		go func(player *game.Player, wtConn *webTransportConnection) {
+			err := s.processStream(ctx, player, wtConn)
+			disconnectPlayer(s.sm, player, wtConn, err)
+		}(player, wtConn)
 	}
 }
 
-// processStream handles an individual WebTransport stream.
-func (s *Streaming) processStream(ctx context.Context, player *game.Player) {
-	stream := player.GetStream()
+// processStream reads framed envelopes from an individual WebTransport stream
+// until it closes, returning the reason.
+func (s *Streaming) processStream(ctx context.Context, player *game.Player, conn *webTransportConnection) error {
+	defer conn.stream.Close()
 
-	defer stream.Close()
-
-	buffer := make([]byte, s.maxStreamBufferSize)
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("Shutting down stream processor due to context cancellation")
-			return
+			return ctx.Err()
 		default:
 		}
 
-		n, err := stream.Read(buffer)
+		env, err := conn.readEnvelope(int(s.maxStreamBufferSize))
 		if err != nil {
 			if ctx.Err() != nil {
 				slog.Info("Shutting down stream processor", "error", ctx.Err())
-				return
+				return ctx.Err()
 			}
 
 			if err != io.EOF {
 				slog.Error("Failed to read from stream", "error", err)
 			}
-			return
+			return err
 		}
 
-		message := string(buffer[:n])
-		response := s.cmdRunner.Execute(player, message)
-
-		if err = player.WriteString(response); err != nil {
-			slog.Error("Failed to write to stream", "error", err)
-			return
+		if err = handleEnvelope(s.game, s.cmdRunner, conn.subscriptions, player, env); err != nil {
+			if !errors.Is(err, errPlayerQuit) {
+				slog.Error("Failed to write to stream", "error", err)
+			}
+			return err
 		}
 
 		if ctx.Err() != nil {
 			slog.Info("Shutting down stream processor due to session closure", "error", ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+// processDatagrams handles ephemeral updates sent by the client as datagrams until
+// the session closes. Commands must use the stream, so only typing indicators
+// are accepted.
+func (s *Streaming) processDatagrams(player *game.Player, conn *webTransportConnection) {
+	for {
+		datagram, err := conn.session.ReceiveDatagram(conn.Context())
+		if err != nil {
 			return
 		}
+
+		env, err := conn.decodeDatagram(datagram)
+		if err != nil {
+			slog.Warn("Invalid datagram", "uuid", player.GetUUID(), "error", err)
+			continue
+		}
+
+		if env.Type != protocol.TypeTyping {
+			slog.Warn("Unexpected datagram from client", "uuid", player.GetUUID(), "type", env.Type)
+			continue
+		}
+
+		s.game.PlayerTyping(player)
 	}
 }
 
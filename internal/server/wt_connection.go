@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/quic-go/webtransport-go"
+	"github.com/xealgo/muddy/api"
+	"github.com/xealgo/muddy/internal/game"
+	"github.com/xealgo/muddy/internal/gmcp"
+	"github.com/xealgo/muddy/internal/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// webTransportConnection adapts a WebTransport session and its bidi stream
+// to the game.Connection interface. Messages are written as framed envelopes, or
+// framed protobuf messages once version 2 has been negotiated. Ephemeral updates
+// are sent as datagrams if the client asked for them.
+type webTransportConnection struct {
+	session       *webtransport.Session
+	stream        *webtransport.Stream
+	version       int
+	datagrams     bool
+	subscriptions *gmcp.Subscriptions
+	mutex         *sync.Mutex
+}
+
+// newWebTransportConnection creates a new webTransportConnection instance.
+func newWebTransportConnection(session *webtransport.Session, stream *webtransport.Stream, hello protocol.Envelope) *webTransportConnection {
+	return &webTransportConnection{
+		session:       session,
+		stream:        stream,
+		version:       hello.Version,
+		datagrams:     hello.Datagrams,
+		subscriptions: gmcp.NewSubscriptions(),
+		mutex:         &sync.Mutex{},
+	}
+}
+
+// WriteMessage writes a message to the stream.
+func (c *webTransportConnection) WriteMessage(typ game.MessageType, message []byte) error {
+	if c.version == protocol.Version2 {
+		msg, err := newServerMessage(typ, message)
+		if err != nil {
+			slog.Warn("Unable to convert message", "type", typ, "error", err)
+			return nil
+		}
+
+		return c.writeMessage(msg)
+	}
+
+	return c.writeEnvelope(newEnvelope(typ, message))
+}
+
+// WriteOutOfBand writes a GMCP package to the stream.
+func (c *webTransportConnection) WriteOutOfBand(pkg string, data []byte) error {
+	if c.version == protocol.Version2 {
+		msg, err := newOutOfBandMessage(pkg, data)
+		if err != nil {
+			slog.Warn("Unable to convert out-of-band package", "package", pkg, "error", err)
+			return nil
+		}
+
+		return c.writeMessage(msg)
+	}
+
+	return c.writeEnvelope(newOutOfBandEnvelope(pkg, data))
+}
+
+// WriteEphemeral sends a package as a datagram, falling back to the stream when
+// the client doesn't accept datagrams or the package doesn't fit in one.
+func (c *webTransportConnection) WriteEphemeral(pkg string, data []byte) error {
+	if !c.datagrams {
+		return c.WriteOutOfBand(pkg, data)
+	}
+
+	var datagram []byte
+	var err error
+
+	if c.version == protocol.Version2 {
+		msg, convertErr := newOutOfBandMessage(pkg, data)
+		if convertErr != nil {
+			slog.Warn("Unable to convert out-of-band package", "package", pkg, "error", convertErr)
+			return nil
+		}
+
+		datagram, err = proto.Marshal(msg)
+	} else {
+		datagram, err = protocol.Marshal(newOutOfBandEnvelope(pkg, data))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = c.session.SendDatagram(datagram); err != nil {
+		slog.Debug("Unable to send datagram, falling back to the stream", "package", pkg, "error", err)
+		return c.WriteOutOfBand(pkg, data)
+	}
+
+	return nil
+}
+
+// IsSubscribed checks if the client subscribed to the package.
+func (c *webTransportConnection) IsSubscribed(pkg string) bool {
+	return c.subscriptions.IsSubscribed(pkg)
+}
+
+// Close closes the stream and the underlying session.
+func (c *webTransportConnection) Close(reason string) error {
+	c.stream.Close()
+	return c.session.CloseWithError(0, reason)
+}
+
+// RemoteAddr returns the address of the remote client.
+func (c *webTransportConnection) RemoteAddr() string {
+	return c.session.RemoteAddr().String()
+}
+
+// Context returns the session context.
+func (c *webTransportConnection) Context() context.Context {
+	return c.session.Context()
+}
+
+// readEnvelope reads the next client message from the stream.
+func (c *webTransportConnection) readEnvelope(maxSize int) (protocol.Envelope, error) {
+	if c.version == protocol.Version2 {
+		msg := &api.ClientMessage{}
+
+		if err := protocol.ReadMessage(c.stream, maxSize, msg); err != nil {
+			return protocol.Envelope{}, err
+		}
+
+		return clientMessageEnvelope(msg), nil
+	}
+
+	return protocol.ReadEnvelope(c.stream, maxSize)
+}
+
+// decodeDatagram decodes a datagram sent by the client.
+func (c *webTransportConnection) decodeDatagram(datagram []byte) (protocol.Envelope, error) {
+	if c.version == protocol.Version2 {
+		msg := &api.ClientMessage{}
+
+		if err := proto.Unmarshal(datagram, msg); err != nil {
+			return protocol.Envelope{}, fmt.Errorf("unable to decode datagram: %w", err)
+		}
+
+		return clientMessageEnvelope(msg), nil
+	}
+
+	return protocol.Unmarshal(datagram)
+}
+
+// writeMessage writes a protobuf message as a single frame.
+func (c *webTransportConnection) writeMessage(msg *api.ServerMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return protocol.WriteMessage(c.stream, msg)
+}
+
+// writeEnvelope writes an envelope as a single frame. Frames written by
+// concurrent broadcasts must not interleave.
+func (c *webTransportConnection) writeEnvelope(env protocol.Envelope) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return protocol.WriteEnvelope(c.stream, env)
+}
@@ -18,23 +18,40 @@ type HealthService struct {
 }
 
 // NewHealthService creates a new HealthService instance.
-func RegisterHealthService(cfg *config.Config, server *grpc.Server, state *game.GameState, sm *game.SessionManager) {
-	hs := &HealthService{
+func NewHealthService(cfg *config.Config, state *game.GameState, sm *game.SessionManager) *HealthService {
+	return &HealthService{
 		cfg:   cfg,
 		state: state,
 		sm:    sm,
 	}
+}
 
-	api.RegisterHealthServiceServer(server, hs)
+// RegisterHealthService registers the HealthService with the gRPC server.
+func RegisterHealthService(server *grpc.Server, service *HealthService) {
+	api.RegisterHealthServiceServer(server, service)
 }
 
 // GetStatus returns the health status of the service.
 func (s *HealthService) GetStatus(ctx context.Context, in *api.StatusRequest) (*api.StatusResponse, error) {
+	stats := s.sm.ReaperStats()
+	output := s.sm.OutputQueueStats()
+
 	return &api.StatusResponse{
 		// Doesn't really make sense to have IsActive if it's just always going to be true,
 		// figure out how to meaningfully define this.
 		IsActive:      true,
 		UptimeSeconds: int32(s.state.Uptime().Seconds()),
 		ActiveUsers:   int32(s.sm.GetActiveSessionCount()),
+		PendingUsers:  int32(s.sm.GetPendingCount()),
+		QueuedUsers:   int32(s.sm.QueueLength()),
+
+		ExpiredPending:  stats.PendingExpired,
+		IdleWarnings:    stats.IdleWarnings,
+		IdleDisconnects: stats.IdleDisconnected,
+
+		OutputQueueDepth:    int32(output.Queued),
+		MaxOutputQueueDepth: int32(output.MaxDepth),
+		DroppedMessages:     output.Dropped,
+		OverflowDisconnects: output.Disconnects,
 	}, nil
 }
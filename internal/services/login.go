@@ -2,40 +2,174 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/xealgo/muddy/api"
 	"github.com/xealgo/muddy/internal/config"
 	"github.com/xealgo/muddy/internal/game"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 12
+
+	QueueHeartbeat = 15 * time.Second // How often waiting players are resent their position
 )
 
 // LoginService implements the login service.
 type LoginService struct {
 	api.LoginServiceServer
-	cfg *config.Config
-	sm  *game.SessionManager
+	cfg      *config.Config
+	sm       *game.SessionManager
+	accounts *game.Accounts
+	throttle *loginThrottle
 }
 
-// LoginService registers the LoginService with the gRPC server.
-func RegisterLoginService(cfg *config.Config, server *grpc.Server, sm *game.SessionManager) {
-	service := &LoginService{
-		cfg: cfg,
-		sm:  sm,
+// NewLoginService creates a new LoginService instance.
+func NewLoginService(cfg *config.Config, sm *game.SessionManager, accounts *game.Accounts) *LoginService {
+	return &LoginService{
+		cfg:      cfg,
+		sm:       sm,
+		accounts: accounts,
+		throttle: newLoginThrottle(LoginAttemptLimit, LoginAttemptWindow),
 	}
+}
 
+// RegisterLoginService registers the LoginService with the gRPC server.
+func RegisterLoginService(server *grpc.Server, service *LoginService) {
 	api.RegisterLoginServiceServer(server, service)
 }
 
 // Login handles user login requests.
 func (s *LoginService) Login(ctx context.Context, req *api.LoginRequest) (*api.LoginResponse, error) {
+	if l := len(req.Username); l < MinUsernameLength || l > MaxUsernameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "username must be between %d and %d characters", MinUsernameLength, MaxUsernameLength)
+	}
+
+	if l := len(req.Password); l < game.MinPasswordLength || l > game.MaxPasswordLength {
+		return nil, status.Errorf(codes.InvalidArgument, "password must be between %d and %d characters", game.MinPasswordLength, game.MaxPasswordLength)
+	}
+
+	// Anyone could otherwise claim a privileged name before its player does, so
+	// their accounts are made with muddy passwd
+	role := s.role(req.Username)
+	if role != game.RolePlayer && !s.accounts.Exists(req.Username) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is reserved", req.Username)
+	}
+
+	// Guessing passwords and making accounts are limited per address
+	host := remoteHost(ctx)
+	if !s.throttle.take(host) {
+		return nil, status.Error(codes.ResourceExhausted, "too many login attempts, please try again later")
+	}
+
+	created, err := s.accounts.Authenticate(req.Username, req.Password)
+	if errors.Is(err, game.ErrorWrongPassword) {
+		slog.Warn("Wrong password", "player", req.Username, "remote", host)
+		return nil, status.Error(codes.Unauthenticated, "wrong username or password")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		slog.Info("Player account created", "player", req.Username, "remote", host)
+	} else {
+		s.throttle.release(host)
+	}
+
 	player := game.NewPlayer(req.Username, req.Username)
+	player.Role = role
 
-	err := s.sm.Register(player)
+	position, err := s.sm.Join(player)
 	if err != nil {
+		smErr := &game.SessionManagerError{}
+		if errors.As(err, &smErr) && (smErr.Type == game.ErrorMaxPlayers || smErr.Type == game.ErrorQueueFull) {
+			return nil, status.Error(codes.ResourceExhausted, smErr.Message)
+		}
+
+		if errors.As(err, &smErr) && smErr.Type == game.ErrorUsernameTaken {
+			return nil, status.Error(codes.AlreadyExists, smErr.Message)
+		}
+
 		return nil, err
 	}
 
 	return &api.LoginResponse{
-		SessionUuid: player.GetUUID(),
+		SessionUuid:   player.GetUUID(),
+		QueuePosition: int32(position),
+	}, nil
+}
+
+// role returns the role configured for the username.
+func (s *LoginService) role(username string) game.Role {
+	switch {
+	case s.cfg.IsAdmin(username):
+		return game.RoleAdmin
+	case s.cfg.IsBuilder(username):
+		return game.RoleBuilder
+	case s.cfg.IsModerator(username):
+		return game.RoleModerator
+	default:
+		return game.RolePlayer
+	}
+}
+
+// GetQueuePosition returns the player's place in the login queue. Waiting players
+// need to keep asking, or they lose their place after the pending TTL.
+func (s *LoginService) GetQueuePosition(ctx context.Context, req *api.QueueRequest) (*api.QueueStatus, error) {
+	return s.queueStatus(req.SessionUuid)
+}
+
+// WaitInQueue streams the player's place in the login queue whenever it changes,
+// until they can connect. Closing the stream gives up the place.
+func (s *LoginService) WaitInQueue(req *api.QueueRequest, stream grpc.ServerStreamingServer[api.QueueStatus]) error {
+	heartbeat := time.NewTicker(QueueHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		// Grab the channel before reading the position so no change is missed
+		changed := s.sm.QueueChanged()
+
+		queueStatus, err := s.queueStatus(req.SessionUuid)
+		if err != nil {
+			return err
+		}
+
+		if err = stream.Send(queueStatus); err != nil {
+			s.sm.LeaveQueue(req.SessionUuid)
+			return err
+		}
+
+		if queueStatus.Position == 0 {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			s.sm.LeaveQueue(req.SessionUuid)
+			return stream.Context().Err()
+		case <-changed:
+		case <-heartbeat.C:
+		}
+	}
+}
+
+// queueStatus looks up the player's place in the login queue.
+func (s *LoginService) queueStatus(uuid string) (*api.QueueStatus, error) {
+	position, ok := s.sm.QueuePosition(uuid)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no login found, please log in again")
+	}
+
+	return &api.QueueStatus{
+		Position:    int32(position),
+		QueueLength: int32(s.sm.QueueLength()),
 	}, nil
 }
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	LoginAttemptLimit  = 5           // Failed logins and new accounts allowed per address per window
+	LoginAttemptWindow = time.Minute // Window login attempts are counted over
+)
+
+// remoteAddrKey holds the client's address on a login's context.
+type remoteAddrKey struct{}
+
+// WithRemoteAddr records the client's address on the context, for logins which
+// don't come in over gRPC.
+func WithRemoteAddr(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, remoteAddrKey{}, addr)
+}
+
+// remoteHost returns the host of the client logging in, without its port.
+func remoteHost(ctx context.Context) string {
+	addr, _ := ctx.Value(remoteAddrKey{}).(string)
+
+	if p, ok := peer.FromContext(ctx); ok && addr == "" {
+		addr = p.Addr.String()
+	}
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
+
+// loginThrottle limits how often one address can fail to log in or make a new
+// account, since each costs a password hash and new accounts are saved.
+// Logins to existing accounts with the right password don't count.
+type loginThrottle struct {
+	limit     int
+	window    time.Duration
+	attempts  map[string][]time.Time // Host -> when its recent attempts were made
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     *sync.Mutex
+}
+
+// newLoginThrottle creates a new loginThrottle instance.
+func newLoginThrottle(limit int, window time.Duration) *loginThrottle {
+	return &loginThrottle{
+		limit:    limit,
+		window:   window,
+		attempts: make(map[string][]time.Time),
+		now:      time.Now,
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// take counts an attempt from the host, returning false if it made too many
+// recently. Attempts which turn out not to count are given back with release.
+func (t *loginThrottle) take(host string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+
+	// Hosts which stopped trying are forgotten
+	if now.Sub(t.lastSweep) >= t.window {
+		for h, attempts := range t.attempts {
+			if now.Sub(attempts[len(attempts)-1]) >= t.window {
+				delete(t.attempts, h)
+			}
+		}
+
+		t.lastSweep = now
+	}
+
+	attempts := t.attempts[host]
+	for len(attempts) > 0 && now.Sub(attempts[0]) >= t.window {
+		attempts = attempts[1:]
+	}
+
+	if len(attempts) >= t.limit {
+		t.attempts[host] = attempts
+		return false
+	}
+
+	t.attempts[host] = append(attempts, now)
+	return true
+}
+
+// release gives back an attempt taken for the host.
+func (t *loginThrottle) release(host string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	attempts := t.attempts[host]
+	if len(attempts) == 0 {
+		return
+	}
+
+	if len(attempts) == 1 {
+		delete(t.attempts, host)
+		return
+	}
+
+	t.attempts[host] = attempts[:len(attempts)-1]
+}
@@ -0,0 +1,304 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+)
+
+const (
+	MaxLineLength = 1024 // Maximum number of bytes accepted for a single line
+)
+
+// Conn wraps a net.Conn and handles telnet option negotiation. Commands sent
+// by the client are stripped from the input so ReadLine only returns text.
+type Conn struct {
+	net.Conn
+
+	// GMCPHandler is called with each GMCP message received from the client.
+	GMCPHandler func(message string)
+
+	reader       *bufio.Reader
+	writeMutex   *sync.Mutex
+	stateMutex   *sync.RWMutex
+	width        int
+	height       int
+	terminalType string
+	echoHidden   bool
+	gmcpEnabled  bool
+}
+
+// NewConn creates a new telnet Conn instance.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		Conn:       conn,
+		reader:     bufio.NewReader(conn),
+		writeMutex: &sync.Mutex{},
+		stateMutex: &sync.RWMutex{},
+	}
+}
+
+// Negotiate sends the initial option negotiation to the client. Replies are
+// processed as they arrive while reading lines.
+func (c *Conn) Negotiate() error {
+	return c.writeRaw([]byte{
+		IAC, WILL, OptSGA,
+		IAC, DO, OptNAWS,
+		IAC, DO, OptTType,
+		IAC, WILL, OptGMCP,
+	})
+}
+
+// SetEchoHidden asks the client to stop (or resume) echoing typed input locally,
+// which is used to hide sensitive input such as passwords.
+func (c *Conn) SetEchoHidden(hidden bool) error {
+	c.stateMutex.Lock()
+	c.echoHidden = hidden
+	c.stateMutex.Unlock()
+
+	if hidden {
+		return c.writeRaw([]byte{IAC, WILL, OptEcho})
+	}
+
+	return c.writeRaw([]byte{IAC, WONT, OptEcho})
+}
+
+// WindowSize returns the client window size reported through NAWS.
+func (c *Conn) WindowSize() (int, int) {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+
+	return c.width, c.height
+}
+
+// TerminalType returns the client terminal type reported through TTYPE.
+func (c *Conn) TerminalType() string {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+
+	return c.terminalType
+}
+
+// GMCPEnabled checks if the client agreed to receive GMCP messages.
+func (c *Conn) GMCPEnabled() bool {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+
+	return c.gmcpEnabled
+}
+
+// WriteGMCP sends a GMCP message to the client.
+func (c *Conn) WriteGMCP(message []byte) error {
+	if !c.GMCPEnabled() {
+		return fmt.Errorf("GMCP is not enabled for this connection")
+	}
+
+	escaped := bytes.ReplaceAll(message, []byte{IAC}, []byte{IAC, IAC})
+
+	frame := make([]byte, 0, len(escaped)+5)
+	frame = append(frame, IAC, SB, OptGMCP)
+	frame = append(frame, escaped...)
+	frame = append(frame, IAC, SE)
+
+	return c.writeRaw(frame)
+}
+
+// ReadLine reads the next line of text from the client, handling any telnet
+// commands found along the way.
+func (c *Conn) ReadLine() (string, error) {
+	line := []byte{}
+
+	for {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case IAC:
+			literal, err := c.readCommand()
+			if err != nil {
+				return "", err
+			}
+
+			if literal {
+				line = append(line, IAC)
+			}
+		case '\n':
+			return string(line), nil
+		case '\r', 0:
+			// Line endings are normalized on '\n'
+		case 8, 127:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		default:
+			if len(line) >= MaxLineLength {
+				return "", fmt.Errorf("line exceeds %d bytes", MaxLineLength)
+			}
+
+			line = append(line, b)
+		}
+	}
+}
+
+// Write writes text to the client, escaping IAC bytes and converting
+// line endings to CRLF.
+func (c *Conn) Write(b []byte) (int, error) {
+	escaped := bytes.ReplaceAll(b, []byte{IAC}, []byte{IAC, IAC})
+	escaped = bytes.ReplaceAll(escaped, []byte("\r\n"), []byte("\n"))
+	escaped = bytes.ReplaceAll(escaped, []byte("\n"), []byte("\r\n"))
+
+	if err := c.writeRaw(escaped); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// writeRaw writes bytes to the underlying connection without any translation.
+func (c *Conn) writeRaw(b []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	_, err := c.Conn.Write(b)
+	return err
+}
+
+// readCommand processes a command following an IAC byte. It returns true if
+// the command was an escaped IAC data byte.
+func (c *Conn) readCommand() (bool, error) {
+	cmd, err := c.reader.ReadByte()
+	if err != nil {
+		return false, err
+	}
+
+	switch cmd {
+	case IAC:
+		return true, nil
+	case WILL, WONT, DO, DONT:
+		opt, err := c.reader.ReadByte()
+		if err != nil {
+			return false, err
+		}
+
+		return false, c.handleOption(cmd, opt)
+	case SB:
+		return false, c.readSubnegotiation()
+	}
+
+	return false, nil
+}
+
+// handleOption responds to an option negotiation request from the client.
+func (c *Conn) handleOption(cmd byte, opt byte) error {
+	switch cmd {
+	case WILL:
+		switch opt {
+		case OptTType:
+			return c.writeRaw([]byte{IAC, SB, OptTType, TTypeSend, IAC, SE})
+		case OptNAWS:
+			return nil
+		}
+
+		return c.writeRaw([]byte{IAC, DONT, opt})
+	case DO:
+		switch opt {
+		case OptSGA:
+			return nil
+		case OptGMCP:
+			c.stateMutex.Lock()
+			c.gmcpEnabled = true
+			c.stateMutex.Unlock()
+			return nil
+		case OptEcho:
+			c.stateMutex.RLock()
+			hidden := c.echoHidden
+			c.stateMutex.RUnlock()
+
+			if hidden {
+				return nil
+			}
+		}
+
+		return c.writeRaw([]byte{IAC, WONT, opt})
+	case DONT:
+		if opt == OptGMCP {
+			c.stateMutex.Lock()
+			c.gmcpEnabled = false
+			c.stateMutex.Unlock()
+		}
+	}
+
+	// WONT and DONT don't require a reply when the option is already disabled.
+	return nil
+}
+
+// readSubnegotiation reads the subnegotiation data up to IAC SE.
+func (c *Conn) readSubnegotiation() error {
+	opt, err := c.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	data := []byte{}
+
+	for {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if b == IAC {
+			next, err := c.reader.ReadByte()
+			if err != nil {
+				return err
+			}
+
+			if next == SE {
+				break
+			}
+
+			if next != IAC {
+				continue
+			}
+		}
+
+		if len(data) >= MaxLineLength {
+			return fmt.Errorf("subnegotiation exceeds %d bytes", MaxLineLength)
+		}
+
+		data = append(data, b)
+	}
+
+	c.handleSubnegotiation(opt, data)
+
+	return nil
+}
+
+// handleSubnegotiation stores the data reported by the client.
+func (c *Conn) handleSubnegotiation(opt byte, data []byte) {
+	if opt == OptGMCP {
+		if c.GMCPHandler != nil {
+			c.GMCPHandler(string(data))
+		}
+		return
+	}
+
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	switch opt {
+	case OptNAWS:
+		if len(data) == 4 {
+			c.width = int(data[0])<<8 | int(data[1])
+			c.height = int(data[2])<<8 | int(data[3])
+		}
+	case OptTType:
+		if len(data) > 1 && data[0] == TTypeIs {
+			c.terminalType = string(data[1:])
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package telnet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLineStripsNegotiation(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	conn := NewConn(server)
+
+	replies := make(chan []byte, 1)
+
+	go func() {
+		client.Write([]byte{IAC, WILL, OptNAWS})
+		client.Write([]byte{IAC, SB, OptNAWS, 0, 120, 0, 40, IAC, SE})
+		client.Write([]byte{IAC, WILL, OptTType})
+
+		// Server requests the terminal type
+		buf := make([]byte, 6)
+		n, _ := client.Read(buf)
+		replies <- buf[:n]
+
+		client.Write([]byte{IAC, SB, OptTType, TTypeIs, 'x', 't', 'e', 'r', 'm', IAC, SE})
+		client.Write([]byte("look\r\n"))
+	}()
+
+	line, err := conn.ReadLine()
+	assert.Nil(t, err)
+	assert.Equal(t, "look", line)
+	assert.Equal(t, []byte{IAC, SB, OptTType, TTypeSend, IAC, SE}, <-replies)
+
+	width, height := conn.WindowSize()
+	assert.Equal(t, 120, width)
+	assert.Equal(t, 40, height)
+	assert.Equal(t, "xterm", conn.TerminalType())
+}
+
+func TestWriteTranslatesLineEndings(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	conn := NewConn(server)
+
+	go conn.Write([]byte("hello\nworld\r\n\xff"))
+
+	buf := make([]byte, 32)
+	n, err := client.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello\r\nworld\r\n\xff\xff"), buf[:n])
+}
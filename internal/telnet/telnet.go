@@ -0,0 +1,44 @@
+package telnet
+
+// Telnet commands (RFC 854)
+const (
+	SE   byte = 240 // End of subnegotiation parameters
+	NOP  byte = 241 // No operation
+	GA   byte = 249 // Go ahead
+	SB   byte = 250 // Start of subnegotiation
+	WILL byte = 251 // Sender wants to enable an option
+	WONT byte = 252 // Sender refuses to enable an option
+	DO   byte = 253 // Sender wants the receiver to enable an option
+	DONT byte = 254 // Sender wants the receiver to disable an option
+	IAC  byte = 255 // Interpret as command
+)
+
+// Telnet options
+const (
+	OptEcho  byte = 1   // Echo (RFC 857)
+	OptSGA   byte = 3   // Suppress go ahead (RFC 858)
+	OptTType byte = 24  // Terminal type (RFC 1091)
+	OptNAWS  byte = 31  // Negotiate about window size (RFC 1073)
+	OptGMCP  byte = 201 // Generic MUD communication protocol
+)
+
+// Terminal type subnegotiation commands
+const (
+	TTypeIs   byte = 0
+	TTypeSend byte = 1
+)
+
+// ANSI escape sequences used to render colored text.
+const (
+	AnsiReset  = "\x1b[0m"
+	AnsiRed    = "\x1b[31m"
+	AnsiGreen  = "\x1b[32m"
+	AnsiYellow = "\x1b[33m"
+	AnsiBlue   = "\x1b[34m"
+	AnsiCyan   = "\x1b[36m"
+)
+
+// Colorize wraps the text in the given ANSI color sequence.
+func Colorize(text string, color string) string {
+	return color + text + AnsiReset
+}
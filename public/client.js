@@ -0,0 +1,642 @@
+// Muddy web client.
+//
+// Logs in through the JSON login gateway, then connects to the game stream using
+// WebTransport when available, falling back to the WebSocket endpoint otherwise.
+// Both transports carry JSON envelopes; WebTransport frames each envelope with a
+// 4 byte big-endian length prefix, WebSockets send one envelope per message.
+// Ephemeral updates (typing, presence, vitals ticks) use WebTransport datagrams
+// when available, one envelope per datagram. If the connection drops the client
+// reconnects with the session's resume token.
+(function () {
+    "use strict";
+
+    const MAX_SCROLLBACK = 1000;
+    const MAX_HISTORY = 100;
+    const CONNECT_TIMEOUT_MS = 5000;
+
+    const PROTOCOL_VERSIONS = [1];
+    const FRAME_HEADER_SIZE = 4;
+    const GMCP_MODULES = ["Char 1", "Room 1", "Comm 1"];
+    const TYPING_INTERVAL_MS = 3000;
+    const RECONNECT_ATTEMPTS = 10;
+    const RECONNECT_DELAY_MS = 3000;
+    const QUEUE_POLL_MS = 3000;
+
+    const el = {
+        login: document.getElementById("login"),
+        username: document.getElementById("username"),
+        password: document.getElementById("password"),
+        loginError: document.getElementById("login-error"),
+        game: document.getElementById("game"),
+        output: document.getElementById("output"),
+        chat: document.getElementById("chat"),
+        command: document.getElementById("command"),
+        input: document.getElementById("command-input"),
+        status: document.getElementById("status"),
+        roomName: document.getElementById("room-name"),
+        exits: document.getElementById("exits"),
+        players: document.getElementById("players"),
+        typing: document.getElementById("typing"),
+        gold: document.getElementById("gold"),
+        inventory: document.getElementById("inventory"),
+    };
+
+    const history = [];
+    let historyIndex = 0;
+    let transport = null;
+    let lastTyping = 0;
+    let typingTimer = null;
+    let resumeToken = null;
+    let wtPort = null;
+
+    // ---------------------------------------------------------------------
+    // Rendering
+    // ---------------------------------------------------------------------
+
+    // ansiToFragment converts text containing ANSI SGR sequences into DOM nodes.
+    function ansiToFragment(text) {
+        const fragment = document.createDocumentFragment();
+        const pattern = /\x1b\[([0-9;]*)m/g;
+        let classes = [];
+        let last = 0;
+        let match;
+
+        const append = (chunk) => {
+            if (!chunk) {
+                return;
+            }
+
+            const span = document.createElement("span");
+            span.className = classes.join(" ");
+            span.textContent = chunk;
+            fragment.appendChild(span);
+        };
+
+        while ((match = pattern.exec(text)) !== null) {
+            append(text.slice(last, match.index));
+            last = pattern.lastIndex;
+
+            const codes = match[1] === "" ? [0] : match[1].split(";").map(Number);
+            for (const code of codes) {
+                if (code === 0) {
+                    classes = [];
+                } else if (code === 1) {
+                    classes.push("ansi-bold");
+                } else if (code >= 30 && code <= 37) {
+                    classes = classes.filter((c) => !c.startsWith("ansi-fg-"));
+                    classes.push("ansi-fg-" + code);
+                } else if (code === 39) {
+                    classes = classes.filter((c) => !c.startsWith("ansi-fg-"));
+                }
+            }
+        }
+
+        append(text.slice(last));
+        return fragment;
+    }
+
+    // appendTo adds a line to a pane, trimming the scrollback when it grows too large.
+    function appendTo(pane, text, className) {
+        const atBottom = pane.scrollHeight - pane.scrollTop - pane.clientHeight < 10;
+
+        const line = document.createElement("div");
+        if (className) {
+            line.className = className;
+        }
+
+        line.appendChild(ansiToFragment(text.replace(/\n$/, "")));
+        pane.appendChild(line);
+
+        while (pane.childNodes.length > MAX_SCROLLBACK) {
+            pane.removeChild(pane.firstChild);
+        }
+
+        if (atBottom) {
+            pane.scrollTop = pane.scrollHeight;
+        }
+    }
+
+    function setStatus(text) {
+        el.status.textContent = text;
+    }
+
+    function renderRoom(info) {
+        el.roomName.textContent = info.name;
+        el.exits.replaceChildren();
+
+        for (const name of Object.keys(info.exits || {}).sort()) {
+            const li = document.createElement("li");
+            li.textContent = name;
+            el.exits.appendChild(li);
+        }
+    }
+
+    function renderInventory(inv) {
+        el.inventory.replaceChildren();
+
+        for (const item of inv.items || []) {
+            const li = document.createElement("li");
+            li.textContent = item.name + " (" + item.id + ")";
+            el.inventory.appendChild(li);
+        }
+    }
+
+    function renderVitals(vitals) {
+        el.gold.textContent = vitals.gold + " gold";
+    }
+
+    function renderPlayers(presence) {
+        el.players.replaceChildren();
+
+        for (const name of presence.players || []) {
+            const li = document.createElement("li");
+            li.textContent = name;
+            el.players.appendChild(li);
+        }
+    }
+
+    function renderTyping(typing) {
+        el.typing.textContent = typing.talker + " is typing...";
+
+        clearTimeout(typingTimer);
+        typingTimer = setTimeout(() => (el.typing.textContent = ""), TYPING_INTERVAL_MS);
+    }
+
+    // ---------------------------------------------------------------------
+    // Message handling
+    // ---------------------------------------------------------------------
+
+    function handleEnvelope(env) {
+        switch (env.type) {
+            case "output":
+                appendTo(el.output, env.text || "");
+                break;
+            case "error":
+                appendTo(el.output, env.text || "", "error");
+                break;
+            case "event":
+                handleEvent(env.data);
+                break;
+            case "oob":
+                handleOutOfBand(env.package, env.data || {});
+                break;
+            case "session":
+                resumeToken = env.text;
+                break;
+            case "prompt":
+                // The input box is always available, so prompts aren't rendered.
+                break;
+        }
+    }
+
+    function handleEvent(event) {
+        if (event.type === "RoomChat") {
+            appendTo(el.chat, event.data.talker + ": " + event.data.text);
+        } else if (event.type === "RoomEmote") {
+            appendTo(el.output, event.data.text, "emote");
+        } else if (event.type in MOVEMENT_EVENTS) {
+            appendTo(el.output, describeMovement(event.type, event.data), "movement");
+        } else {
+            appendTo(el.output, "[" + event.type + "] " + JSON.stringify(event.data));
+        }
+    }
+
+    // Players arriving in or leaving the room, or joining or leaving the game.
+    const MOVEMENT_EVENTS = {PlayerArrived: true, PlayerDeparted: true, PlayerJoined: true, PlayerQuit: true};
+
+    function describeMovement(type, data) {
+        switch (type) {
+            case "PlayerArrived":
+                return data.player + (data.direction ? " arrives from the " + data.direction + "." : " arrives.");
+            case "PlayerDeparted":
+                return data.player + (data.direction ? " leaves " + data.direction + "." : " leaves.");
+            case "PlayerJoined":
+                return data.player + " has joined the game.";
+            default:
+                return data.player + " has left the game.";
+        }
+    }
+
+    function handleOutOfBand(pkg, data) {
+        switch (pkg) {
+            case "Room.Info":
+                renderRoom(data);
+                break;
+            case "Char.Items.Inv":
+                renderInventory(data);
+                break;
+            case "Char.Vitals":
+                renderVitals(data);
+                break;
+            case "Room.Players":
+                renderPlayers(data);
+                break;
+            case "Comm.Typing":
+                renderTyping(data);
+                break;
+        }
+    }
+
+    // ---------------------------------------------------------------------
+    // Transports
+    // ---------------------------------------------------------------------
+
+    // FrameReader splits a byte stream into length-prefixed frames.
+    class FrameReader {
+        constructor() {
+            this.buffer = new Uint8Array(0);
+        }
+
+        // push appends a chunk and returns any frames that are now complete.
+        push(chunk) {
+            const merged = new Uint8Array(this.buffer.length + chunk.length);
+            merged.set(this.buffer);
+            merged.set(chunk, this.buffer.length);
+            this.buffer = merged;
+
+            const frames = [];
+
+            while (this.buffer.length >= FRAME_HEADER_SIZE) {
+                const view = new DataView(this.buffer.buffer, this.buffer.byteOffset, this.buffer.byteLength);
+                const size = view.getUint32(0);
+
+                if (this.buffer.length < FRAME_HEADER_SIZE + size) {
+                    break;
+                }
+
+                frames.push(this.buffer.slice(FRAME_HEADER_SIZE, FRAME_HEADER_SIZE + size));
+                this.buffer = this.buffer.slice(FRAME_HEADER_SIZE + size);
+            }
+
+            return frames;
+        }
+    }
+
+    // encodeFrame encodes an envelope as a length-prefixed frame.
+    function encodeFrame(env) {
+        const payload = new TextEncoder().encode(JSON.stringify(env));
+        const frame = new Uint8Array(FRAME_HEADER_SIZE + payload.length);
+
+        new DataView(frame.buffer).setUint32(0, payload.length);
+        frame.set(payload, FRAME_HEADER_SIZE);
+
+        return frame;
+    }
+
+    // checkHello verifies the server accepted one of our protocol versions.
+    function checkHello(env) {
+        if (env.type === "error") {
+            throw new Error(env.text);
+        }
+
+        if (env.type !== "hello") {
+            throw new Error("Unexpected " + env.type + " message during handshake");
+        }
+    }
+
+    // readDatagrams handles ephemeral updates until the session closes.
+    async function readDatagrams(reader) {
+        const decoder = new TextDecoder();
+
+        for (;;) {
+            const { value, done } = await reader.read().catch(() => ({ done: true }));
+            if (done) {
+                return;
+            }
+
+            try {
+                handleEnvelope(JSON.parse(decoder.decode(value)));
+            } catch (err) {
+                console.error("failed to parse datagram", err);
+            }
+        }
+    }
+
+    // connectWebTransport opens a bidi stream to the /wt endpoint. query identifies
+    // the player, either by the session uuid from login or a resume token.
+    async function connectWebTransport(query) {
+        const url = "https://" + location.hostname + ":" + wtPort + "/wt?" + query;
+        const wt = new WebTransport(url);
+
+        const timeout = new Promise((_, reject) => {
+            setTimeout(() => reject(new Error("WebTransport connection timed out")), CONNECT_TIMEOUT_MS);
+        });
+
+        await Promise.race([wt.ready, timeout]);
+
+        const stream = await wt.createBidirectionalStream();
+        const writer = stream.writable.getWriter();
+        const reader = stream.readable.getReader();
+        const frames = new FrameReader();
+        const encoder = new TextEncoder();
+        const decoder = new TextDecoder();
+        const pending = [];
+
+        const readEnvelope = async () => {
+            while (pending.length === 0) {
+                const { value, done } = await reader.read();
+                if (done) {
+                    return null;
+                }
+
+                for (const frame of frames.push(value)) {
+                    pending.push(JSON.parse(decoder.decode(frame)));
+                }
+            }
+
+            return pending.shift();
+        };
+
+        await writer.write(encodeFrame({ type: "hello", versions: PROTOCOL_VERSIONS, datagrams: true }));
+
+        const hello = await Promise.race([readEnvelope(), timeout]);
+        if (!hello) {
+            throw new Error("WebTransport stream closed during handshake");
+        }
+
+        checkHello(hello);
+
+        let datagramWriter = null;
+
+        if (hello.datagrams) {
+            datagramWriter = wt.datagrams.writable.getWriter();
+            readDatagrams(wt.datagrams.readable.getReader());
+        }
+
+        const closed = (async () => {
+            for (;;) {
+                const env = await readEnvelope();
+                if (!env) {
+                    return;
+                }
+
+                handleEnvelope(env);
+            }
+        })();
+
+        return {
+            name: "WebTransport",
+            send: (env) => writer.write(encodeFrame(env)),
+            // Ephemeral messages may be lost, so they're sent as datagrams when possible.
+            sendEphemeral: (env) =>
+                datagramWriter ? datagramWriter.write(encoder.encode(JSON.stringify(env))) : writer.write(encodeFrame(env)),
+            closed: closed.finally(() => wt.close()),
+        };
+    }
+
+    // connectWebSocket connects to the /ws fallback endpoint on the HTTP server.
+    function connectWebSocket(query) {
+        const scheme = location.protocol === "https:" ? "wss://" : "ws://";
+        const ws = new WebSocket(scheme + location.host + "/ws?" + query);
+
+        return new Promise((resolve, reject) => {
+            let closedResolve;
+            const closed = new Promise((r) => (closedResolve = r));
+            let connected = false;
+
+            ws.onopen = () => {
+                ws.send(JSON.stringify({ type: "hello", versions: PROTOCOL_VERSIONS }));
+            };
+
+            ws.onerror = () => reject(new Error("WebSocket connection failed"));
+
+            ws.onmessage = (e) => {
+                let env;
+
+                try {
+                    env = JSON.parse(String(e.data));
+                } catch (err) {
+                    console.error("failed to parse envelope", err);
+                    return;
+                }
+
+                if (connected) {
+                    handleEnvelope(env);
+                    return;
+                }
+
+                try {
+                    checkHello(env);
+                } catch (err) {
+                    reject(err);
+                    ws.close();
+                    return;
+                }
+
+                connected = true;
+                resolve({
+                    name: "WebSocket",
+                    send: (env) => ws.send(JSON.stringify(env)),
+                    sendEphemeral: (env) => ws.send(JSON.stringify(env)),
+                    closed: closed,
+                });
+            };
+
+            ws.onclose = () => {
+                reject(new Error("WebSocket closed during handshake"));
+                closedResolve();
+            };
+        });
+    }
+
+    // connect tries WebTransport first and falls back to WebSockets. A fresh login
+    // is needed for the fallback since a failed attempt may have consumed the session.
+    async function connect(username, password) {
+        let session = await loginAndWait(username, password);
+        wtPort = session.webTransportPort;
+
+        if ("WebTransport" in window) {
+            try {
+                return await connectWebTransport("uuid=" + encodeURIComponent(session.sessionUuid));
+            } catch (err) {
+                console.warn("WebTransport unavailable, falling back to WebSocket", err);
+                session = await loginAndWait(username, password);
+            }
+        }
+
+        return connectWebSocket("uuid=" + encodeURIComponent(session.sessionUuid));
+    }
+
+    // resume reconnects with the resume token. Each successful connection rotates
+    // the token, so a failed WebTransport attempt doesn't prevent the fallback.
+    async function resume() {
+        const query = () => "resume=" + encodeURIComponent(resumeToken);
+
+        if ("WebTransport" in window) {
+            try {
+                return await connectWebTransport(query());
+            } catch (err) {
+                console.warn("WebTransport resume failed, falling back to WebSocket", err);
+            }
+        }
+
+        return connectWebSocket(query());
+    }
+
+    // reconnect keeps trying to resume the session after the connection dropped.
+    async function reconnect() {
+        for (let attempt = 0; attempt < RECONNECT_ATTEMPTS && resumeToken; attempt++) {
+            setStatus("Reconnecting...");
+            await new Promise((r) => setTimeout(r, RECONNECT_DELAY_MS));
+
+            try {
+                return await resume();
+            } catch (err) {
+                console.warn("reconnect attempt failed", err);
+            }
+        }
+
+        return null;
+    }
+
+    // attach makes the transport current and handles it closing.
+    function attach(t) {
+        transport = t;
+        setStatus("Connected via " + transport.name);
+
+        send({ type: "oob", package: "Core.Supports.Set", data: GMCP_MODULES });
+
+        transport.closed.then(async () => {
+            transport = null;
+
+            const t = resumeToken ? await reconnect() : null;
+            if (t) {
+                attach(t);
+                return;
+            }
+
+            setStatus("Disconnected");
+            appendTo(el.output, "Disconnected from server.", "error");
+        });
+    }
+
+    // loginAndWait logs in, waiting in the login queue while the server is full.
+    async function loginAndWait(username, password) {
+        const session = await login(username, password);
+        let position = session.queuePosition;
+
+        while (position > 0) {
+            el.loginError.textContent = "The server is full, you are number " + position + " in the queue...";
+            await new Promise((r) => setTimeout(r, QUEUE_POLL_MS));
+
+            const resp = await fetch("/api/queue?uuid=" + encodeURIComponent(session.sessionUuid));
+            const body = await resp.json().catch(() => null);
+
+            if (!resp.ok) {
+                throw new Error(body && body.error ? body.error.message : "Lost place in the login queue");
+            }
+
+            position = body.position;
+        }
+
+        el.loginError.textContent = "";
+        return session;
+    }
+
+    async function login(username, password) {
+        const resp = await fetch("/api/login", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ username: username, password: password }),
+        });
+
+        if (!resp.ok) {
+            const body = await resp.json().catch(() => null);
+            throw new Error(body && body.error ? body.error.message : "Login failed");
+        }
+
+        return resp.json();
+    }
+
+    function send(env) {
+        if (transport) {
+            transport.send(env);
+        }
+    }
+
+    // ---------------------------------------------------------------------
+    // UI
+    // ---------------------------------------------------------------------
+
+    el.login.addEventListener("submit", async (e) => {
+        e.preventDefault();
+        el.loginError.textContent = "";
+
+        const username = el.username.value.trim();
+        if (username.length < 3 || username.length > 12) {
+            el.loginError.textContent = "Username must be between 3 and 12 characters";
+            return;
+        }
+
+        const password = el.password.value;
+        if (password.length < 6 || password.length > 64) {
+            el.loginError.textContent = "Password must be between 6 and 64 characters";
+            return;
+        }
+
+        let t;
+
+        try {
+            t = await connect(username, password);
+        } catch (err) {
+            el.loginError.textContent = err.message;
+            return;
+        }
+
+        el.login.hidden = true;
+        el.game.hidden = false;
+        el.input.focus();
+        attach(t);
+    });
+
+    el.command.addEventListener("submit", (e) => {
+        e.preventDefault();
+
+        const text = el.input.value.trim();
+        if (text === "") {
+            return;
+        }
+
+        if (history[history.length - 1] !== text) {
+            history.push(text);
+            if (history.length > MAX_HISTORY) {
+                history.shift();
+            }
+        }
+
+        historyIndex = history.length;
+        el.input.value = "";
+
+        appendTo(el.output, "> " + text, "echo");
+        send({ type: "command", text: text });
+
+        // Quitting ends the session, so there's nothing to resume.
+        if (text === "quit" || text === "exit") {
+            resumeToken = null;
+        }
+    });
+
+    // Let the room know we're typing, at most once per interval.
+    el.input.addEventListener("input", () => {
+        const now = Date.now();
+
+        if (!transport || el.input.value.trim() === "" || now - lastTyping < TYPING_INTERVAL_MS) {
+            return;
+        }
+
+        lastTyping = now;
+        transport.sendEphemeral({ type: "typing" });
+    });
+
+    el.input.addEventListener("keydown", (e) => {
+        if (e.key === "ArrowUp" && historyIndex > 0) {
+            historyIndex--;
+            el.input.value = history[historyIndex];
+            e.preventDefault();
+        } else if (e.key === "ArrowDown" && historyIndex < history.length) {
+            historyIndex++;
+            el.input.value = history[historyIndex] || "";
+            e.preventDefault();
+        }
+    });
+})();